@@ -36,6 +36,10 @@ func (h *DDNSHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
+	if err := ddns.ValidateDomains(&task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
 	task.Status = "stopped"
 	h.db.Create(&task)
 	if task.Enable {
@@ -51,6 +55,10 @@ func (h *DDNSHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
+	if err := ddns.ValidateDomains(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
 	h.mgr.Stop(uint(id))
 	req.ID = uint(id)
 	h.db.Save(&req)
@@ -2,20 +2,12 @@ package handlers
 
 import (
 	"bufio"
-	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha1"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"net/url"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,475 +18,11 @@ import (
 	"github.com/netpanel/netpanel/pkg/config"
 	"github.com/netpanel/netpanel/service/access"
 	"github.com/netpanel/netpanel/service/callback"
+	"github.com/netpanel/netpanel/service/dnsprovider"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-// ===== DNS Provider 同步接口 =====
-
-// ProviderRecord 服务商返回的解析记录
-type ProviderRecord struct {
-	RemoteID   string
-	RecordType string
-	Host       string // 主机记录（相对域名，如 www、@ 等）
-	Value      string
-	TTL        int
-	Proxied    bool // 仅 Cloudflare 支持
-}
-
-// ProviderDomainItem 服务商返回的域名条目
-type ProviderDomainItem struct {
-	Name    string
-	ThirdID string
-}
-
-// dnsRecordProvider DNS 解析记录同步接口
-type dnsRecordProvider interface {
-	// ListRecords 获取指定域名的所有解析记录
-	ListRecords(domain string) ([]ProviderRecord, error)
-	// ListDomains 获取账号下所有域名
-	ListDomains() ([]ProviderDomainItem, error)
-}
-
-// newDNSRecordProvider 根据账号信息创建对应的 DNS provider
-func newDNSRecordProvider(acc model.DomainAccount) dnsRecordProvider {
-	switch strings.ToLower(acc.Provider) {
-	case "cloudflare", "cf":
-		// Cloudflare: AccessID = Zone ID（可选），AccessSecret = API Token
-		// 若 AuthType == api_key，则 AccessID=Email, AccessSecret=Global API Key
-		if acc.AuthType == "api_key" {
-			return &cfProvider{email: acc.Email, apiKey: acc.AccessSecret}
-		}
-		return &cfProvider{apiToken: acc.AccessSecret, zoneID: acc.AccessID}
-	case "alidns", "aliyun":
-		return &aliDNSRecordProvider{accessKeyID: acc.AccessID, accessKeySecret: acc.AccessSecret}
-	case "dnspod":
-		return &dnspodRecordProvider{secretID: acc.AccessID, secretKey: acc.AccessSecret}
-	default:
-		return nil
-	}
-}
-
-// ===== Cloudflare Provider =====
-
-const cfAPIBase = "https://api.cloudflare.com/client/v4"
-
-type cfProvider struct {
-	apiToken string
-	apiKey   string // Global API Key（api_key 认证方式）
-	email    string // 邮箱（api_key 认证方式）
-	zoneID   string // 可选，若为空则自动查询
-}
-
-func (p *cfProvider) cfHeaders() map[string]string {
-	h := map[string]string{"Content-Type": "application/json"}
-	if p.apiToken != "" {
-		h["Authorization"] = "Bearer " + p.apiToken
-	} else {
-		h["X-Auth-Email"] = p.email
-		h["X-Auth-Key"] = p.apiKey
-	}
-	return h
-}
-
-func (p *cfProvider) cfGet(path string) ([]byte, error) {
-	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest("GET", cfAPIBase+path, nil)
-	if err != nil {
-		return nil, err
-	}
-	for k, v := range p.cfHeaders() {
-		req.Header.Set(k, v)
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode >= 400 {
-		return body, fmt.Errorf("Cloudflare API HTTP %d: %s", resp.StatusCode, string(body))
-	}
-	return body, nil
-}
-
-func (p *cfProvider) getZoneID(domain string) (string, error) {
-	if p.zoneID != "" {
-		return p.zoneID, nil
-	}
-	// 尝试用根域名查询 Zone
-	parts := strings.Split(domain, ".")
-	var rootDomain string
-	if len(parts) >= 2 {
-		rootDomain = strings.Join(parts[len(parts)-2:], ".")
-	} else {
-		rootDomain = domain
-	}
-	body, err := p.cfGet("/zones?name=" + rootDomain + "&status=active")
-	if err != nil {
-		return "", fmt.Errorf("查询 Cloudflare Zone 失败: %w", err)
-	}
-	var result struct {
-		Result []struct {
-			ID string `json:"id"`
-		} `json:"result"`
-		Success bool `json:"success"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil || !result.Success || len(result.Result) == 0 {
-		return "", fmt.Errorf("未找到域名 %s 对应的 Cloudflare Zone", rootDomain)
-	}
-	return result.Result[0].ID, nil
-}
-
-func (p *cfProvider) ListRecords(domain string) ([]ProviderRecord, error) {
-	zoneID, err := p.getZoneID(domain)
-	if err != nil {
-		return nil, err
-	}
-
-	var allRecords []ProviderRecord
-	page := 1
-	for {
-		body, err := p.cfGet(fmt.Sprintf("/zones/%s/dns_records?per_page=100&page=%d", zoneID, page))
-		if err != nil {
-			return nil, fmt.Errorf("获取 Cloudflare 解析记录失败: %w", err)
-		}
-		var result struct {
-			Result []struct {
-				ID      string `json:"id"`
-				Type    string `json:"type"`
-				Name    string `json:"name"`
-				Content string `json:"content"`
-				TTL     int    `json:"ttl"`
-				Proxied bool   `json:"proxied"`
-			} `json:"result"`
-			Success    bool `json:"success"`
-			ResultInfo struct {
-				Page       int `json:"page"`
-				TotalPages int `json:"total_pages"`
-			} `json:"result_info"`
-		}
-		if err := json.Unmarshal(body, &result); err != nil || !result.Success {
-			return nil, fmt.Errorf("解析 Cloudflare 响应失败")
-		}
-		for _, r := range result.Result {
-			// 将完整域名转换为主机记录（相对部分）
-			host := r.Name
-			if strings.HasSuffix(host, "."+domain) {
-				host = strings.TrimSuffix(host, "."+domain)
-			} else if host == domain {
-				host = "@"
-			}
-			allRecords = append(allRecords, ProviderRecord{
-				RemoteID:   r.ID,
-				RecordType: r.Type,
-				Host:       host,
-				Value:      r.Content,
-				TTL:        r.TTL,
-				Proxied:    r.Proxied,
-			})
-		}
-		if page >= result.ResultInfo.TotalPages || result.ResultInfo.TotalPages == 0 {
-			break
-		}
-		page++
-	}
-	return allRecords, nil
-}
-
-func (p *cfProvider) ListDomains() ([]ProviderDomainItem, error) {
-	var allDomains []ProviderDomainItem
-	page := 1
-	for {
-		body, err := p.cfGet(fmt.Sprintf("/zones?per_page=50&page=%d&status=active", page))
-		if err != nil {
-			return nil, fmt.Errorf("获取 Cloudflare 域名列表失败: %w", err)
-		}
-		var result struct {
-			Result []struct {
-				ID   string `json:"id"`
-				Name string `json:"name"`
-			} `json:"result"`
-			Success    bool `json:"success"`
-			ResultInfo struct {
-				Page       int `json:"page"`
-				TotalPages int `json:"total_pages"`
-			} `json:"result_info"`
-		}
-		if err := json.Unmarshal(body, &result); err != nil || !result.Success {
-			return nil, fmt.Errorf("解析 Cloudflare 域名列表响应失败")
-		}
-		for _, z := range result.Result {
-			allDomains = append(allDomains, ProviderDomainItem{Name: z.Name, ThirdID: z.ID})
-		}
-		if page >= result.ResultInfo.TotalPages || result.ResultInfo.TotalPages == 0 {
-			break
-		}
-		page++
-	}
-	return allDomains, nil
-}
-
-// ===== 阿里云 DNS Provider（解析记录同步）=====
-
-// aliDNSSign 生成阿里云 DNS API HMAC-SHA1 签名
-func aliDNSSign(params map[string]string, secret string) string {
-	// 排序参数键
-	keys := make([]string, 0, len(params))
-	for k := range params {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// 构建规范化查询字符串
-	var parts []string
-	for _, k := range keys {
-		parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(params[k]))
-	}
-	canonicalized := strings.Join(parts, "&")
-	stringToSign := "GET&%2F&" + url.QueryEscape(canonicalized)
-
-	mac := hmac.New(sha1.New, []byte(secret+"&"))
-	mac.Write([]byte(stringToSign))
-	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
-}
-
-// tc3HashSHA256 计算字符串的 SHA256 哈希（十六进制）
-func tc3HashSHA256(s string) string {
-	h := sha256.New()
-	h.Write([]byte(s))
-	return hex.EncodeToString(h.Sum(nil))
-}
-
-// tc3HmacSHA256 计算 HMAC-SHA256
-func tc3HmacSHA256(key []byte, data string) []byte {
-	mac := hmac.New(sha256.New, key)
-	mac.Write([]byte(data))
-	return mac.Sum(nil)
-}
-
-type aliDNSRecordProvider struct {
-	accessKeyID     string
-	accessKeySecret string
-}
-
-func (p *aliDNSRecordProvider) aliRequest(action string, params map[string]string) ([]byte, error) {
-	// 内联阿里云签名逻辑
-	allParams := map[string]string{
-		"Action":           action,
-		"AccessKeyId":      p.accessKeyID,
-		"Format":           "JSON",
-		"Version":          "2015-01-09",
-		"SignatureMethod":  "HMAC-SHA1",
-		"SignatureVersion": "1.0",
-		"SignatureNonce":   strconv.FormatInt(time.Now().UnixNano(), 10),
-		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
-	}
-	for k, v := range params {
-		allParams[k] = v
-	}
-	allParams["Signature"] = aliDNSSign(allParams, p.accessKeySecret)
-
-	var parts []string
-	for k, v := range allParams {
-		parts = append(parts, k+"="+v)
-	}
-	reqURL := "https://alidns.aliyuncs.com/?" + strings.Join(parts, "&")
-
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(reqURL)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
-}
-
-func (p *aliDNSRecordProvider) ListRecords(domain string) ([]ProviderRecord, error) {
-	body, err := p.aliRequest("DescribeDomainRecords", map[string]string{
-		"DomainName": domain,
-		"PageSize":   "500",
-	})
-	if err != nil {
-		return nil, fmt.Errorf("获取阿里云解析记录失败: %w", err)
-	}
-	var result struct {
-		DomainRecords struct {
-			Record []struct {
-				RecordId string `json:"RecordId"`
-				Type     string `json:"Type"`
-				RR       string `json:"RR"`
-				Value    string `json:"Value"`
-				TTL      int    `json:"TTL"`
-			} `json:"Record"`
-		} `json:"DomainRecords"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("解析阿里云响应失败: %w", err)
-	}
-	var records []ProviderRecord
-	for _, r := range result.DomainRecords.Record {
-		records = append(records, ProviderRecord{
-			RemoteID:   r.RecordId,
-			RecordType: r.Type,
-			Host:       r.RR,
-			Value:      r.Value,
-			TTL:        r.TTL,
-		})
-	}
-	return records, nil
-}
-
-func (p *aliDNSRecordProvider) ListDomains() ([]ProviderDomainItem, error) {
-	body, err := p.aliRequest("DescribeDomains", map[string]string{"PageSize": "100"})
-	if err != nil {
-		return nil, fmt.Errorf("获取阿里云域名列表失败: %w", err)
-	}
-	var result struct {
-		Domains struct {
-			Domain []struct {
-				DomainId   string `json:"DomainId"`
-				DomainName string `json:"DomainName"`
-			} `json:"Domain"`
-		} `json:"Domains"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("解析阿里云域名列表失败: %w", err)
-	}
-	var domains []ProviderDomainItem
-	for _, d := range result.Domains.Domain {
-		domains = append(domains, ProviderDomainItem{Name: d.DomainName, ThirdID: d.DomainId})
-	}
-	return domains, nil
-}
-
-// ===== DNSPod Provider（解析记录同步）=====
-
-type dnspodRecordProvider struct {
-	secretID  string
-	secretKey string
-}
-
-func (p *dnspodRecordProvider) dnspodPost(action string, payload interface{}) ([]byte, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
-	payloadStr := string(data)
-	host := "dnspod.tencentcloudapi.com"
-	service := "dnspod"
-	ts := time.Now().Unix()
-	timestamp := strconv.FormatInt(ts, 10)
-	date := time.Unix(ts, 0).UTC().Format("2006-01-02")
-
-	// TC3-HMAC-SHA256 签名
-	canonicalRequest := strings.Join([]string{
-		"POST", "/", "",
-		"content-type:application/json; charset=utf-8\nhost:" + host + "\n",
-		"content-type;host",
-		tc3HashSHA256(payloadStr),
-	}, "\n")
-	credentialScope := date + "/" + service + "/tc3_request"
-	stringToSign := strings.Join([]string{
-		"TC3-HMAC-SHA256", timestamp, credentialScope, tc3HashSHA256(canonicalRequest),
-	}, "\n")
-	secretDate := tc3HmacSHA256([]byte("TC3"+p.secretKey), date)
-	secretService := tc3HmacSHA256(secretDate, service)
-	secretSigning := tc3HmacSHA256(secretService, "tc3_request")
-	signature := hex.EncodeToString(tc3HmacSHA256(secretSigning, stringToSign))
-	authorization := fmt.Sprintf(
-		"TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
-		p.secretID, credentialScope, signature,
-	)
-
-	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest("POST", "https://"+host, bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", authorization)
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("Host", host)
-	req.Header.Set("X-TC-Action", action)
-	req.Header.Set("X-TC-Timestamp", timestamp)
-	req.Header.Set("X-TC-Version", "2021-03-23")
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
-}
-
-func (p *dnspodRecordProvider) ListRecords(domain string) ([]ProviderRecord, error) {
-	body, err := p.dnspodPost("DescribeRecordList", map[string]interface{}{
-		"Domain": domain,
-		"Limit":  3000,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("获取 DNSPod 解析记录失败: %w", err)
-	}
-	var result struct {
-		Response struct {
-			RecordList []struct {
-				RecordId   uint   `json:"RecordId"`
-				Type       string `json:"Type"`
-				SubDomain  string `json:"Name"`
-				Value      string `json:"Value"`
-				TTL        int    `json:"TTL"`
-			} `json:"RecordList"`
-			Error *struct{ Message string } `json:"Error"`
-		} `json:"Response"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("解析 DNSPod 响应失败: %w", err)
-	}
-	if result.Response.Error != nil {
-		return nil, fmt.Errorf("DNSPod API 错误: %s", result.Response.Error.Message)
-	}
-	var records []ProviderRecord
-	for _, r := range result.Response.RecordList {
-		records = append(records, ProviderRecord{
-			RemoteID:   strconv.FormatUint(uint64(r.RecordId), 10),
-			RecordType: r.Type,
-			Host:       r.SubDomain,
-			Value:      r.Value,
-			TTL:        r.TTL,
-		})
-	}
-	return records, nil
-}
-
-func (p *dnspodRecordProvider) ListDomains() ([]ProviderDomainItem, error) {
-	body, err := p.dnspodPost("DescribeDomainList", map[string]interface{}{"Limit": 3000})
-	if err != nil {
-		return nil, fmt.Errorf("获取 DNSPod 域名列表失败: %w", err)
-	}
-	var result struct {
-		Response struct {
-			DomainList []struct {
-				DomainId uint   `json:"DomainId"`
-				Name     string `json:"Name"`
-			} `json:"DomainList"`
-			Error *struct{ Message string } `json:"Error"`
-		} `json:"Response"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("解析 DNSPod 域名列表失败: %w", err)
-	}
-	if result.Response.Error != nil {
-		return nil, fmt.Errorf("DNSPod API 错误: %s", result.Response.Error.Message)
-	}
-	var domains []ProviderDomainItem
-	for _, d := range result.Response.DomainList {
-		domains = append(domains, ProviderDomainItem{
-			Name:    d.Name,
-			ThirdID: strconv.FormatUint(uint64(d.DomainId), 10),
-		})
-	}
-	return domains, nil
-}
-
 // ===== WOL =====
 
 type WolHandler struct {
@@ -628,9 +156,24 @@ func (h *DomainAccountHandler) Test(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "账号不存在"})
 		return
 	}
-	// TODO: 根据 account.Provider 调用对应 DNS 服务商 API 验证凭据
 	h.log.Infof("[域名账号] 测试连接: id=%d provider=%s", id, account.Provider)
-	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "连接测试成功"})
+	provider, err := dnsprovider.New(account)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	// 通过列出域名验证凭据；不支持列出域名的服务商（如 DuckDNS）跳过验证
+	domains, err := provider.ListDomains()
+	if err != nil && !errors.Is(err, dnsprovider.ErrNotSupported) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "连接测试失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{"domain_count": len(domains)}, "message": "连接测试成功"})
+}
+
+// Providers 列出已支持的 DNS 服务商
+func (h *DomainAccountHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": dnsprovider.List()})
 }
 
 // ===== 域名管理 =====
@@ -728,9 +271,9 @@ func (h *DomainInfoHandler) doSyncFromProvider(ctx context.Context, domainInfoID
 	h.log.Infof("[域名同步] 开始同步: domain=%s provider=%s", domain.Name, acc.Provider)
 
 	// 创建对应服务商的 provider
-	provider := newDNSRecordProvider(acc)
-	if provider == nil {
-		return 0, fmt.Errorf("不支持的 DNS 服务商: %s", acc.Provider)
+	provider, err := dnsprovider.New(acc)
+	if err != nil {
+		return 0, err
 	}
 
 	// 从服务商拉取解析记录
@@ -744,32 +287,32 @@ func (h *DomainInfoHandler) doSyncFromProvider(ctx context.Context, domainInfoID
 	// 构建服务商记录的 remoteID 集合，用于后续删除本地多余记录
 	remoteIDSet := make(map[string]bool)
 	for _, pr := range providerRecords {
-		if pr.RemoteID != "" {
-			remoteIDSet[pr.RemoteID] = true
+		if pr.ID != "" {
+			remoteIDSet[pr.ID] = true
 		}
 	}
 
 	// 三路 diff：插入/更新/删除
 	for _, pr := range providerRecords {
 		var local model.DomainRecord
-		err := h.db.WithContext(ctx).Where("domain_info_id = ? AND remote_id = ?", domainInfoID, pr.RemoteID).First(&local).Error
+		err := h.db.WithContext(ctx).Where("domain_info_id = ? AND remote_id = ?", domainInfoID, pr.ID).First(&local).Error
 		if err != nil {
 			// 本地不存在 → 插入
 			h.db.WithContext(ctx).Create(&model.DomainRecord{
 				DomainInfoID:    domainInfoID,
 				DomainAccountID: domain.AccountID,
 				Domain:          domain.Name,
-				RecordType:      pr.RecordType,
+				RecordType:      pr.Type,
 				Host:            pr.Host,
 				Value:           pr.Value,
 				TTL:             pr.TTL,
-				RemoteID:        pr.RemoteID,
+				RemoteID:        pr.ID,
 				Proxied:         pr.Proxied,
 			})
 		} else {
 			// 本地已存在 → 更新
 			h.db.WithContext(ctx).Model(&local).Updates(map[string]interface{}{
-				"record_type": pr.RecordType,
+				"record_type": pr.Type,
 				"host":        pr.Host,
 				"value":       pr.Value,
 				"ttl":         pr.TTL,
@@ -928,7 +471,7 @@ func (h *DomainInfoHandler) FetchFromProvider(c *gin.Context) {
 	}
 
 	// 创建对应服务商的 provider
-	provider := newDNSRecordProvider(acc)
+	provider, _ := dnsprovider.New(acc)
 	var providerDomains []ProviderDomain
 
 	if provider != nil {
@@ -957,7 +500,7 @@ func (h *DomainInfoHandler) FetchFromProvider(c *gin.Context) {
 		for _, item := range providerItems {
 			providerDomains = append(providerDomains, ProviderDomain{
 				Name:    item.Name,
-				ThirdID: item.ThirdID,
+				ThirdID: item.ID,
 				Added:   existingMap[item.Name],
 			})
 		}
@@ -1171,11 +714,92 @@ func (h *DomainRecordHandler) Create(c *gin.Context) {
 			record.DomainAccountID = domain.AccountID
 			record.Domain = domain.Name
 		}
+	} else if record.DomainInfoID == 0 && record.Domain != "" {
+		h.resolveRecordZone(&record)
+	}
+	// 先在服务商侧创建，成功后保存服务商记录 ID
+	if record.DomainAccountID > 0 {
+		provider, err := h.provider(record.DomainAccountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		remoteID, err := provider.CreateRecord(record.Domain, toProviderRecord(&record))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "服务商创建记录失败: " + err.Error()})
+			return
+		}
+		record.RemoteID = remoteID
 	}
 	h.db.Create(&record)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "data": record, "message": "创建成功"})
 }
 
+// provider 根据域名账号创建 DNS 服务商实例
+func (h *DomainRecordHandler) provider(accountID uint) (dnsprovider.Provider, error) {
+	var acc model.DomainAccount
+	if err := h.db.First(&acc, accountID).Error; err != nil {
+		return nil, fmt.Errorf("域名账号不存在")
+	}
+	return dnsprovider.New(acc)
+}
+
+// toProviderRecord 本地解析记录转换为服务商记录
+func toProviderRecord(record *model.DomainRecord) dnsprovider.Record {
+	return dnsprovider.Record{
+		ID:      record.RemoteID,
+		Type:    record.RecordType,
+		Host:    record.Host,
+		Value:   record.Value,
+		TTL:     record.TTL,
+		Proxied: record.Proxied,
+	}
+}
+
+// refreshRemoteID 修改后重新查询服务商记录 ID（华为云、GoDaddy 等的记录 ID 包含记录值）
+func refreshRemoteID(provider dnsprovider.Provider, record *model.DomainRecord) {
+	list, err := provider.FindRecords(record.Domain, record.Host, record.RecordType)
+	if err != nil {
+		return
+	}
+	for _, r := range list {
+		if strings.Trim(r.Value, `"`) == strings.Trim(record.Value, `"`) {
+			record.RemoteID = r.ID
+			return
+		}
+	}
+}
+
+// resolveRecordZone 未指定所属域名时，将完整域名拆分为主机记录与根域名
+// 优先匹配已添加域名中最长的根域名，未匹配时按公共后缀列表拆分
+func (h *DomainRecordHandler) resolveRecordZone(record *model.DomainRecord) {
+	fqdn := dnsprovider.JoinHost(record.Host, strings.TrimSuffix(record.Domain, "."))
+	var domains []model.DomainInfo
+	query := h.db.Model(&model.DomainInfo{})
+	if record.DomainAccountID > 0 {
+		query = query.Where("account_id = ?", record.DomainAccountID)
+	}
+	query.Find(&domains)
+
+	names := make([]string, 0, len(domains))
+	for _, d := range domains {
+		names = append(names, d.Name)
+	}
+	if zone, ok := dnsprovider.FindZone(fqdn, names); ok {
+		for _, d := range domains {
+			if strings.EqualFold(strings.TrimSuffix(d.Name, "."), zone) {
+				record.DomainInfoID = d.ID
+				record.DomainAccountID = d.AccountID
+				break
+			}
+		}
+		record.Host = dnsprovider.RelativeHost(fqdn, zone)
+		record.Domain = zone
+		return
+	}
+	record.Host, record.Domain = dnsprovider.SplitDomain(fqdn)
+}
+
 func (h *DomainRecordHandler) Update(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	var req model.DomainRecord
@@ -1183,13 +807,62 @@ func (h *DomainRecordHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
-	req.ID = uint(id)
-	h.db.Save(&req)
-	c.JSON(http.StatusOK, gin.H{"code": 200, "data": req, "message": "更新成功"})
+	var record model.DomainRecord
+	if err := h.db.First(&record, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "记录不存在"})
+		return
+	}
+	// 所属域名与服务商记录 ID 以数据库为准，只修改记录内容
+	record.RecordType = req.RecordType
+	record.Host = req.Host
+	record.Value = req.Value
+	record.TTL = req.TTL
+	record.Proxied = req.Proxied
+	record.Remark = req.Remark
+
+	if record.DomainAccountID > 0 {
+		provider, err := h.provider(record.DomainAccountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		if record.RemoteID == "" {
+			remoteID, err := provider.CreateRecord(record.Domain, toProviderRecord(&record))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "服务商创建记录失败: " + err.Error()})
+				return
+			}
+			record.RemoteID = remoteID
+		} else {
+			if err := provider.UpdateRecord(record.Domain, toProviderRecord(&record)); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "服务商修改记录失败: " + err.Error()})
+				return
+			}
+			refreshRemoteID(provider, &record)
+		}
+	}
+	h.db.Save(&record)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": record, "message": "更新成功"})
 }
 
 func (h *DomainRecordHandler) Delete(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var record model.DomainRecord
+	if err := h.db.First(&record, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "记录不存在"})
+		return
+	}
+	if record.DomainAccountID > 0 && record.RemoteID != "" {
+		provider, err := h.provider(record.DomainAccountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+		if err := provider.DeleteRecord(record.Domain, toProviderRecord(&record)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "服务商删除记录失败: " + err.Error()})
+			return
+		}
+	}
 	h.db.Delete(&model.DomainRecord{}, id)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
 }
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"strconv"
 
@@ -65,6 +68,7 @@ func (h *EasytierHandler) Update(c *gin.Context) {
 func (h *EasytierHandler) Delete(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	h.mgr.StopClient(uint(id))
+	h.db.Where("client_id = ?", id).Delete(&model.EasytierVpnDevice{})
 	h.db.Delete(&model.EasytierClient{}, id)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
 }
@@ -89,7 +93,134 @@ func (h *EasytierHandler) Stop(c *gin.Context) {
 func (h *EasytierHandler) GetStatus(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	status := h.mgr.GetClientStatus(uint(id))
-	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{"status": status}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{
+		"status":  status,
+		"process": h.mgr.GetClientProcess(uint(id)),
+	}})
+}
+
+// Import 导入 easytier config.toml，表单字段 file 为配置文件，name 为可选名称
+func (h *EasytierHandler) Import(c *gin.Context) {
+	content, err := readUploadedConfig(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	cfg, err := h.mgr.ImportClientConfig(c.PostForm("name"), content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": cfg, "message": "导入成功"})
+}
+
+// Export 导出客户端 config.toml
+func (h *EasytierHandler) Export(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	data, err := h.mgr.ExportClientConfig(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	sendConfigFile(c, fmt.Sprintf("easytier-client-%d.toml", id), data)
+}
+
+// Peers 对等节点列表（通过 RPC 门户查询，含 p2p/relay 连接方式、延迟、流量）
+func (h *EasytierHandler) Peers(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	data, err := h.mgr.GetClientPeers(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": data})
+}
+
+// Routes 路由表（通过 RPC 门户查询）
+func (h *EasytierHandler) Routes(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	data, err := h.mgr.GetClientRoutes(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": data})
+}
+
+// ===== VPN 门户设备 =====
+
+// vpnEndpoint 设备访问本机的地址，未指定时使用当前访问面板的主机名
+func vpnEndpoint(c *gin.Context) string {
+	if ep := c.Query("endpoint"); ep != "" {
+		return ep
+	}
+	host := c.Request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
+
+func (h *EasytierHandler) ListVpnDevices(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": h.mgr.ListVpnDevices(uint(id))})
+}
+
+func (h *EasytierHandler) CreateVpnDevice(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var req struct {
+		Name   string `json:"name"`
+		Remark string `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	dev, err := h.mgr.CreateVpnDevice(uint(id), req.Name, req.Remark)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": dev, "message": "创建成功"})
+}
+
+func (h *EasytierHandler) DeleteVpnDevice(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	did, _ := strconv.ParseUint(c.Param("did"), 10, 64)
+	if err := h.mgr.DeleteVpnDevice(uint(id), uint(did)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// VpnDeviceConfig 下载设备的 WireGuard 配置文件
+func (h *EasytierHandler) VpnDeviceConfig(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	did, _ := strconv.ParseUint(c.Param("did"), 10, 64)
+	data, err := h.mgr.VpnDeviceConfig(uint(id), uint(did), vpnEndpoint(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	sendConfigFile(c, fmt.Sprintf("easytier-vpn-%d.conf", did), data)
+}
+
+// VpnDeviceQRCode 设备配置二维码（PNG）
+func (h *EasytierHandler) VpnDeviceQRCode(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	did, _ := strconv.ParseUint(c.Param("did"), 10, 64)
+	png, err := h.mgr.VpnDeviceQRCode(uint(id), uint(did), vpnEndpoint(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// Topology 虚拟网络拓扑（汇总本地所有实例的对等节点与路由）
+func (h *EasytierHandler) Topology(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": h.mgr.GetTopology(c.Query("network"))})
 }
 
 // ===== EasyTier 服务端 =====
@@ -166,3 +297,293 @@ func (h *EasytierServerHandler) Stop(c *gin.Context) {
 	h.db.Model(&model.EasytierServer{}).Where("id = ?", id).Update("enable", false)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已停止"})
 }
+
+func (h *EasytierServerHandler) GetStatus(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	status := h.mgr.GetServerStatus(uint(id))
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{
+		"status":  status,
+		"process": h.mgr.GetServerProcess(uint(id)),
+	}})
+}
+
+// Export 导出服务端 config.toml
+func (h *EasytierServerHandler) Export(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	data, err := h.mgr.ExportServerConfig(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	sendConfigFile(c, fmt.Sprintf("easytier-server-%d.toml", id), data)
+}
+
+// ===== EasyTier 二进制管理 =====
+
+type EasytierBinaryHandler struct {
+	log *logrus.Logger
+	mgr *easytier.Manager
+}
+
+func NewEasytierBinaryHandler(log *logrus.Logger, mgr *easytier.Manager) *EasytierBinaryHandler {
+	return &EasytierBinaryHandler{log: log, mgr: mgr}
+}
+
+// easytierVersionReq 版本操作请求
+type easytierVersionReq struct {
+	Version string `json:"version"`
+	SHA256  string `json:"sha256"`
+}
+
+// Status 已安装版本、当前版本与固定版本
+func (h *EasytierBinaryHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": h.mgr.GetBinaryStatus()})
+}
+
+// Release 查询发行版（默认最新版）中适用于当前平台的发行包
+func (h *EasytierBinaryHandler) Release(c *gin.Context) {
+	rel, err := h.mgr.GetRelease(c.Query("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": rel})
+}
+
+// Install 在线下载安装（版本为空时安装固定版本或最新版），运行中的实例会重启到新版本
+func (h *EasytierBinaryHandler) Install(c *gin.Context) {
+	var req easytierVersionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	v, err := h.mgr.InstallRelease(req.Version, req.SHA256)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": v, "message": "安装成功"})
+}
+
+// Upload 离线安装：上传发行包（zip/tar.gz），可选填写版本号与 SHA256
+func (h *EasytierBinaryHandler) Upload(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请上传发行包"})
+		return
+	}
+	if fh.Size > 200<<20 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "发行包过大"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	v, err := h.mgr.InstallArchive(fh.Filename, data, c.PostForm("version"), c.PostForm("sha256"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": v, "message": "安装成功"})
+}
+
+// Activate 切换到已安装的版本（升级或回滚）
+func (h *EasytierBinaryHandler) Activate(c *gin.Context) {
+	var req easytierVersionReq
+	if err := c.ShouldBindJSON(&req); err != nil || req.Version == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请指定版本"})
+		return
+	}
+	if err := h.mgr.ActivateVersion(req.Version); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已切换到 " + req.Version})
+}
+
+// Pin 固定版本（版本为空时取消固定）
+func (h *EasytierBinaryHandler) Pin(c *gin.Context) {
+	var req easytierVersionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.PinVersion(req.Version); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "设置成功"})
+}
+
+// Remove 删除已安装的非当前版本
+func (h *EasytierBinaryHandler) Remove(c *gin.Context) {
+	if err := h.mgr.RemoveVersion(c.Param("version")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// ===== EasyTier 配置服务器（config-server） =====
+
+type EasytierWebHandler struct {
+	db  *gorm.DB
+	log *logrus.Logger
+	mgr *easytier.Manager
+}
+
+func NewEasytierWebHandler(db *gorm.DB, log *logrus.Logger, mgr *easytier.Manager) *EasytierWebHandler {
+	return &EasytierWebHandler{db: db, log: log, mgr: mgr}
+}
+
+func (h *EasytierWebHandler) List(c *gin.Context) {
+	var list []model.EasytierWebServer
+	h.db.Order("id desc").Find(&list)
+	for i := range list {
+		list[i].Status = h.mgr.GetWebServerStatus(list[i].ID)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": list})
+}
+
+func (h *EasytierWebHandler) Create(c *gin.Context) {
+	var srv model.EasytierWebServer
+	if err := c.ShouldBindJSON(&srv); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := easytier.ValidateWebServer(&srv); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	srv.Status = "stopped"
+	h.db.Create(&srv)
+	if srv.Enable {
+		h.mgr.StartWebServer(srv.ID)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": srv, "message": "创建成功"})
+}
+
+func (h *EasytierWebHandler) Update(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var req model.EasytierWebServer
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := easytier.ValidateWebServer(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	h.mgr.StopWebServer(uint(id))
+	req.ID = uint(id)
+	h.db.Save(&req)
+	if req.Enable {
+		h.mgr.StartWebServer(uint(id))
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": req, "message": "更新成功"})
+}
+
+func (h *EasytierWebHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	h.mgr.StopWebServer(uint(id))
+	h.db.Where("web_server_id = ?", id).Delete(&model.EasytierMachine{})
+	h.db.Delete(&model.EasytierWebServer{}, id)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+func (h *EasytierWebHandler) Start(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err := h.mgr.StartWebServer(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": err.Error()})
+		return
+	}
+	h.db.Model(&model.EasytierWebServer{}).Where("id = ?", id).Update("enable", true)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已启动"})
+}
+
+func (h *EasytierWebHandler) Stop(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	h.mgr.StopWebServer(uint(id))
+	h.db.Model(&model.EasytierWebServer{}).Where("id = ?", id).Update("enable", false)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已停止"})
+}
+
+func (h *EasytierWebHandler) GetStatus(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{
+		"status":  h.mgr.GetWebServerStatus(uint(id)),
+		"process": h.mgr.GetWebServerProcess(uint(id)),
+	}})
+}
+
+// Machines 接入的机器列表（含在线状态与审批状态）
+func (h *EasytierWebHandler) Machines(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": h.mgr.ListMachines(uint(id))})
+}
+
+// UpdateMachine 更新下发给机器的网络配置
+func (h *EasytierWebHandler) UpdateMachine(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	mid, _ := strconv.ParseUint(c.Param("mid"), 10, 64)
+	var req model.EasytierMachine
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.UpdateMachineConfig(uint(id), uint(mid), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "更新成功"})
+}
+
+func (h *EasytierWebHandler) setApproval(c *gin.Context, approval, message string) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	mid, _ := strconv.ParseUint(c.Param("mid"), 10, 64)
+	if err := h.mgr.SetMachineApproval(uint(id), uint(mid), approval); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": message})
+}
+
+// ApproveMachine 审批通过，下发网络配置
+func (h *EasytierWebHandler) ApproveMachine(c *gin.Context) {
+	h.setApproval(c, easytier.MachineApproved, "已审批")
+}
+
+// RevokeMachine 撤销，删除机器上的全部网络实例
+func (h *EasytierWebHandler) RevokeMachine(c *gin.Context) {
+	h.setApproval(c, easytier.MachineRevoked, "已撤销")
+}
+
+func (h *EasytierWebHandler) DeleteMachine(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	mid, _ := strconv.ParseUint(c.Param("mid"), 10, 64)
+	if err := h.mgr.DeleteMachine(uint(id), uint(mid)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// MachineNetworks 机器上网络实例的运行信息
+func (h *EasytierWebHandler) MachineNetworks(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	mid, _ := strconv.ParseUint(c.Param("mid"), 10, 64)
+	data, err := h.mgr.GetMachineNetworks(uint(id), uint(mid))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": data})
+}
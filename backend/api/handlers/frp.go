@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/pkg/utils"
 	"github.com/netpanel/netpanel/service/frp"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -27,7 +29,7 @@ func NewFrpcHandler(db *gorm.DB, log *logrus.Logger, mgr *frp.Manager) *FrpcHand
 
 func (h *FrpcHandler) List(c *gin.Context) {
 	var configs []model.FrpcConfig
-	h.db.Preload("Proxies").Order("id desc").Find(&configs)
+	h.db.Preload("Proxies").Preload("Visitors").Order("id desc").Find(&configs)
 	for i := range configs {
 		configs[i].Status = h.mgr.GetClientStatus(configs[i].ID)
 	}
@@ -68,10 +70,38 @@ func (h *FrpcHandler) Delete(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	h.mgr.StopClient(uint(id))
 	h.db.Where("frpc_id = ?", id).Delete(&model.FrpcProxy{})
+	h.db.Where("frpc_id = ?", id).Delete(&model.FrpcVisitor{})
 	h.db.Delete(&model.FrpcConfig{}, id)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
 }
 
+// Import 导入 frpc 配置文件（toml/yaml/json/ini），表单字段 file 为配置文件，name 为可选名称
+func (h *FrpcHandler) Import(c *gin.Context) {
+	content, err := readUploadedConfig(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	cfg, err := h.mgr.ImportClientConfig(c.PostForm("name"), content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": cfg, "message": "导入成功"})
+}
+
+// Export 导出 frpc 原生配置文件，format 可选 toml（默认）/yaml/json
+func (h *FrpcHandler) Export(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	format := c.DefaultQuery("format", "toml")
+	data, err := h.mgr.ExportClientConfig(uint(id), format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	sendConfigFile(c, fmt.Sprintf("frpc-%d.%s", id, format), data)
+}
+
 func (h *FrpcHandler) Start(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err := h.mgr.StartClient(uint(id)); err != nil {
@@ -99,6 +129,12 @@ func (h *FrpcHandler) Restart(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已重启"})
 }
 
+// Status 获取 FRP 客户端登录状态及各代理运行阶段
+func (h *FrpcHandler) Status(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": h.mgr.GetClientRuntimeStatus(uint(id))})
+}
+
 func (h *FrpcHandler) ListProxies(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	var proxies []model.FrpcProxy
@@ -113,10 +149,19 @@ func (h *FrpcHandler) CreateProxy(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
+	if proxy.PluginType != "" {
+		if _, err := frp.ParsePluginConfig(proxy.PluginType, proxy.PluginConfig); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+	}
 	proxy.FrpcID = uint(id)
 	h.db.Create(&proxy)
-	// 重启客户端以应用新代理
-	h.mgr.RestartClient(uint(id))
+	// 热更新客户端以应用新代理
+	if err := h.mgr.ReloadClient(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "data": proxy, "message": "已保存，但应用失败: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "data": proxy, "message": "创建成功"})
 }
 
@@ -128,10 +173,19 @@ func (h *FrpcHandler) UpdateProxy(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
+	if proxy.PluginType != "" {
+		if _, err := frp.ParsePluginConfig(proxy.PluginType, proxy.PluginConfig); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+			return
+		}
+	}
 	proxy.ID = uint(pid)
 	proxy.FrpcID = uint(id)
 	h.db.Save(&proxy)
-	h.mgr.RestartClient(uint(id))
+	if err := h.mgr.ReloadClient(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "data": proxy, "message": "已保存，但应用失败: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "data": proxy, "message": "更新成功"})
 }
 
@@ -139,7 +193,71 @@ func (h *FrpcHandler) DeleteProxy(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	pid, _ := strconv.ParseUint(c.Param("pid"), 10, 64)
 	h.db.Delete(&model.FrpcProxy{}, pid)
-	h.mgr.RestartClient(uint(id))
+	if err := h.mgr.ReloadClient(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "已删除，但应用失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+func (h *FrpcHandler) ListVisitors(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var visitors []model.FrpcVisitor
+	h.db.Where("frpc_id = ?", id).Find(&visitors)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": visitors})
+}
+
+func (h *FrpcHandler) CreateVisitor(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var visitor model.FrpcVisitor
+	if err := c.ShouldBindJSON(&visitor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := frp.ValidateVisitor(&visitor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	visitor.FrpcID = uint(id)
+	h.db.Create(&visitor)
+	// 热更新客户端以应用新访问者
+	if err := h.mgr.ReloadClient(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "data": visitor, "message": "已保存，但应用失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": visitor, "message": "创建成功"})
+}
+
+func (h *FrpcHandler) UpdateVisitor(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	vid, _ := strconv.ParseUint(c.Param("vid"), 10, 64)
+	var visitor model.FrpcVisitor
+	if err := c.ShouldBindJSON(&visitor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := frp.ValidateVisitor(&visitor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	visitor.ID = uint(vid)
+	visitor.FrpcID = uint(id)
+	h.db.Save(&visitor)
+	if err := h.mgr.ReloadClient(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "data": visitor, "message": "已保存，但应用失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": visitor, "message": "更新成功"})
+}
+
+func (h *FrpcHandler) DeleteVisitor(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	vid, _ := strconv.ParseUint(c.Param("vid"), 10, 64)
+	h.db.Delete(&model.FrpcVisitor{}, vid)
+	if err := h.mgr.ReloadClient(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "已删除，但应用失败: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
 }
 
@@ -197,6 +315,7 @@ func (h *FrpsHandler) Update(c *gin.Context) {
 func (h *FrpsHandler) Delete(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	h.mgr.StopServer(uint(id))
+	h.db.Where("frps_id = ?", id).Delete(&model.FrpsUser{})
 	h.db.Delete(&model.FrpsConfig{}, id)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
 }
@@ -218,6 +337,124 @@ func (h *FrpsHandler) Stop(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已停止"})
 }
 
+// Import 导入 frps 配置文件（toml/yaml/json/ini），表单字段 file 为配置文件，name 为可选名称
+func (h *FrpsHandler) Import(c *gin.Context) {
+	content, err := readUploadedConfig(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	cfg, err := h.mgr.ImportServerConfig(c.PostForm("name"), content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": cfg, "message": "导入成功"})
+}
+
+// Export 导出 frps 原生配置文件，format 可选 toml（默认）/yaml/json
+func (h *FrpsHandler) Export(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	format := c.DefaultQuery("format", "toml")
+	data, err := h.mgr.ExportServerConfig(uint(id), format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	sendConfigFile(c, fmt.Sprintf("frps-%d.%s", id, format), data)
+}
+
+// ===== FRP 服务端用户 =====
+
+func (h *FrpsHandler) ListUsers(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var users []model.FrpsUser
+	h.db.Where("frps_id = ?", id).Order("id desc").Find(&users)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": users})
+}
+
+func (h *FrpsHandler) CreateUser(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var user model.FrpsUser
+	if err := c.ShouldBindJSON(&user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if user.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "用户名不能为空"})
+		return
+	}
+	if err := frp.ValidateAllowPorts(user.AllowPorts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	var count int64
+	h.db.Model(&model.FrpsUser{}).Where("frps_id = ? AND username = ?", id, user.Username).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "用户名已存在"})
+		return
+	}
+	// 未指定 token 时自动生成
+	if user.Token == "" {
+		user.Token = utils.GenerateKey(32)
+	}
+	user.FrpsID = uint(id)
+	h.db.Create(&user)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": user, "message": "创建成功"})
+}
+
+func (h *FrpsHandler) UpdateUser(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	uid, _ := strconv.ParseUint(c.Param("uid"), 10, 64)
+	var user model.FrpsUser
+	if err := h.db.Where("frps_id = ? AND id = ?", id, uid).First(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "用户不存在"})
+		return
+	}
+	// 在已有记录上解析请求体，只覆盖请求中携带的字段
+	if err := c.ShouldBindJSON(&user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if user.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "用户名不能为空"})
+		return
+	}
+	if err := frp.ValidateAllowPorts(user.AllowPorts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	var count int64
+	h.db.Model(&model.FrpsUser{}).Where("frps_id = ? AND username = ? AND id <> ?", id, user.Username, uid).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "用户名已存在"})
+		return
+	}
+	user.ID = uint(uid)
+	user.FrpsID = uint(id)
+	// 用户配置由插件实时读取，无需重启服务端
+	h.db.Save(&user)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": user, "message": "更新成功"})
+}
+
+func (h *FrpsHandler) DeleteUser(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	uid, _ := strconv.ParseUint(c.Param("uid"), 10, 64)
+	h.db.Where("frps_id = ? AND id = ?", id, uid).Delete(&model.FrpsUser{})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// Clients 获取 frps 已连接客户端、代理及流量统计
+func (h *FrpsHandler) Clients(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	info, err := h.mgr.GetServerRuntimeInfo(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": info})
+}
+
 // GetDashboardURL 返回 frps Dashboard 的访问地址
 func (h *FrpsHandler) GetDashboardURL(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -253,3 +490,26 @@ func (h *FrpsHandler) GetDashboardURL(c *gin.Context) {
 	url := fmt.Sprintf("http://%s:%d", addr, cfg.DashboardPort)
 	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{"url": url}})
 }
+
+// readUploadedConfig 读取上传的配置文件内容（限制 1MB）
+func readUploadedConfig(c *gin.Context) ([]byte, error) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("请上传配置文件: %w", err)
+	}
+	if fh.Size > 1<<20 {
+		return nil, fmt.Errorf("配置文件过大")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// sendConfigFile 以附件形式返回配置文件
+func sendConfigFile(c *gin.Context, filename string, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/octet-stream", data)
+}
@@ -90,6 +90,242 @@ func (h *NpsServerHandler) Stop(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已停止"})
 }
 
+// GetStatus 获取服务端运行状态及子进程守护信息（运行时长、重启次数、最近输出）
+func (h *NpsServerHandler) GetStatus(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{
+		"status":  h.mgr.GetServerStatus(uint(id)),
+		"process": h.mgr.GetServerProcess(uint(id)),
+	}})
+}
+
+// ===== NPS 服务端数据管理（客户端/隧道/域名，通过 NPS Web API） =====
+
+// npsListQuery 解析列表查询参数
+func npsListQuery(c *gin.Context) nps.ListQuery {
+	clientID, _ := strconv.Atoi(c.Query("client_id"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return nps.ListQuery{
+		ClientID: clientID,
+		Type:     c.Query("type"),
+		Search:   c.Query("search"),
+		Offset:   offset,
+		Limit:    limit,
+	}
+}
+
+// npsStatusReq 启用/停用请求
+type npsStatusReq struct {
+	Enable bool `json:"enable"`
+}
+
+func (h *NpsServerHandler) ListClients(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	res, err := h.mgr.ListServerClients(uint(id), npsListQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": res})
+}
+
+func (h *NpsServerHandler) CreateClient(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var req nps.ServerClientReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	cid, err := h.mgr.CreateServerClient(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{"id": cid}, "message": "创建成功"})
+}
+
+func (h *NpsServerHandler) UpdateClient(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	cid, _ := strconv.Atoi(c.Param("cid"))
+	var req nps.ServerClientReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.UpdateServerClient(uint(id), cid, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "更新成功"})
+}
+
+func (h *NpsServerHandler) SetClientStatus(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	cid, _ := strconv.Atoi(c.Param("cid"))
+	var req npsStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.SetServerClientStatus(uint(id), cid, req.Enable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "操作成功"})
+}
+
+func (h *NpsServerHandler) DeleteClient(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	cid, _ := strconv.Atoi(c.Param("cid"))
+	if err := h.mgr.DeleteServerClient(uint(id), cid); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+func (h *NpsServerHandler) ListTunnels(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	res, err := h.mgr.ListServerTunnels(uint(id), npsListQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": res})
+}
+
+func (h *NpsServerHandler) CreateTunnel(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var req nps.ServerTunnelReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	tid, err := h.mgr.CreateServerTunnel(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{"id": tid}, "message": "创建成功"})
+}
+
+func (h *NpsServerHandler) UpdateTunnel(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	tid, _ := strconv.Atoi(c.Param("tid"))
+	var req nps.ServerTunnelReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.UpdateServerTunnel(uint(id), tid, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "更新成功"})
+}
+
+func (h *NpsServerHandler) SetTunnelStatus(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	tid, _ := strconv.Atoi(c.Param("tid"))
+	var req npsStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.SetServerTunnelStatus(uint(id), tid, req.Enable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "操作成功"})
+}
+
+func (h *NpsServerHandler) DeleteTunnel(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	tid, _ := strconv.Atoi(c.Param("tid"))
+	if err := h.mgr.DeleteServerTunnel(uint(id), tid); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+func (h *NpsServerHandler) ListHosts(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	res, err := h.mgr.ListServerHosts(uint(id), npsListQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": res})
+}
+
+func (h *NpsServerHandler) CreateHost(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var req nps.ServerHostReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	hid, err := h.mgr.CreateServerHost(uint(id), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": gin.H{"id": hid}, "message": "创建成功"})
+}
+
+func (h *NpsServerHandler) UpdateHost(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	hid, _ := strconv.Atoi(c.Param("hid"))
+	var req nps.ServerHostReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.UpdateServerHost(uint(id), hid, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "更新成功"})
+}
+
+func (h *NpsServerHandler) SetHostStatus(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	hid, _ := strconv.Atoi(c.Param("hid"))
+	var req npsStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	if err := h.mgr.SetServerHostStatus(uint(id), hid, req.Enable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "操作成功"})
+}
+
+func (h *NpsServerHandler) DeleteHost(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	hid, _ := strconv.Atoi(c.Param("hid"))
+	if err := h.mgr.DeleteServerHost(uint(id), hid); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
+}
+
+// Stats 服务端总览统计（客户端/隧道数量、流量、系统负载）
+func (h *NpsServerHandler) Stats(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	data, err := h.mgr.GetServerStats(uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": data})
+}
+
 // ===== NPS 客户端 =====
 
 type NpsClientHandler struct {
@@ -169,6 +405,12 @@ func (h *NpsClientHandler) Stop(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "已停止"})
 }
 
+// Status 获取客户端连接状态及各隧道注册状态
+func (h *NpsClientHandler) Status(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "data": h.mgr.GetClientRuntimeStatus(uint(id))})
+}
+
 // ===== NPS 隧道 =====
 
 func (h *NpsClientHandler) ListTunnels(c *gin.Context) {
@@ -185,25 +427,48 @@ func (h *NpsClientHandler) CreateTunnel(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
+	if err := nps.ValidateTunnel(&tunnel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
 	tunnel.NpsClientID = uint(clientID)
 	h.db.Create(&tunnel)
+	if err := h.mgr.ReloadClient(uint(clientID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "data": tunnel, "message": "已保存，但应用失败: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "data": tunnel, "message": "创建成功"})
 }
 
 func (h *NpsClientHandler) UpdateTunnel(c *gin.Context) {
+	clientID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	tid, _ := strconv.ParseUint(c.Param("tid"), 10, 64)
 	var req model.NpsTunnel
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
 		return
 	}
+	if err := nps.ValidateTunnel(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": err.Error()})
+		return
+	}
 	req.ID = uint(tid)
+	req.NpsClientID = uint(clientID)
 	h.db.Save(&req)
+	if err := h.mgr.ReloadClient(uint(clientID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "data": req, "message": "已保存，但应用失败: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "data": req, "message": "更新成功"})
 }
 
 func (h *NpsClientHandler) DeleteTunnel(c *gin.Context) {
+	clientID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	tid, _ := strconv.ParseUint(c.Param("tid"), 10, 64)
-	h.db.Delete(&model.NpsTunnel{}, tid)
+	h.db.Where("nps_client_id = ?", clientID).Delete(&model.NpsTunnel{}, tid)
+	if err := h.mgr.ReloadClient(uint(clientID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "已删除，但应用失败: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "message": "删除成功"})
 }
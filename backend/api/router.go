@@ -100,25 +100,41 @@ func NewRouter(opts RouterOptions) *gin.Engine {
 	frpcHandler := handlers.NewFrpcHandler(opts.DB, opts.Log, opts.FrpMgr)
 	auth.GET("/frpc", frpcHandler.List)
 	auth.POST("/frpc", frpcHandler.Create)
+	auth.POST("/frpc/import", frpcHandler.Import)
 	auth.PUT("/frpc/:id", frpcHandler.Update)
 	auth.DELETE("/frpc/:id", frpcHandler.Delete)
 	auth.POST("/frpc/:id/start", frpcHandler.Start)
 	auth.POST("/frpc/:id/stop", frpcHandler.Stop)
 	auth.POST("/frpc/:id/restart", frpcHandler.Restart)
+	auth.GET("/frpc/:id/status", frpcHandler.Status)
+	auth.GET("/frpc/:id/export", frpcHandler.Export)
 	// FRP 代理
 	auth.GET("/frpc/:id/proxies", frpcHandler.ListProxies)
 	auth.POST("/frpc/:id/proxies", frpcHandler.CreateProxy)
 	auth.PUT("/frpc/:id/proxies/:pid", frpcHandler.UpdateProxy)
 	auth.DELETE("/frpc/:id/proxies/:pid", frpcHandler.DeleteProxy)
+	// FRP 访问者（stcp/xtcp/sudp）
+	auth.GET("/frpc/:id/visitors", frpcHandler.ListVisitors)
+	auth.POST("/frpc/:id/visitors", frpcHandler.CreateVisitor)
+	auth.PUT("/frpc/:id/visitors/:vid", frpcHandler.UpdateVisitor)
+	auth.DELETE("/frpc/:id/visitors/:vid", frpcHandler.DeleteVisitor)
 
 	// FRP 服务端
 	frpsHandler := handlers.NewFrpsHandler(opts.DB, opts.Log, opts.FrpMgr)
 	auth.GET("/frps", frpsHandler.List)
 	auth.POST("/frps", frpsHandler.Create)
+	auth.POST("/frps/import", frpsHandler.Import)
 	auth.PUT("/frps/:id", frpsHandler.Update)
 	auth.DELETE("/frps/:id", frpsHandler.Delete)
 	auth.POST("/frps/:id/start", frpsHandler.Start)
 	auth.POST("/frps/:id/stop", frpsHandler.Stop)
+	auth.GET("/frps/:id/clients", frpsHandler.Clients)
+	auth.GET("/frps/:id/export", frpsHandler.Export)
+	// FRP 服务端用户（多用户鉴权）
+	auth.GET("/frps/:id/users", frpsHandler.ListUsers)
+	auth.POST("/frps/:id/users", frpsHandler.CreateUser)
+	auth.PUT("/frps/:id/users/:uid", frpsHandler.UpdateUser)
+	auth.DELETE("/frps/:id/users/:uid", frpsHandler.DeleteUser)
 
 	// NPS 服务端
 	npsServerHandler := handlers.NewNpsServerHandler(opts.DB, opts.Log, opts.NpsMgr)
@@ -128,6 +144,24 @@ func NewRouter(opts RouterOptions) *gin.Engine {
 	auth.DELETE("/nps/server/:id", npsServerHandler.Delete)
 	auth.POST("/nps/server/:id/start", npsServerHandler.Start)
 	auth.POST("/nps/server/:id/stop", npsServerHandler.Stop)
+	auth.GET("/nps/server/:id/status", npsServerHandler.GetStatus)
+	// NPS 服务端数据（通过 NPS Web API 管理）
+	auth.GET("/nps/server/:id/stats", npsServerHandler.Stats)
+	auth.GET("/nps/server/:id/clients", npsServerHandler.ListClients)
+	auth.POST("/nps/server/:id/clients", npsServerHandler.CreateClient)
+	auth.PUT("/nps/server/:id/clients/:cid", npsServerHandler.UpdateClient)
+	auth.POST("/nps/server/:id/clients/:cid/status", npsServerHandler.SetClientStatus)
+	auth.DELETE("/nps/server/:id/clients/:cid", npsServerHandler.DeleteClient)
+	auth.GET("/nps/server/:id/tunnels", npsServerHandler.ListTunnels)
+	auth.POST("/nps/server/:id/tunnels", npsServerHandler.CreateTunnel)
+	auth.PUT("/nps/server/:id/tunnels/:tid", npsServerHandler.UpdateTunnel)
+	auth.POST("/nps/server/:id/tunnels/:tid/status", npsServerHandler.SetTunnelStatus)
+	auth.DELETE("/nps/server/:id/tunnels/:tid", npsServerHandler.DeleteTunnel)
+	auth.GET("/nps/server/:id/hosts", npsServerHandler.ListHosts)
+	auth.POST("/nps/server/:id/hosts", npsServerHandler.CreateHost)
+	auth.PUT("/nps/server/:id/hosts/:hid", npsServerHandler.UpdateHost)
+	auth.POST("/nps/server/:id/hosts/:hid/status", npsServerHandler.SetHostStatus)
+	auth.DELETE("/nps/server/:id/hosts/:hid", npsServerHandler.DeleteHost)
 
 	// NPS 客户端
 	npsClientHandler := handlers.NewNpsClientHandler(opts.DB, opts.Log, opts.NpsMgr)
@@ -137,6 +171,7 @@ func NewRouter(opts RouterOptions) *gin.Engine {
 	auth.DELETE("/nps/client/:id", npsClientHandler.Delete)
 	auth.POST("/nps/client/:id/start", npsClientHandler.Start)
 	auth.POST("/nps/client/:id/stop", npsClientHandler.Stop)
+	auth.GET("/nps/client/:id/status", npsClientHandler.Status)
 	// NPS 隧道（子表，参考 nps 隧道类型）
 	auth.GET("/nps/client/:id/tunnels", npsClientHandler.ListTunnels)
 	auth.POST("/nps/client/:id/tunnels", npsClientHandler.CreateTunnel)
@@ -150,11 +185,21 @@ func NewRouter(opts RouterOptions) *gin.Engine {
 	etHandler := handlers.NewEasytierHandler(opts.DB, opts.Log, opts.EasytierMgr)
 	auth.GET("/easytier/client", etHandler.List)
 	auth.POST("/easytier/client", etHandler.Create)
+	auth.POST("/easytier/client/import", etHandler.Import)
 	auth.PUT("/easytier/client/:id", etHandler.Update)
 	auth.DELETE("/easytier/client/:id", etHandler.Delete)
 	auth.POST("/easytier/client/:id/start", etHandler.Start)
 	auth.POST("/easytier/client/:id/stop", etHandler.Stop)
 	auth.GET("/easytier/client/:id/status", etHandler.GetStatus)
+	auth.GET("/easytier/client/:id/peers", etHandler.Peers)
+	auth.GET("/easytier/client/:id/routes", etHandler.Routes)
+	auth.GET("/easytier/client/:id/export", etHandler.Export)
+	auth.GET("/easytier/client/:id/vpn-devices", etHandler.ListVpnDevices)
+	auth.POST("/easytier/client/:id/vpn-devices", etHandler.CreateVpnDevice)
+	auth.DELETE("/easytier/client/:id/vpn-devices/:did", etHandler.DeleteVpnDevice)
+	auth.GET("/easytier/client/:id/vpn-devices/:did/config", etHandler.VpnDeviceConfig)
+	auth.GET("/easytier/client/:id/vpn-devices/:did/qrcode", etHandler.VpnDeviceQRCode)
+	auth.GET("/easytier/topology", etHandler.Topology)
 
 	// EasyTier 服务端
 	etsHandler := handlers.NewEasytierServerHandler(opts.DB, opts.Log, opts.EasytierMgr)
@@ -164,6 +209,34 @@ func NewRouter(opts RouterOptions) *gin.Engine {
 	auth.DELETE("/easytier/server/:id", etsHandler.Delete)
 	auth.POST("/easytier/server/:id/start", etsHandler.Start)
 	auth.POST("/easytier/server/:id/stop", etsHandler.Stop)
+	auth.GET("/easytier/server/:id/status", etsHandler.GetStatus)
+	auth.GET("/easytier/server/:id/export", etsHandler.Export)
+
+	// EasyTier 配置服务器（远端 easytier-core --config-server 接入）
+	etwHandler := handlers.NewEasytierWebHandler(opts.DB, opts.Log, opts.EasytierMgr)
+	auth.GET("/easytier/web", etwHandler.List)
+	auth.POST("/easytier/web", etwHandler.Create)
+	auth.PUT("/easytier/web/:id", etwHandler.Update)
+	auth.DELETE("/easytier/web/:id", etwHandler.Delete)
+	auth.POST("/easytier/web/:id/start", etwHandler.Start)
+	auth.POST("/easytier/web/:id/stop", etwHandler.Stop)
+	auth.GET("/easytier/web/:id/status", etwHandler.GetStatus)
+	auth.GET("/easytier/web/:id/machines", etwHandler.Machines)
+	auth.PUT("/easytier/web/:id/machines/:mid", etwHandler.UpdateMachine)
+	auth.POST("/easytier/web/:id/machines/:mid/approve", etwHandler.ApproveMachine)
+	auth.POST("/easytier/web/:id/machines/:mid/revoke", etwHandler.RevokeMachine)
+	auth.DELETE("/easytier/web/:id/machines/:mid", etwHandler.DeleteMachine)
+	auth.GET("/easytier/web/:id/machines/:mid/networks", etwHandler.MachineNetworks)
+
+	// EasyTier 二进制管理
+	etbHandler := handlers.NewEasytierBinaryHandler(opts.Log, opts.EasytierMgr)
+	auth.GET("/easytier/binary", etbHandler.Status)
+	auth.GET("/easytier/binary/release", etbHandler.Release)
+	auth.POST("/easytier/binary/install", etbHandler.Install)
+	auth.POST("/easytier/binary/upload", etbHandler.Upload)
+	auth.POST("/easytier/binary/activate", etbHandler.Activate)
+	auth.POST("/easytier/binary/pin", etbHandler.Pin)
+	auth.DELETE("/easytier/binary/:version", etbHandler.Remove)
 
 	// DDNS
 	ddnsHandler := handlers.NewDDNSHandler(opts.DB, opts.Log, opts.DdnsMgr)
@@ -200,6 +273,7 @@ func NewRouter(opts RouterOptions) *gin.Engine {
 	auth.PUT("/domain/accounts/:id", daHandler.Update)
 	auth.DELETE("/domain/accounts/:id", daHandler.Delete)
 	auth.POST("/domain/accounts/:id/test", daHandler.Test)
+	auth.GET("/domain/providers", daHandler.Providers)
 
 	// 域名管理（域名列表，参考 dnsmgr domain 表）
 	diHandler := handlers.NewDomainInfoHandler(opts.DB, opts.Log)
@@ -205,6 +205,11 @@ func startServer() *http.Server {
 	callbackMgr := callback.NewManager(db, logCallback)
 	firewallMgr := firewall.NewManager(db, logFirewall)
 
+	// STUN 公网端点变化时通过 DDNS 服务商发布 DNS 记录
+	stunMgr.SetEndpointPublisher(ddnsMgr)
+	// 证书续期后重启引用该证书的 FRP 服务端
+	certMgr.SetUpdateNotifier(frpMgr)
+
 	// 非管理员时：将数据库中所有 EasyTier 客户端/服务端的 no_tun 强制置为 true
 	if !isAdmin {
 		applyNoTunFallback(db, log)
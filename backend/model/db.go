@@ -60,11 +60,16 @@ func autoMigrate(db *gorm.DB) error {
 		&StunRule{},
 		&FrpcConfig{},
 		&FrpcProxy{},
+		&FrpcVisitor{},
 		&FrpsConfig{},
+		&FrpsUser{},
 		&NpsServerConfig{},
 		&NpsClientConfig{},
 		&EasytierClient{},
+		&EasytierVpnDevice{},
 		&EasytierServer{},
+		&EasytierWebServer{},
+		&EasytierMachine{},
 		&NpsTunnel{},
 		&DDNSTask{},
 		&DDNSHistory{},
@@ -110,6 +115,9 @@ func initDefaultData(db *gorm.DB) {
 		})
 	}
 
+	// EasyTier 离线模式（后续版本新增的配置项，已有数据库同样需要补齐）
+	db.Where(SystemConfig{Key: "easytier_offline_mode"}).FirstOrCreate(&SystemConfig{Key: "easytier_offline_mode", Value: "false"})
+
 	// 初始化默认 admin 用户（若不存在）
 	var userCount int64
 	db.Model(&User{}).Where("username = ?", "admin").Count(&userCount)
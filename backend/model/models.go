@@ -91,6 +91,16 @@ type StunRule struct {
 	// ===== 回调 =====
 	CallbackTaskID uint `json:"callback_task_id"`
 
+	// ===== DNS 发布 =====
+	// 公网端点变化时，通过域名账号将当前 IP/端口发布为 DNS 记录，供客户端解析
+	DNSPublishEnable    bool   `gorm:"default:false" json:"dns_publish_enable"`
+	DNSPublishAccountID uint   `json:"dns_publish_account_id"`                        // 关联域名账号（DomainAccount）
+	DNSPublishType      string `gorm:"size:20;default:'srv'" json:"dns_publish_type"` // srv/txt/https
+	DNSPublishDomain    string `gorm:"size:255" json:"dns_publish_domain"`            // 发布域名，如 mc.example.com
+	// SRV 服务名（不含下划线），如 minecraft，最终记录为 _minecraft._tcp.mc.example.com
+	DNSPublishService string `gorm:"size:100" json:"dns_publish_service"`
+	DNSPublishTTL     int    `gorm:"default:120" json:"dns_publish_ttl"`
+
 	// ===== 运行时状态 =====
 	CurrentIP   string `gorm:"size:100" json:"current_ip"`
 	CurrentPort int    `json:"current_port"`
@@ -158,6 +168,7 @@ type FrpcConfig struct {
 	WebServerPassword string `gorm:"size:255" json:"web_server_password"`
 	LogLevel          string      `gorm:"size:20;default:'info'" json:"log_level"`
 	Proxies           []FrpcProxy `gorm:"foreignKey:FrpcID" json:"proxies"`
+	Visitors          []FrpcVisitor `gorm:"foreignKey:FrpcID" json:"visitors"`
 	Status            string      `gorm:"size:20;default:'stopped'" json:"status"`
 	LastError         string      `gorm:"type:text" json:"last_error"`
 	Remark            string      `gorm:"size:500" json:"remark"`
@@ -206,11 +217,44 @@ type FrpcProxy struct {
 	LoadBalancerGroup    string `gorm:"size:100" json:"load_balancer_group"`     // 负载均衡组名，空表示不参与
 	LoadBalancerGroupKey string `gorm:"size:255" json:"load_balancer_group_key"` // 组密钥
 	// 插件（参考 frp plugin）
-	PluginType   string `gorm:"size:50" json:"plugin_type"`     // http_proxy/socks5/static_file/unix_domain_socket
-	PluginConfig string `gorm:"type:text" json:"plugin_config"` // JSON 配置
+	PluginType   string `gorm:"size:50" json:"plugin_type"`     // socks5/http_proxy/static_file/unix_domain_socket/http2https/https2http/https2https/tls2raw
+	PluginConfig string `gorm:"type:text" json:"plugin_config"` // JSON 配置，字段名同 frp 配置文件，如 {"localAddr":"127.0.0.1:8080"}
+	// https2http/https2https/tls2raw 插件关联的域名证书 ID，0 表示使用 PluginConfig 中的 crtPath/keyPath
+	PluginCertID uint   `gorm:"default:0" json:"plugin_cert_id"`
 	Remark       string `gorm:"size:500" json:"remark"`
 }
 
+// FrpcVisitor FRP 访问者配置（子表，用于访问其他客户端的 stcp/xtcp/sudp 代理）
+type FrpcVisitor struct {
+	BaseModel
+	FrpcID uint   `gorm:"not null;index" json:"frpc_id"`
+	Name   string `gorm:"size:100;not null" json:"name"`
+	// 访问者类型：stcp/xtcp/sudp，需与被访问代理类型一致
+	Type string `gorm:"size:20;not null;default:'stcp'" json:"type"`
+	// 被访问代理所属用户，留空表示与当前客户端用户相同
+	ServerUser string `gorm:"size:100" json:"server_user"`
+	// 被访问代理名称
+	ServerName string `gorm:"size:100;not null" json:"server_name"`
+	SecretKey  string `gorm:"size:255" json:"secret_key"`
+	// 本地监听地址与端口，访问该端口即访问远端代理
+	BindAddr string `gorm:"size:100;default:'127.0.0.1'" json:"bind_addr"`
+	BindPort int    `json:"bind_port"`
+	// 加密压缩
+	UseEncryption  bool `gorm:"default:false" json:"use_encryption"`
+	UseCompression bool `gorm:"default:false" json:"use_compression"`
+	// XTCP 专用：打洞隧道协议 quic/kcp，默认 quic
+	Protocol string `gorm:"size:20;default:'quic'" json:"protocol"`
+	// XTCP 专用：保持隧道常开，便于快速建立连接
+	KeepTunnelOpen   bool `gorm:"default:false" json:"keep_tunnel_open"`
+	MaxRetriesAnHour int  `gorm:"default:8" json:"max_retries_an_hour"`
+	MinRetryInterval int  `gorm:"default:90" json:"min_retry_interval"` // 秒
+	// XTCP 专用：打洞失败时回退到的访问者名称（通常为同一代理的 stcp 访问者）
+	FallbackTo        string `gorm:"size:100" json:"fallback_to"`
+	FallbackTimeoutMs int    `gorm:"default:1000" json:"fallback_timeout_ms"`
+	Enable            bool   `gorm:"default:true" json:"enable"`
+	Remark            string `gorm:"size:500" json:"remark"`
+}
+
 // ===== FRP 服务端 =====
 
 // FrpsConfig FRP 服务端配置
@@ -232,6 +276,10 @@ type FrpsConfig struct {
 	VhostHTTPTimeout int `gorm:"default:60" json:"vhost_http_timeout"`
 	// HTTPS 虚拟主机端口，0 表示不启用
 	VhostHTTPSPort int `gorm:"default:0" json:"vhost_https_port"`
+	// HTTPS 虚拟主机由 NetPanel 按 SNI 分流：*.SubDomainHost 终止 TLS 后转发到 HTTP 虚拟主机端口，其余透传给 frps（需同时配置 VhostHTTPPort 和 SubDomainHost）
+	VhostHTTPSTerminate bool `gorm:"default:false" json:"vhost_https_terminate"`
+	// 终止 TLS 使用的泛域名证书 ID（关联 DomainCert，需覆盖 *.SubDomainHost）
+	VhostHTTPSCertID uint `gorm:"default:0" json:"vhost_https_cert_id"`
 	// tcpmux httpconnect 代理监听端口，0 表示不启用
 	TcpmuxHTTPConnectPort int  `gorm:"default:0" json:"tcpmux_http_connect_port"`
 	// tcpmux 是否透传 CONNECT 请求
@@ -242,6 +290,8 @@ type FrpsConfig struct {
 	Custom404Page string `gorm:"size:500" json:"custom_404_page"`
 	// 认证 Token
 	Token string `gorm:"size:255" json:"token"`
+	// 多用户鉴权：启用后客户端需以 user + metadatas.token 登录，并受 FrpsUser 中的端口/域名/配额限制
+	EnableUserAuth bool `gorm:"default:false" json:"enable_user_auth"`
 	// Dashboard（WebServer）配置
 	DashboardAddr     string `gorm:"size:100" json:"dashboard_addr"`
 	DashboardPort     int    `json:"dashboard_port"`
@@ -272,6 +322,7 @@ type FrpsConfig struct {
 	TransportTLSCertFile       string `gorm:"size:500" json:"transport_tls_cert_file"`       // TLS 证书文件
 	TransportTLSKeyFile        string `gorm:"size:500" json:"transport_tls_key_file"`        // TLS 私钥文件
 	TransportTLSTrustedCAFile  string `gorm:"size:500" json:"transport_tls_trusted_ca_file"` // 受信任 CA 文件（双向 TLS）
+	TransportTLSCertID         uint   `gorm:"default:0" json:"transport_tls_cert_id"`        // 关联域名证书 ID，非 0 时覆盖证书/私钥文件，续期后自动重启
 	// SSH 隧道网关配置
 	SSHTunnelGatewayBindPort        int    `gorm:"default:0" json:"ssh_tunnel_gateway_bind_port"`          // SSH 服务器监听端口，0 表示不启用
 	SSHTunnelGatewayPrivateKeyFile  string `gorm:"size:500" json:"ssh_tunnel_gateway_private_key_file"`    // SSH 私钥文件，留空自动生成
@@ -282,6 +333,27 @@ type FrpsConfig struct {
 	Remark    string `gorm:"size:500" json:"remark"`
 }
 
+// FrpsUser FRP 服务端用户（多用户鉴权，通过 frps 服务端插件校验）
+type FrpsUser struct {
+	BaseModel
+	FrpsID   uint   `gorm:"not null;index" json:"frps_id"`
+	Username string `gorm:"size:100;not null" json:"username"` // 对应 frpc 的 user
+	Token    string `gorm:"size:255" json:"token"`             // 对应 frpc 的 metadatas.token
+	// 允许使用的远程端口，如 "6000-6100,7000"，留空表示不限制
+	AllowPorts string `gorm:"size:500" json:"allow_ports"`
+	// 允许使用的子域名，逗号分隔，* 表示不限制，留空表示禁止使用子域名
+	AllowSubdomains string `gorm:"size:500" json:"allow_subdomains"`
+	// 允许使用的自定义域名后缀，逗号分隔，如 "example.com" 同时允许 example.com 与 *.example.com；* 表示不限制
+	AllowDomains string `gorm:"size:500" json:"allow_domains"`
+	// 最大代理数，0 表示不限制
+	MaxProxies int `gorm:"default:0" json:"max_proxies"`
+	// 单个代理带宽限制（服务端限速），如 "1MB"，留空表示不限制
+	BandwidthLimit string     `gorm:"size:50" json:"bandwidth_limit"`
+	ExpireAt       *time.Time `json:"expire_at"` // 过期时间，空表示永不过期
+	Enable         bool       `gorm:"default:true" json:"enable"`
+	Remark         string     `gorm:"size:500" json:"remark"`
+}
+
 // ===== NPS 服务端 =====
 
 // NpsServerConfig NPS 服务端配置
@@ -297,6 +369,7 @@ type NpsServerConfig struct {
 	WebUsername       string `gorm:"size:100;default:'admin'" json:"web_username"`
 	WebPassword       string `gorm:"size:255;default:'123456'" json:"web_password"`
 	AuthKey           string `gorm:"size:255" json:"auth_key"`          // 连接认证密钥
+	APIKey            string `gorm:"size:64" json:"-"`                  // Web API 签名密钥，NetPanel 管理客户端/隧道/域名时使用，首次启动自动生成
 	LogLevel          string `gorm:"size:20;default:'info'" json:"log_level"`
 	Status            string `gorm:"size:20;default:'stopped'" json:"status"`
 	LastError         string `gorm:"type:text" json:"last_error"`
@@ -326,13 +399,13 @@ type NpsTunnel struct {
 	BaseModel
 	NpsClientID  uint   `gorm:"not null;index" json:"nps_client_id"`
 	Name         string `gorm:"size:100;not null" json:"name"`
-	Type         string `gorm:"size:20;not null" json:"type"` // tcp/udp/http/https/socks5/p2p
+	Type         string `gorm:"size:20;not null" json:"type"` // tcp/udp/http/https/socks5/secret/p2p
 	LocalIP      string `gorm:"size:100;default:'127.0.0.1'" json:"local_ip"`
 	LocalPort    int    `json:"local_port"`
 	RemotePort   int    `json:"remote_port"`
-	// HTTP/HTTPS 专用
+	// HTTP/HTTPS 专用：绑定的域名
 	HostHeader string `gorm:"size:255" json:"host_header"`
-	// 认证
+	// secret/p2p 隧道的唯一密钥
 	Password string `gorm:"size:255" json:"password"`
 	Enable   bool   `gorm:"default:true" json:"enable"`
 	Remark   string `gorm:"size:500" json:"remark"`
@@ -365,7 +438,7 @@ type EasytierClient struct {
 	ExternalNodes string `gorm:"size:500" json:"external_nodes"`
 
 	// ===== RPC 设置 =====
-	RpcPortal          string `gorm:"size:100" json:"rpc_portal"`           // --rpc-portal：RPC 管理门户地址，如 0 或 12345 或 0.0.0.0:12345
+	RpcPortal          string `gorm:"size:100" json:"rpc_portal"`           // --rpc-portal：RPC 管理门户地址，如 12345 或 0.0.0.0:12345，留空或 0 时自动分配本机端口
 	RpcPortalWhitelist string `gorm:"size:500" json:"rpc_portal_whitelist"` // --rpc-portal-whitelist：RPC 门户白名单
 
 	// ===== 网络行为选项 =====
@@ -449,11 +522,22 @@ type EasytierClient struct {
 	MultiThreadCount int  `gorm:"default:0" json:"multi_thread_count"`     // --multi-thread-count：线程数（0使用默认2，需>2）
 
 	ExtraArgs string `gorm:"type:text" json:"extra_args"` // 额外命令行参数（兜底）
+	// ExtraConfig 自定义 config.toml 片段，启动时合并到生成的配置中（用于表单未提供的配置项）
+	ExtraConfig string `gorm:"type:text" json:"extra_config"`
 	Status    string `gorm:"size:20;default:'stopped'" json:"status"`
 	LastError string `gorm:"type:text" json:"last_error"`
 	Remark    string `gorm:"size:500" json:"remark"`
 }
 
+// EasytierVpnDevice EasyTier 客户端 VPN 门户的 WireGuard 设备
+type EasytierVpnDevice struct {
+	BaseModel
+	ClientID uint   `gorm:"uniqueIndex:idx_vpn_device_address" json:"client_id"`
+	Name     string `gorm:"size:100;not null" json:"name"`
+	Address  string `gorm:"size:50;uniqueIndex:idx_vpn_device_address" json:"address"` // 设备在 VPN 客户端网段中的地址，如 10.14.14.2/32
+	Remark   string `gorm:"size:500" json:"remark"`
+}
+
 // ===== EasyTier 服务端 =====
 
 // EasytierServer EasyTier 服务端配置
@@ -480,7 +564,7 @@ type EasytierServer struct {
 	InstanceName    string `gorm:"size:255" json:"instance_name"` // --instance-name：实例名称，同机多节点时区分
 
 	// ===== RPC 设置 =====
-	RpcPortal          string `gorm:"size:100" json:"rpc_portal"`           // --rpc-portal：RPC 管理门户地址
+	RpcPortal          string `gorm:"size:100" json:"rpc_portal"`           // --rpc-portal：RPC 管理门户地址，standalone 模式留空或 0 时自动分配本机端口
 	RpcPortalWhitelist string `gorm:"size:500" json:"rpc_portal_whitelist"` // --rpc-portal-whitelist：RPC 门户白名单
 
 	// ===== 网络行为选项 =====
@@ -538,11 +622,58 @@ type EasytierServer struct {
 	MultiThreadCount int  `gorm:"default:0" json:"multi_thread_count"`     // --multi-thread-count：线程数（0使用默认2，需>2）
 
 	ExtraArgs string `gorm:"type:text" json:"extra_args"`
+	// ExtraConfig 自定义 config.toml 片段，启动时合并到生成的配置中（仅 standalone 模式）
+	ExtraConfig string `gorm:"type:text" json:"extra_config"`
 	Status    string `gorm:"size:20;default:'stopped'" json:"status"`
 	LastError string `gorm:"type:text" json:"last_error"`
 	Remark    string `gorm:"size:500" json:"remark"`
 }
 
+// ===== EasyTier Web 配置服务器 =====
+
+// EasytierWebServer 由 NetPanel 托管的 EasyTier config-server（easytier-web）
+// 远端节点以 easytier-core --config-server <协议>://<面板地址>:<ConfigPort>/<Username> 接入，
+// 由面板审批后下发网络配置
+type EasytierWebServer struct {
+	BaseModel
+	Name           string `gorm:"size:100;not null" json:"name"`
+	Enable         bool   `gorm:"default:false" json:"enable"`
+	ConfigPort     int    `gorm:"default:22020" json:"config_port"`             // --config-server-port：节点接入端口
+	ConfigProtocol string `gorm:"size:10;default:'udp'" json:"config_protocol"` // --config-server-protocol：tcp/udp/ws
+	ApiPort        int    `gorm:"default:11211" json:"api_port"`                // --api-server-port：easytier-web 管理 API 端口，支持时只监听本机回环
+	Username       string `gorm:"size:100;default:'admin'" json:"username"`     // easytier-web 账号（需已存在，默认账号 admin/user 首次启动时改为下方密码），同时作为节点接入 token
+	Password       string `gorm:"size:255" json:"password"`                     // 不允许使用 easytier-web 默认密码
+	AutoApprove    bool   `gorm:"default:false" json:"auto_approve"` // 新接入的节点自动审批通过
+	Status         string `gorm:"size:20;default:'stopped'" json:"status"`
+	LastError      string `gorm:"type:text" json:"last_error"`
+	Remark         string `gorm:"size:500" json:"remark"`
+}
+
+// EasytierMachine 接入 config-server 的远端节点，按 MachineID 识别
+type EasytierMachine struct {
+	BaseModel
+	WebServerID uint       `gorm:"index" json:"web_server_id"`
+	MachineID   string     `gorm:"size:64;index" json:"machine_id"`
+	Hostname    string     `gorm:"size:255" json:"hostname"`
+	Version     string     `gorm:"size:50" json:"version"`
+	ClientURL   string     `gorm:"size:255" json:"client_url"` // 节点接入地址
+	Online      bool       `gorm:"default:false" json:"online"`
+	LastSeen    *time.Time `json:"last_seen"`
+	Approval    string     `gorm:"size:20;default:'pending'" json:"approval"` // pending/approved/revoked
+
+	// ===== 下发的网络配置（审批通过后生效） =====
+	NetworkName     string `gorm:"size:255" json:"network_name"`
+	NetworkPassword string `gorm:"size:255" json:"network_password"`
+	VirtualIP       string `gorm:"size:50" json:"virtual_ip"`  // 虚拟 IP（CIDR 格式，如 10.0.0.1/24），留空使用 DHCP
+	PeerURLs        string `gorm:"type:text" json:"peer_urls"` // 对等节点地址，逗号分隔
+	ListenerURLs    string `gorm:"type:text" json:"listener_urls"`
+	// ExtraConfig 自定义 NetworkConfig 字段（JSON 对象），合并到下发的配置中
+	ExtraConfig string `gorm:"type:text" json:"extra_config"`
+	InstanceID  string `gorm:"size:64" json:"instance_id"` // 已下发的网络实例 ID
+	LastError   string `gorm:"type:text" json:"last_error"`
+	Remark      string `gorm:"size:500" json:"remark"`
+}
+
 // ===== DDNS =====
 
 // DDNSTask DDNS 任务
@@ -550,19 +681,23 @@ type DDNSTask struct {
 	BaseModel
 	Name             string `gorm:"size:100;not null" json:"name"`
 	Enable           bool   `gorm:"default:false" json:"enable"`
-	TaskType         string `gorm:"size:10;default:'IPv4'" json:"task_type"` // IPv4/IPv6
+	TaskType         string `gorm:"size:10;default:'IPv4'" json:"task_type"` // IPv4/IPv6/dual（双栈）
 	Provider         string `gorm:"size:50;not null" json:"provider"`        // alidns/cloudflare/dnspod/...
 	DomainAccountID  uint   `json:"domain_account_id"`                       // 关联域名账号（可选）
 	AccessID         string `gorm:"size:255" json:"access_id"`
 	AccessSecret     string `gorm:"size:500" json:"access_secret"`
-	Domains          string `gorm:"type:text" json:"domains"`   // JSON 数组
-	IPGetType        string `gorm:"size:20;default:'url'" json:"ip_get_type"` // url/interface/custom
+	Domains          string `gorm:"type:text" json:"domains"`   // JSON 数组，元素为域名或 {"domain","type","suffix"} 对象
+	IPGetType        string `gorm:"size:20;default:'url'" json:"ip_get_type"` // url/interface/custom/command/upnp/ddns_task/stun_rule/vote
 	IPGetURLs        string `gorm:"type:text" json:"ip_get_urls"` // JSON 数组
 	NetInterface     string `gorm:"size:100" json:"net_interface"`
 	IPRegex          string `gorm:"size:255" json:"ip_regex"`
+	IPCommand        string `gorm:"type:text" json:"ip_command"` // command：执行的命令，从输出中提取 IP
+	IPSourceID       uint   `json:"ip_source_id"`                // ddns_task/stun_rule：引用的 DDNS 任务或 STUN 规则 ID
 	TTL              string `gorm:"size:20;default:'600'" json:"ttl"`
 	Interval         int    `gorm:"default:300" json:"interval"` // 检查间隔（秒）
 	CurrentIP        string `gorm:"size:100" json:"current_ip"`
+	CurrentIPv6      string `gorm:"size:100" json:"current_ipv6"` // 双栈任务的 IPv6 地址（CurrentIP 保存 IPv4）
+	IPv6PrefixLen    int    `gorm:"default:64" json:"ipv6_prefix_len"` // 与设备后缀组合时使用的前缀长度
 	LastUpdateTime   *time.Time `json:"last_update_time"`
 	// Webhook 通知（参考 lucky DDNSTask.Webhook）
 	WebhookEnable  bool   `gorm:"default:false" json:"webhook_enable"`
@@ -574,6 +709,8 @@ type DDNSTask struct {
 	ForceInterval int `gorm:"default:0" json:"force_interval"`
 	// HTTP 客户端超时（参考 lucky HttpClientTimeout），单位秒
 	HttpTimeout int    `gorm:"default:10" json:"http_timeout"`
+	// 查找权威服务器使用的 DNS 服务器（JSON 数组，如 ["223.5.5.5","1.1.1.1:53"]），服务商无法查询记录时向权威服务器核对线上解析
+	VerifyResolvers string `gorm:"type:text" json:"verify_resolvers"`
 	Status      string `gorm:"size:20;default:'stopped'" json:"status"`
 	LastError   string `gorm:"type:text" json:"last_error"`
 	Remark      string `gorm:"size:500" json:"remark"`
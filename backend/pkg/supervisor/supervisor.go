@@ -0,0 +1,415 @@
+// Package supervisor 提供子进程守护：崩溃后按指数退避自动重启、限制单位时间内的重启次数、
+// 捕获 stdout/stderr 最近 N 行输出（逐行写入调试日志，退出时汇总写入警告日志），并统计运行时长与重启次数。
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 进程状态
+const (
+	StateRunning = "running" // 运行中
+	StateBackoff = "backoff" // 已退出，等待重启
+	StateFailed  = "failed"  // 重启次数超限，已放弃
+	StateExited  = "exited"  // 正常退出且无需重启
+	StateStopped = "stopped" // 已被主动停止
+)
+
+// Config 守护配置
+type Config struct {
+	// Name 日志前缀，如 "[EasyTier客户端][1]"
+	Name string
+	Log  *logrus.Logger // 必填
+	// Command 每次（重新）启动时调用，返回待启动的命令，便于重启时读取最新配置
+	Command func() (*exec.Cmd, error)
+	// OnExit 进程退出后调用，返回是否需要重启；为空时仅在异常退出（err != nil）时重启
+	// output 为最近捕获的 stdout/stderr 输出
+	OnExit func(err error, output string) bool
+	// OnStateChange 状态变化时调用（如同步数据库中的 status/last_error）
+	OnStateChange func(st Status)
+	// Stdout/Stderr 同时镜像输出的目标（如控制台），可为空
+	Stdout io.Writer
+	Stderr io.Writer
+
+	TailLines      int           // 保留的最近输出行数，默认 100
+	InitialBackoff time.Duration // 首次重启延迟，默认 1 秒
+	MaxBackoff     time.Duration // 最大重启延迟，默认 60 秒
+	StableAfter    time.Duration // 运行超过该时长视为稳定，重置退避，默认 60 秒
+	MaxRestarts    int           // RestartWindow 内最多重启次数，默认 5
+	RestartWindow  time.Duration // 重启次数统计窗口，默认 10 分钟
+	StopTimeout    time.Duration // 停止时等待进程优雅退出的时间，超时强制结束，默认 5 秒
+}
+
+// Status 进程运行状态
+type Status struct {
+	State      string     `json:"state"`
+	PID        int        `json:"pid"`
+	StartedAt  *time.Time `json:"started_at"`
+	Uptime     int64      `json:"uptime"` // 本次运行时长（秒）
+	Restarts   int        `json:"restarts"`
+	LastExit   string     `json:"last_exit"`
+	LastExitAt *time.Time `json:"last_exit_at"`
+	Output     []string   `json:"output"` // 最近输出
+}
+
+// Process 受守护的进程
+type Process struct {
+	cfg    Config
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	tail   *tailBuffer
+
+	mu         sync.Mutex
+	cmd        *exec.Cmd
+	state      string
+	startedAt  time.Time
+	restarts   int
+	restartAt  []time.Time
+	lastExit   string
+	lastExitAt time.Time
+}
+
+// Start 启动进程并开始守护；首次启动失败时直接返回错误
+func Start(cfg Config) (*Process, error) {
+	if cfg.Log == nil || cfg.Command == nil {
+		return nil, fmt.Errorf("%s 守护配置缺少 Log 或 Command", cfg.Name)
+	}
+	if cfg.TailLines <= 0 {
+		cfg.TailLines = 100
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 60 * time.Second
+	}
+	if cfg.StableAfter <= 0 {
+		cfg.StableAfter = 60 * time.Second
+	}
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = 5
+	}
+	if cfg.RestartWindow <= 0 {
+		cfg.RestartWindow = 10 * time.Minute
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Process{
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	p.tail = newTailBuffer(cfg.TailLines, func(line string) {
+		cfg.Log.Debugf("%s %s", cfg.Name, line)
+	})
+	if err := p.spawn(); err != nil {
+		cancel()
+		close(p.done)
+		return nil, err
+	}
+	go p.supervise()
+	return p, nil
+}
+
+// spawn 创建并启动一次子进程
+func (p *Process) spawn() error {
+	cmd, err := p.cfg.Command()
+	if err != nil {
+		return err
+	}
+	// 每次启动清空上次运行的输出，避免与本次输出混在一起
+	p.tail.reset()
+	cmd.Stdout = p.tail.writer(p.cfg.Stdout)
+	cmd.Stderr = p.tail.writer(p.cfg.Stderr)
+	// 子进程派生的后代进程可能继承输出管道，进程退出后最多再等待 WaitDelay，避免 Wait 一直阻塞
+	if cmd.WaitDelay <= 0 {
+		cmd.WaitDelay = p.cfg.StopTimeout
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动进程失败: %w", err)
+	}
+	p.mu.Lock()
+	p.cmd = cmd
+	p.state = StateRunning
+	p.startedAt = time.Now()
+	p.mu.Unlock()
+	p.notify()
+	return nil
+}
+
+func (p *Process) supervise() {
+	defer close(p.done)
+	backoff := p.cfg.InitialBackoff
+	for {
+		p.mu.Lock()
+		cmd := p.cmd
+		p.mu.Unlock()
+
+		err := cmd.Wait()
+		output := p.tail.String()
+		lastExit := "exit status 0"
+		if err != nil {
+			lastExit = err.Error()
+		}
+
+		p.mu.Lock()
+		ranFor := time.Since(p.startedAt)
+		p.lastExitAt = time.Now()
+		p.lastExit = lastExit
+		p.mu.Unlock()
+
+		if p.ctx.Err() != nil {
+			p.setState(StateStopped)
+			return
+		}
+
+		p.cfg.Log.Warnf("%s 进程退出: %s，运行 %s", p.cfg.Name, lastExit, ranFor.Round(time.Second))
+		if output != "" {
+			p.cfg.Log.Warnf("%s 最近输出:\n%s", p.cfg.Name, output)
+		}
+
+		restart := err != nil
+		if p.cfg.OnExit != nil {
+			restart = p.cfg.OnExit(err, output)
+		}
+		if !restart || p.ctx.Err() != nil {
+			p.setState(StateExited)
+			return
+		}
+
+		// 运行足够久视为稳定，退避重新计算
+		if ranFor >= p.cfg.StableAfter {
+			backoff = p.cfg.InitialBackoff
+		}
+		if !p.allowRestart() {
+			p.cfg.Log.Errorf("%s %s 内已重启 %d 次，停止自动重启", p.cfg.Name, p.cfg.RestartWindow, p.cfg.MaxRestarts)
+			p.setState(StateFailed)
+			return
+		}
+
+		for {
+			p.setState(StateBackoff)
+			p.cfg.Log.Infof("%s %s 后自动重启", p.cfg.Name, backoff)
+			select {
+			case <-p.ctx.Done():
+				p.setState(StateStopped)
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, p.cfg.MaxBackoff)
+
+			p.mu.Lock()
+			p.restarts++
+			p.mu.Unlock()
+			if err := p.spawn(); err != nil {
+				p.mu.Lock()
+				p.lastExit = err.Error()
+				p.lastExitAt = time.Now()
+				p.mu.Unlock()
+				p.cfg.Log.Errorf("%s 自动重启失败: %v", p.cfg.Name, err)
+				if !p.allowRestart() {
+					p.setState(StateFailed)
+					return
+				}
+				continue
+			}
+			p.cfg.Log.Infof("%s 已自动重启，PID: %d", p.cfg.Name, p.PID())
+			break
+		}
+	}
+}
+
+// allowRestart 记录一次重启并判断是否超过窗口内的次数上限
+func (p *Process) allowRestart() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	kept := p.restartAt[:0]
+	for _, t := range p.restartAt {
+		if now.Sub(t) < p.cfg.RestartWindow {
+			kept = append(kept, t)
+		}
+	}
+	p.restartAt = kept
+	if len(p.restartAt) >= p.cfg.MaxRestarts {
+		return false
+	}
+	p.restartAt = append(p.restartAt, now)
+	return true
+}
+
+func (p *Process) setState(state string) {
+	p.mu.Lock()
+	p.state = state
+	p.mu.Unlock()
+	p.notify()
+}
+
+func (p *Process) notify() {
+	if p.cfg.OnStateChange != nil {
+		p.cfg.OnStateChange(p.Status())
+	}
+}
+
+// Stop 停止守护并结束进程：先尝试 SIGTERM，超时后强制结束；阻塞直到进程退出
+func (p *Process) Stop() {
+	p.cancel()
+	p.mu.Lock()
+	cmd := p.cmd
+	p.mu.Unlock()
+	if cmd != nil && cmd.Process != nil {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			_ = cmd.Process.Kill()
+		}
+	}
+	select {
+	case <-p.done:
+		return
+	case <-time.After(p.cfg.StopTimeout):
+	}
+	p.mu.Lock()
+	cmd = p.cmd
+	p.mu.Unlock()
+	if cmd != nil && cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+	<-p.done
+}
+
+// Done 守护结束（被停止、放弃重启或正常退出）时关闭
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Alive 进程是否处于运行中或等待重启
+func (p *Process) Alive() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state == StateRunning || p.state == StateBackoff
+}
+
+// PID 当前进程 PID
+func (p *Process) PID() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+// Status 获取运行状态
+func (p *Process) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := Status{
+		State:    p.state,
+		Restarts: p.restarts,
+		LastExit: p.lastExit,
+		Output:   p.tail.Lines(),
+	}
+	if p.state == StateRunning {
+		startedAt := p.startedAt
+		st.StartedAt = &startedAt
+		st.Uptime = int64(time.Since(startedAt).Seconds())
+		if p.cmd != nil && p.cmd.Process != nil {
+			st.PID = p.cmd.Process.Pid
+		}
+	}
+	if !p.lastExitAt.IsZero() {
+		lastExitAt := p.lastExitAt
+		st.LastExitAt = &lastExitAt
+	}
+	return st
+}
+
+// ===== 输出捕获 =====
+
+// tailBuffer 按行保存最近 N 行输出（stdout/stderr 共用）
+type tailBuffer struct {
+	mu     sync.Mutex
+	max    int
+	lines  []string
+	onLine func(line string) // 每捕获一行时调用（写入调试日志）
+}
+
+func newTailBuffer(max int, onLine func(line string)) *tailBuffer {
+	return &tailBuffer{max: max, onLine: onLine}
+}
+
+func (t *tailBuffer) add(line string) {
+	t.mu.Lock()
+	t.lines = append(t.lines, line)
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+	t.mu.Unlock()
+	if t.onLine != nil {
+		t.onLine(line)
+	}
+}
+
+// reset 清空已保存的输出
+func (t *tailBuffer) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = nil
+}
+
+// Lines 返回最近输出的副本
+func (t *tailBuffer) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string{}, t.lines...)
+}
+
+func (t *tailBuffer) String() string {
+	return strings.Join(t.Lines(), "\n")
+}
+
+// writer 返回按行写入 tailBuffer 的 io.Writer，mirror 非空时同时镜像输出
+func (t *tailBuffer) writer(mirror io.Writer) io.Writer {
+	return &lineWriter{tail: t, mirror: mirror}
+}
+
+type lineWriter struct {
+	tail   *tailBuffer
+	mirror io.Writer
+	buf    bytes.Buffer
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	if w.mirror != nil {
+		_, _ = w.mirror.Write(b)
+	}
+	w.buf.Write(b)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// 不完整的行放回缓冲区，过长时直接截断保存
+			if len(line) > 4096 {
+				w.tail.add(line)
+			} else {
+				w.buf.WriteString(line)
+			}
+			break
+		}
+		w.tail.add(strings.TrimRight(line, "\r\n"))
+	}
+	return len(b), nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/service/dnsprovider"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -184,6 +185,7 @@ func (m *Manager) executeWebhook(account *model.CallbackAccount, task *model.Cal
 
 // executeCFOrigin 更新 Cloudflare 回源端口
 // 配置字段：api_token, zone_id, rule_id, origin_port（可选，默认使用 event.NewPort）
+// zone_id 为空时可配置 domain，按域名自动查找所属区域
 func (m *Manager) executeCFOrigin(account *model.CallbackAccount, task *model.CallbackTask, event *TriggerEvent) error {
 	var cfg map[string]string
 	if err := json.Unmarshal([]byte(account.Config), &cfg); err != nil {
@@ -193,8 +195,15 @@ func (m *Manager) executeCFOrigin(account *model.CallbackAccount, task *model.Ca
 	apiToken := cfg["api_token"]
 	zoneID := cfg["zone_id"]
 	ruleID := cfg["rule_id"]
+	if zoneID == "" && apiToken != "" && cfg["domain"] != "" {
+		id, err := cfZoneID(apiToken, cfg["domain"])
+		if err != nil {
+			return err
+		}
+		zoneID = id
+	}
 	if apiToken == "" || zoneID == "" || ruleID == "" {
-		return fmt.Errorf("CF 回源配置不完整（需要 api_token、zone_id、rule_id）")
+		return fmt.Errorf("CF 回源配置不完整（需要 api_token、zone_id 或 domain、rule_id）")
 	}
 
 	// 目标端口：优先使用配置中的固定端口，否则使用事件中的新端口
@@ -259,6 +268,32 @@ func (m *Manager) executeCFOrigin(account *model.CallbackAccount, task *model.Ca
 	return nil
 }
 
+// cfZoneID 在 Cloudflare 账号托管的区域中选取与域名最长匹配的区域，返回区域 ID
+func cfZoneID(apiToken, domain string) (string, error) {
+	provider, err := dnsprovider.New(model.DomainAccount{Provider: "cloudflare", AccessSecret: apiToken})
+	if err != nil {
+		return "", err
+	}
+	zones, err := provider.ListDomains()
+	if err != nil {
+		return "", fmt.Errorf("查询 CF 区域失败: %w", err)
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		names = append(names, z.Name)
+	}
+	zone, ok := dnsprovider.FindZone(domain, names)
+	if !ok {
+		return "", fmt.Errorf("未在 CF 账号中找到域名 %s 所属的区域", domain)
+	}
+	for _, z := range zones {
+		if strings.EqualFold(z.Name, zone) {
+			return z.ID, nil
+		}
+	}
+	return "", fmt.Errorf("未在 CF 账号中找到域名 %s 所属的区域", domain)
+}
+
 // executeAliESA 更新阿里云 ESA（边缘安全加速）回源端口
 // 配置字段：access_key_id, access_key_secret, site_id, rule_id
 func (m *Manager) executeAliESA(account *model.CallbackAccount, task *model.CallbackTask, event *TriggerEvent) error {
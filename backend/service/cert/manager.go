@@ -21,11 +21,9 @@ import (
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/challenge/http01"
 	"github.com/go-acme/lego/v4/lego"
-	"github.com/go-acme/lego/v4/providers/dns/alidns"
-	"github.com/go-acme/lego/v4/providers/dns/cloudflare"
-	"github.com/go-acme/lego/v4/providers/dns/dnspod"
 	"github.com/go-acme/lego/v4/registration"
 	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/service/dnsprovider"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -41,18 +39,29 @@ func (u *acmeUser) GetEmail() string                        { return u.Email }
 func (u *acmeUser) GetRegistration() *registration.Resource { return u.Registration }
 func (u *acmeUser) GetPrivateKey() crypto.PrivateKey        { return u.key }
 
+// UpdateNotifier 证书签发/续期成功后的通知接口（由引用证书的模块实现并注入，避免循环依赖）
+type UpdateNotifier interface {
+	OnCertUpdated(certID uint)
+}
+
 // Manager 域名证书管理器
 type Manager struct {
-	db      *gorm.DB
-	log     *logrus.Logger
-	dataDir string
-	mu      sync.Mutex
+	db       *gorm.DB
+	log      *logrus.Logger
+	dataDir  string
+	mu       sync.Mutex
+	notifier UpdateNotifier
 }
 
 func NewManager(db *gorm.DB, log *logrus.Logger, dataDir string) *Manager {
 	return &Manager{db: db, log: log, dataDir: dataDir}
 }
 
+// SetUpdateNotifier 注入证书更新通知器
+func (m *Manager) SetUpdateNotifier(n UpdateNotifier) {
+	m.notifier = n
+}
+
 // StartAll 启动自动续期检查
 func (m *Manager) StartAll() {
 	go m.autoRenewLoop()
@@ -169,12 +178,12 @@ func (m *Manager) Apply(id uint) error {
 	// 配置 DNS 验证
 	if cert.ChallengeType == "dns" || cert.ChallengeType == "" {
 		// 获取 DNS 账号信息
-		accessID, accessSecret, provider, err := m.getDNSCredentials(&cert)
+		account, err := m.getDNSAccount(&cert)
 		if err != nil {
 			return m.setError(id, err)
 		}
 
-		dnsProvider, err := m.createDNSProvider(provider, accessID, accessSecret)
+		dnsProvider, err := newDNSChallengeProvider(account)
 		if err != nil {
 			return m.setError(id, err)
 		}
@@ -260,6 +269,10 @@ func (m *Manager) Apply(id uint) error {
 	m.db.Model(&model.DomainCert{}).Where("id = ?", id).Updates(updates)
 
 	m.log.Infof("[证书][%s] 证书申请成功，到期时间: %v", cert.Name, expireAt)
+	if m.notifier != nil {
+		// 异步通知，避免持有 m.mu 时等待引用方重启
+		go m.notifier.OnCertUpdated(id)
+	}
 	return nil
 }
 
@@ -293,55 +306,41 @@ func (m *Manager) setError(id uint, err error) error {
 	return err
 }
 
-// getDNSCredentials 获取 DNS 验证凭据
-func (m *Manager) getDNSCredentials(cert *model.DomainCert) (accessID, accessSecret, provider string, err error) {
+// getDNSAccount 获取 DNS 验证使用的域名账号
+func (m *Manager) getDNSAccount(cert *model.DomainCert) (model.DomainAccount, error) {
+	var account model.DomainAccount
 	// 优先使用关联的域名账号
 	if cert.DomainAccountID > 0 {
-		var account model.DomainAccount
 		if dbErr := m.db.First(&account, cert.DomainAccountID).Error; dbErr == nil {
-			return account.AccessID, account.AccessSecret, account.Provider, nil
+			return account, nil
 		}
 	}
-	return "", "", "", fmt.Errorf("未配置 DNS 账号，请关联域名账号")
+	return account, fmt.Errorf("未配置 DNS 账号，请关联域名账号")
 }
 
-// dnsProvider DNS 验证 provider 接口
-type dnsProvider interface {
-	Present(domain, token, keyAuth string) error
-	CleanUp(domain, token, keyAuth string) error
+// dnsChallengeProvider 基于 dnsprovider 的 lego DNS-01 provider
+// 凭据保存在实例中，并发签发证书时互不影响
+type dnsChallengeProvider struct {
+	p dnsprovider.Provider
 }
 
-// createDNSProvider 根据服务商名称创建 lego DNS provider
-func (m *Manager) createDNSProvider(providerName, accessID, accessSecret string) (dnsProvider, error) {
-	switch strings.ToLower(providerName) {
-	case "alidns", "aliyun":
-		os.Setenv("ALICLOUD_ACCESS_KEY", accessID)
-		os.Setenv("ALICLOUD_SECRET_KEY", accessSecret)
-		p, err := alidns.NewDNSProvider()
-		if err != nil {
-			return nil, fmt.Errorf("创建阿里云 DNS provider 失败: %w", err)
-		}
-		return p, nil
-
-	case "cloudflare", "cf":
-		os.Setenv("CF_DNS_API_TOKEN", accessSecret)
-		p, err := cloudflare.NewDNSProvider()
-		if err != nil {
-			return nil, fmt.Errorf("创建 Cloudflare DNS provider 失败: %w", err)
-		}
-		return p, nil
+// newDNSChallengeProvider 根据域名账号创建 DNS-01 验证 provider
+func newDNSChallengeProvider(account model.DomainAccount) (*dnsChallengeProvider, error) {
+	p, err := dnsprovider.New(account)
+	if err != nil {
+		return nil, err
+	}
+	return &dnsChallengeProvider{p: p}, nil
+}
 
-	case "dnspod":
-		os.Setenv("DNSPOD_API_KEY", accessID+","+accessSecret)
-		p, err := dnspod.NewDNSProvider()
-		if err != nil {
-			return nil, fmt.Errorf("创建 DNSPod DNS provider 失败: %w", err)
-		}
-		return p, nil
+func (d *dnsChallengeProvider) Present(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+	return d.p.PresentTXT(dns01.UnFqdn(info.EffectiveFQDN), info.Value)
+}
 
-	default:
-		return nil, fmt.Errorf("不支持的 DNS 服务商: %s（支持: alidns/cloudflare/dnspod）", providerName)
-	}
+func (d *dnsChallengeProvider) CleanUp(domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(domain, keyAuth)
+	return d.p.CleanupTXT(dns01.UnFqdn(info.EffectiveFQDN), info.Value)
 }
 
 // parseCertExpiry 从 PEM 证书中解析到期时间
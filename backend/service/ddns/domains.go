@@ -0,0 +1,192 @@
+package ddns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// domainEntry 域名配置项
+// DDNSTask.Domains 为 JSON 数组，元素可以是域名字符串，也可以是对象：
+//
+//	{"domain": "nas.example.com", "type": "AAAA", "suffix": "::1234"}
+//	{"domain": "pc.example.com", "suffix": "00:11:22:33:44:55"}
+type domainEntry struct {
+	Domain string `json:"domain"`
+	Type   string `json:"type"`   // A/AAAA，留空时按任务类型（双栈任务同时更新 A 与 AAAA）
+	Suffix string `json:"suffix"` // 局域网设备的 IPv6 后缀：静态接口标识（如 ::1234）或 MAC 地址（按 EUI-64 生成）
+}
+
+// parseDomains 解析任务的域名列表，并按记录类型展开（每个元素对应一条记录）
+// 记录类型与任务类型不符（如 IPv4 任务中的 AAAA 或设备后缀）时返回错误，避免该域名被静默跳过
+func parseDomains(task *model.DDNSTask) ([]domainEntry, error) {
+	if strings.TrimSpace(task.Domains) == "" {
+		return nil, nil
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal([]byte(task.Domains), &raws); err != nil {
+		// 兼容单域名字符串
+		raws = []json.RawMessage{mustMarshal(task.Domains)}
+	}
+
+	families := taskFamilies(task)
+	var entries []domainEntry
+	for _, raw := range raws {
+		var e domainEntry
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			e.Domain = name
+		} else if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, fmt.Errorf("域名配置格式错误: %s", string(raw))
+		}
+		e.Domain = strings.TrimSpace(e.Domain)
+		if e.Domain == "" {
+			continue
+		}
+		e.Type = strings.ToUpper(strings.TrimSpace(e.Type))
+		if e.Suffix != "" {
+			// 设备后缀仅用于 IPv6
+			if e.Type != "" && e.Type != "AAAA" {
+				return nil, fmt.Errorf("域名 %s 配置了设备后缀，记录类型只能为 AAAA", e.Domain)
+			}
+			if _, err := parseInterfaceID(e.Suffix); err != nil {
+				return nil, fmt.Errorf("域名 %s: %w", e.Domain, err)
+			}
+			e.Type = "AAAA"
+		}
+		switch e.Type {
+		case "A", "AAAA":
+			if !containsString(families, familyOf(e.Type)) {
+				return nil, fmt.Errorf("域名 %s 的记录类型 %s 与任务类型 %s 不符，同时更新 A 与 AAAA 请使用双栈（dual）任务", e.Domain, e.Type, task.TaskType)
+			}
+			entries = append(entries, e)
+		case "":
+			for _, family := range families {
+				e.Type = recordTypeOf(family)
+				entries = append(entries, e)
+			}
+		default:
+			return nil, fmt.Errorf("域名 %s 的记录类型 %s 无效（仅支持 A/AAAA）", e.Domain, e.Type)
+		}
+	}
+	return entries, nil
+}
+
+// ValidateDomains 校验任务的域名列表，保存任务前调用
+func ValidateDomains(task *model.DDNSTask) error {
+	_, err := parseDomains(task)
+	return err
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func mustMarshal(s string) json.RawMessage {
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// taskFamilies 任务需要处理的地址族：IPv4、IPv6 或双栈（dual）
+func taskFamilies(task *model.DDNSTask) []string {
+	switch task.TaskType {
+	case "IPv6":
+		return []string{"IPv6"}
+	case "dual":
+		return []string{"IPv4", "IPv6"}
+	default:
+		return []string{"IPv4"}
+	}
+}
+
+// recordTypeOf 地址族对应的记录类型
+func recordTypeOf(family string) string {
+	if family == "IPv6" {
+		return "AAAA"
+	}
+	return "A"
+}
+
+// familyOf 记录类型对应的地址族
+func familyOf(recordType string) string {
+	if recordType == "AAAA" {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+// currentIPColumn 缓存该地址族 IP 的字段：双栈任务的 IPv6 地址保存在 current_ipv6，其余保存在 current_ip
+func currentIPColumn(task *model.DDNSTask, family string) string {
+	if task.TaskType == "dual" && family == "IPv6" {
+		return "current_ipv6"
+	}
+	return "current_ip"
+}
+
+// pickCustomIP 从逗号分隔的自定义 IP 中选出对应地址族的地址
+func pickCustomIP(raw, family string) string {
+	for _, s := range strings.Split(raw, ",") {
+		s = strings.TrimSpace(s)
+		ip := net.ParseIP(s)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() != nil) == (family == "IPv4") {
+			return s
+		}
+	}
+	return ""
+}
+
+// composeIPv6 取 ip 的前 prefixLen 位作为前缀，与设备后缀组合出完整 IPv6 地址
+// suffix 可以是接口标识（如 ::1234、::211:22ff:fe33:4455）或 MAC 地址（按 EUI-64 转换）
+func composeIPv6(ip string, prefixLen int, suffix string) (string, error) {
+	prefix := net.ParseIP(ip)
+	if prefix == nil || prefix.To4() != nil {
+		return "", fmt.Errorf("无效的 IPv6 前缀来源地址: %s", ip)
+	}
+	if prefixLen <= 0 {
+		prefixLen = 64
+	}
+	if prefixLen > 128 {
+		return "", fmt.Errorf("无效的 IPv6 前缀长度: %d", prefixLen)
+	}
+
+	iid, err := parseInterfaceID(suffix)
+	if err != nil {
+		return "", err
+	}
+
+	mask := net.CIDRMask(prefixLen, 128)
+	out := make(net.IP, net.IPv6len)
+	for i := range out {
+		out[i] = prefix[i]&mask[i] | iid[i]&^mask[i]
+	}
+	return out.String(), nil
+}
+
+// parseInterfaceID 解析设备后缀为 128 位地址形式的接口标识
+func parseInterfaceID(suffix string) (net.IP, error) {
+	suffix = strings.TrimSpace(suffix)
+	if mac, err := net.ParseMAC(suffix); err == nil && len(mac) == 6 {
+		// EUI-64：MAC 中间插入 fffe，并翻转 U/L 位
+		iid := make(net.IP, net.IPv6len)
+		iid[8] = mac[0] ^ 0x02
+		iid[9], iid[10] = mac[1], mac[2]
+		iid[11], iid[12] = 0xff, 0xfe
+		iid[13], iid[14], iid[15] = mac[3], mac[4], mac[5]
+		return iid, nil
+	}
+	if iid := net.ParseIP(suffix); iid != nil && iid.To4() == nil {
+		return iid, nil
+	}
+	return nil, fmt.Errorf("无效的 IPv6 设备后缀: %s（应为 ::1234 形式的接口标识或 MAC 地址）", suffix)
+}
@@ -0,0 +1,64 @@
+package ddns
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseInterfaceID(t *testing.T) {
+	tests := []struct {
+		suffix string
+		want   string
+		ok     bool
+	}{
+		{"::1234", "::1234", true},
+		{" ::abcd:1 ", "::abcd:1", true},
+		// EUI-64：插入 fffe 并翻转 U/L 位
+		{"00:11:22:33:44:55", "::211:22ff:fe33:4455", true},
+		{"02-11-22-33-44-55", "::11:22ff:fe33:4455", true},
+		{"1.2.3.4", "", false},
+		{"not-an-id", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		iid, err := parseInterfaceID(tt.suffix)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseInterfaceID(%q) err = %v, want ok=%v", tt.suffix, err, tt.ok)
+			continue
+		}
+		if tt.ok && !iid.Equal(net.ParseIP(tt.want)) {
+			t.Errorf("parseInterfaceID(%q) = %s, want %s", tt.suffix, iid, tt.want)
+		}
+	}
+}
+
+func TestComposeIPv6(t *testing.T) {
+	tests := []struct {
+		ip        string
+		prefixLen int
+		suffix    string
+		want      string
+		ok        bool
+	}{
+		{"2001:db8:1:2:aaaa:bbbb:cccc:dddd", 64, "::1234", "2001:db8:1:2::1234", true},
+		// 前缀长度为 0 时按 /64 处理
+		{"2001:db8:1:2::1", 0, "::5", "2001:db8:1:2::5", true},
+		{"2001:db8:1:2::1", 56, "::ab:0:0:0:1", "2001:db8:1:ab::1", true},
+		{"2001:db8:1:2::1", 64, "00:11:22:33:44:55", "2001:db8:1:2:211:22ff:fe33:4455", true},
+		{"2001:db8::1", 128, "::5", "2001:db8::1", true},
+		{"192.168.1.1", 64, "::1", "", false},
+		{"bogus", 64, "::1", "", false},
+		{"2001:db8::1", 129, "::1", "", false},
+		{"2001:db8::1", 64, "bogus", "", false},
+	}
+	for _, tt := range tests {
+		got, err := composeIPv6(tt.ip, tt.prefixLen, tt.suffix)
+		if (err == nil) != tt.ok {
+			t.Errorf("composeIPv6(%q, %d, %q) err = %v, want ok=%v", tt.ip, tt.prefixLen, tt.suffix, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf("composeIPv6(%q, %d, %q) = %s, want %s", tt.ip, tt.prefixLen, tt.suffix, got, tt.want)
+		}
+	}
+}
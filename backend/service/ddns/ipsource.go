@@ -0,0 +1,168 @@
+package ddns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/service/stun"
+)
+
+// getIPFromCommand 执行 IPCommand 并从输出中提取 IP（可配合光猫/路由器的命令行查询）
+func getIPFromCommand(task *model.DDNSTask, ipType string) (string, error) {
+	if strings.TrimSpace(task.IPCommand) == "" {
+		return "", fmt.Errorf("获取 IP 的命令为空")
+	}
+	re, err := ipRegexFor(task, ipType)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout(task))
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", task.IPCommand)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", task.IPCommand)
+	}
+	// 超时只会杀掉 shell，管道或 ssh 子进程可能仍占用输出；WaitDelay 到期后强制关闭管道返回
+	cmd.WaitDelay = 2 * time.Second
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行命令失败: %w", err)
+	}
+
+	ip := re.FindString(string(out))
+	if ip == "" {
+		return "", fmt.Errorf("命令输出中未找到 %s 地址", ipType)
+	}
+	return ip, nil
+}
+
+// getIPFromUPnP 通过 UPnP GetExternalIPAddress 查询路由器 WAN 口地址（仅 IPv4）
+func getIPFromUPnP(ipType string) (string, error) {
+	if ipType == "IPv6" {
+		return "", fmt.Errorf("UPnP 仅支持获取 IPv4 地址")
+	}
+	ip, err := stun.UPnPExternalIP()
+	if err != nil {
+		return "", err
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() == nil {
+		return "", fmt.Errorf("UPnP 返回的外部地址无效: %s", ip)
+	}
+	// 光猫拨号或运营商 NAT 时路由器 WAN 口为内网地址，不能发布
+	if !isPublicIPv4(parsed) {
+		return "", fmt.Errorf("UPnP 返回的外部地址 %s 不是公网地址（上级存在 NAT）", ip)
+	}
+	return ip, nil
+}
+
+// cgnatNet 运营商级 NAT 地址段（RFC 6598）
+var cgnatNet = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIPv4 排除私有、CGNAT、回环、链路本地等非公网地址
+func isPublicIPv4(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || cgnatNet.Contains(ip))
+}
+
+// getIPFromTask 读取另一个 DDNS 任务最近一次获取到的 IP
+func (m *Manager) getIPFromTask(task *model.DDNSTask, ipType string) (string, error) {
+	if task.IPSourceID == 0 || task.IPSourceID == task.ID {
+		return "", fmt.Errorf("未指定有效的来源 DDNS 任务")
+	}
+	var source model.DDNSTask
+	if err := m.db.First(&source, task.IPSourceID).Error; err != nil {
+		return "", fmt.Errorf("来源 DDNS 任务 [ID=%d] 不存在: %w", task.IPSourceID, err)
+	}
+	ip := source.CurrentIP
+	if currentIPColumn(&source, ipType) == "current_ipv6" {
+		ip = source.CurrentIPv6
+	}
+	if ip == "" || familyOfIP(ip) != ipType {
+		return "", fmt.Errorf("来源 DDNS 任务 [%s] 暂无 %s 地址", source.Name, ipType)
+	}
+	return ip, nil
+}
+
+// getIPFromStunRule 读取 STUN 穿透规则当前的公网 IP
+func (m *Manager) getIPFromStunRule(ruleID uint, ipType string) (string, error) {
+	if ruleID == 0 {
+		return "", fmt.Errorf("未指定来源 STUN 规则")
+	}
+	var rule model.StunRule
+	if err := m.db.First(&rule, ruleID).Error; err != nil {
+		return "", fmt.Errorf("来源 STUN 规则 [ID=%d] 不存在: %w", ruleID, err)
+	}
+	if rule.CurrentIP == "" || familyOfIP(rule.CurrentIP) != ipType {
+		return "", fmt.Errorf("来源 STUN 规则 [%s] 暂无 %s 地址", rule.Name, ipType)
+	}
+	return rule.CurrentIP, nil
+}
+
+// getIPByVote 并发请求所有查询接口，取超过半数接口一致返回的 IP
+// 防止单个回显服务返回错误地址导致解析被改错
+func getIPByVote(task *model.DDNSTask, ipType string) (string, error) {
+	re, err := ipRegexFor(task, ipType)
+	if err != nil {
+		return "", err
+	}
+	urls := ipURLs(task, ipType)
+	if len(urls) < 3 {
+		return "", fmt.Errorf("多源投票至少需要 3 个查询接口，当前 %d 个", len(urls))
+	}
+
+	client := &http.Client{Timeout: httpTimeout(task)}
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		votes = make(map[string]int)
+	)
+	for _, rawURL := range urls {
+		wg.Add(1)
+		go func(rawURL string) {
+			defer wg.Done()
+			ip, err := fetchIP(client, rawURL, re)
+			if err != nil || ip == "" {
+				return
+			}
+			// 统一 IPv6 写法后计票
+			if parsed := net.ParseIP(ip); parsed != nil {
+				ip = parsed.String()
+			}
+			mu.Lock()
+			votes[ip]++
+			mu.Unlock()
+		}(rawURL)
+	}
+	wg.Wait()
+
+	var results []string
+	for ip, n := range votes {
+		if n*2 > len(urls) {
+			return ip, nil
+		}
+		results = append(results, fmt.Sprintf("%s×%d", ip, n))
+	}
+	sort.Strings(results)
+	return "", fmt.Errorf("查询接口结果未过半数一致（共 %d 个接口）: %s", len(urls), strings.Join(results, ", "))
+}
+
+// familyOfIP IP 地址所属地址族
+func familyOfIP(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return "IPv6"
+	}
+	return "IPv4"
+}
@@ -3,11 +3,13 @@ package ddns
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,15 +23,29 @@ import (
 type CallbackFunc func(taskID uint, oldIP, newIP string)
 
 type ddnsEntry struct {
-	cancel context.CancelFunc
+	cancel  context.CancelFunc
+	iface   string        // IPGetType=interface 时监听的网络接口
+	trigger chan struct{} // 接口地址变化通知
 }
 
+// addrChangeDebounce 地址变化事件的去抖时间（等待 DAD、多地址批量变化完成）
+const addrChangeDebounce = 3 * time.Second
+
+// addrWatchRetry netlink 订阅中断后的重试间隔
+const addrWatchRetry = 10 * time.Second
+
+// errAddrWatchUnsupported 当前平台不支持地址变化通知
+var errAddrWatchUnsupported = errors.New("当前平台不支持网络地址变化通知")
+
 // Manager DDNS 管理器
 type Manager struct {
 	db         *gorm.DB
 	log        *logrus.Logger
 	entries    sync.Map // map[uint]*ddnsEntry
 	callbackFn CallbackFunc
+
+	watchMu     sync.Mutex
+	watchCancel context.CancelFunc // 地址变化监听，首个 interface 任务启动时创建
 }
 
 // NewManager 创建 DDNS 管理器
@@ -60,6 +76,12 @@ func (m *Manager) StopAll() {
 		entry.cancel()
 		return true
 	})
+	m.watchMu.Lock()
+	if m.watchCancel != nil {
+		m.watchCancel()
+		m.watchCancel = nil
+	}
+	m.watchMu.Unlock()
 }
 
 // Start 启动指定 DDNS 任务
@@ -75,10 +97,15 @@ func (m *Manager) Start(id uint) error {
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	entry := &ddnsEntry{cancel: cancel}
+	entry := &ddnsEntry{cancel: cancel, trigger: make(chan struct{}, 1)}
+	if task.IPGetType == "interface" && task.NetInterface != "" {
+		// 接口地址变化时立即更新，定时轮询作为兜底
+		entry.iface = task.NetInterface
+		m.startAddrWatcher()
+	}
 	m.entries.Store(id, entry)
 
-	go m.runDDNS(ctx, id, &task)
+	go m.runDDNS(ctx, id, &task, entry)
 
 	m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"status":     "running",
@@ -116,10 +143,11 @@ func (m *Manager) RunNow(id uint) error {
 	return nil
 }
 
-// runDDNS 定时循环执行 DDNS 更新
-func (m *Manager) runDDNS(ctx context.Context, id uint, task *model.DDNSTask) {
+// runDDNS 定时循环执行 DDNS 更新，接口地址变化时（去抖后）立即执行
+func (m *Manager) runDDNS(ctx context.Context, id uint, task *model.DDNSTask, entry *ddnsEntry) {
 	defer func() {
-		m.entries.Delete(id)
+		// 任务被重启时 entries 中已是新的 entry，不能误删
+		m.entries.CompareAndDelete(id, entry)
 		m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("status", "stopped")
 		m.log.Infof("[DDNS][%d] 已停止", id)
 	}()
@@ -135,160 +163,338 @@ func (m *Manager) runDDNS(ctx context.Context, id uint, task *model.DDNSTask) {
 	// 立即执行一次
 	m.doUpdate(id, task)
 
+	var debounce <-chan time.Time
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-entry.trigger:
+			// 重新计时，短时间内的多次变化只触发一次更新
+			debounce = time.After(addrChangeDebounce)
+			continue
+		case <-debounce:
+			debounce = nil
+			m.log.Infof("[DDNS][%d] 接口 %s 地址变化，立即检查", id, entry.iface)
 		case <-ticker.C:
-			// 重新读取最新配置（用户可能修改了间隔等参数）
-			var latestTask model.DDNSTask
-			if err := m.db.First(&latestTask, id).Error; err != nil {
-				m.log.Errorf("[DDNS][%d] 读取配置失败: %v", id, err)
-				continue
-			}
-			// 若间隔变化，重启定时器
-			newInterval := time.Duration(latestTask.Interval) * time.Second
-			if newInterval < 30*time.Second {
-				newInterval = 300 * time.Second
-			}
-			if newInterval != interval {
-				ticker.Reset(newInterval)
-				interval = newInterval
-			}
-			m.doUpdate(id, &latestTask)
+		}
+
+		// 重新读取最新配置（用户可能修改了间隔等参数）
+		var latestTask model.DDNSTask
+		if err := m.db.First(&latestTask, id).Error; err != nil {
+			m.log.Errorf("[DDNS][%d] 读取配置失败: %v", id, err)
+			continue
+		}
+		// 若间隔变化，重启定时器
+		newInterval := time.Duration(latestTask.Interval) * time.Second
+		if newInterval < 30*time.Second {
+			newInterval = 300 * time.Second
+		}
+		if newInterval != interval {
+			ticker.Reset(newInterval)
+			interval = newInterval
+		}
+		m.doUpdate(id, &latestTask)
+	}
+}
+
+// startAddrWatcher 启动网络地址变化监听（全局只启动一个）
+func (m *Manager) startAddrWatcher() {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	if m.watchCancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watchCancel = cancel
+	go m.runAddrWatcher(ctx)
+}
+
+// runAddrWatcher 订阅地址变化并通知对应接口的任务，订阅中断时延迟重试
+func (m *Manager) runAddrWatcher(ctx context.Context) {
+	for {
+		err := watchAddrChanges(ctx, m.notifyAddrChange, func(err error) {
+			m.log.Debugf("[DDNS] 地址变化订阅错误: %v", err)
+		})
+		if errors.Is(err, errAddrWatchUnsupported) {
+			m.log.Debugf("[DDNS] %v，仅使用定时轮询", err)
+			return
+		}
+		if err != nil {
+			m.log.Warnf("[DDNS] 监听网络地址变化失败，%s 后重试: %v", addrWatchRetry, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(addrWatchRetry):
 		}
 	}
 }
 
+// notifyAddrChange 通知监听该接口的任务（非阻塞，已有待处理通知时合并）
+func (m *Manager) notifyAddrChange(ifaceName string) {
+	m.entries.Range(func(_, value interface{}) bool {
+		entry := value.(*ddnsEntry)
+		if entry.iface == ifaceName {
+			select {
+			case entry.trigger <- struct{}{}:
+			default:
+			}
+		}
+		return true
+	})
+}
+
 // doUpdate 执行一次 DDNS 更新
 func (m *Manager) doUpdate(id uint, task *model.DDNSTask) {
 	// 若配置了关联域名账号，优先从账号读取凭证
-	accessID, accessSecret, err := m.resolveCredentials(task)
+	account, err := m.resolveAccount(task)
 	if err != nil {
 		m.log.Errorf("[DDNS][%d] 获取凭证失败: %v", id, err)
 		m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("last_error", err.Error())
 		return
 	}
 
-	// 获取当前 IP
-	currentIP, err := m.getIP(task)
-	if err != nil {
-		m.log.Errorf("[DDNS][%d] 获取 IP 失败: %v", id, err)
-		m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("last_error", err.Error())
-		return
-	}
-
-	// 检查 IP 是否变化（从数据库读取最新值）
+	// 从数据库读取最新的缓存 IP 与更新时间
 	var dbTask model.DDNSTask
 	if err := m.db.First(&dbTask, id).Error; err != nil {
 		return
 	}
-	oldIP := dbTask.CurrentIP
-	if oldIP == currentIP {
-		m.log.Debugf("[DDNS][%d] IP 未变化: %s，跳过更新", id, currentIP)
-		return
-	}
-
-	m.log.Infof("[DDNS][%d] IP 变化: %s -> %s，开始更新 DNS", id, oldIP, currentIP)
 
 	// 解析域名列表
-	var domains []string
-	if task.Domains == "" {
-		m.log.Warnf("[DDNS][%d] 域名列表为空，跳过", id)
+	entries, err := parseDomains(task)
+	if err != nil {
+		m.log.Errorf("[DDNS][%d] %v", id, err)
+		m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("last_error", err.Error())
 		return
 	}
-	if err := json.Unmarshal([]byte(task.Domains), &domains); err != nil {
-		// 兼容单域名字符串
-		domains = []string{task.Domains}
-	}
-	if len(domains) == 0 {
+	if len(entries) == 0 {
 		m.log.Warnf("[DDNS][%d] 域名列表为空，跳过", id)
 		return
 	}
 
 	// 创建 DNS 服务商实例
-	provider := NewProvider(task.Provider, accessID, accessSecret)
-	if provider == nil {
-		errMsg := fmt.Sprintf("不支持的 DNS 服务商: %s", task.Provider)
-		m.log.Errorf("[DDNS][%d] %s", id, errMsg)
-		m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("last_error", errMsg)
+	provider, err := NewProvider(account)
+	if err != nil {
+		m.log.Errorf("[DDNS][%d] %v", id, err)
+		m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("last_error", err.Error())
 		return
 	}
 
-	// 确定记录类型
-	recordType := "A"
-	if task.TaskType == "IPv6" {
-		recordType = "AAAA"
-	}
-
-	// 逐个更新域名
+	force := forceDue(&dbTask)
+	updates := map[string]interface{}{}
 	var lastErr string
-	successCount := 0
-	for _, domain := range domains {
-		if domain == "" {
+	attempted := 0
+
+	// 按地址族分别获取 IP 并更新对应记录（双栈任务依次处理 A 与 AAAA）
+	for _, family := range taskFamilies(task) {
+		var list []domainEntry
+		for _, e := range entries {
+			if familyOf(e.Type) == family {
+				list = append(list, e)
+			}
+		}
+		if len(list) == 0 {
 			continue
 		}
-		if err := provider.UpdateRecord(domain, recordType, currentIP, task.TTL); err != nil {
-			m.log.Errorf("[DDNS][%d] 更新域名 %s 失败: %v", id, domain, err)
+
+		currentIP, err := m.getIP(task, family)
+		if err != nil {
+			m.log.Errorf("[DDNS][%d] 获取 %s 地址失败: %v", id, family, err)
 			lastErr = err.Error()
-		} else {
-			m.log.Infof("[DDNS][%d] 域名 %s 更新成功: %s", id, domain, currentIP)
-			successCount++
+			continue
+		}
+
+		ipColumn := currentIPColumn(task, family)
+		oldIP := dbTask.CurrentIP
+		if ipColumn == "current_ipv6" {
+			oldIP = dbTask.CurrentIPv6
+		}
+		changed := oldIP != currentIP
+		if changed {
+			m.log.Infof("[DDNS][%d] %s 地址变化: %s -> %s，开始更新 DNS", id, family, oldIP, currentIP)
+		} else if force {
+			m.log.Infof("[DDNS][%d] 已到达强制更新间隔 %ds，重新提交: %s", id, dbTask.ForceInterval, currentIP)
+		}
+
+		// 逐个核对并更新域名：优先比对线上解析记录，无法查询时按缓存的 IP 判断
+		successCount := 0
+		for _, e := range list {
+			history := model.DDNSHistory{
+				TaskID:   id,
+				OldIP:    oldIP,
+				NewIP:    currentIP,
+				Domain:   e.Domain,
+				Provider: account.Provider,
+				Success:  true,
+			}
+
+			// 配置了设备后缀时，用当前地址的前缀与后缀组合出该设备的地址
+			value := currentIP
+			if e.Suffix != "" {
+				value, err = composeIPv6(currentIP, task.IPv6PrefixLen, e.Suffix)
+				if err != nil {
+					m.log.Errorf("[DDNS][%d] 域名 %s 地址组合失败: %v", id, e.Domain, err)
+					lastErr = err.Error()
+					attempted++
+					history.NewIP = ""
+					history.Success = false
+					history.Message = err.Error()
+					m.db.Create(&history)
+					continue
+				}
+				history.NewIP = value
+			}
+
+			differs := changed
+			live, verr := m.liveRecord(provider, task, e.Domain, e.Type)
+			if verr != nil {
+				m.log.Debugf("[DDNS][%d] 查询域名 %s 线上记录失败，按缓存 IP 判断: %v", id, e.Domain, verr)
+			} else {
+				differs = !containsIP(live, value)
+				if len(live) > 0 {
+					history.OldIP = strings.Join(live, ",")
+				}
+				if differs && !changed {
+					// IP 未变化但线上记录不一致：记录被外部修改或上次更新未生效
+					m.log.Warnf("[DDNS][%d] 域名 %s 解析记录不一致: 线上 %v，期望 %s", id, e.Domain, live, value)
+					history.Message = fmt.Sprintf("解析记录不一致（线上: %s），已重新提交", liveText(live))
+				}
+			}
+			if !differs && !force {
+				continue
+			}
+			if !differs && history.Message == "" {
+				history.Message = "到达强制更新间隔，重新提交"
+			}
+
+			attempted++
+			if err := provider.UpdateRecord(e.Domain, e.Type, value, task.TTL, force); err != nil {
+				m.log.Errorf("[DDNS][%d] 更新域名 %s [%s] 失败: %v", id, e.Domain, e.Type, err)
+				lastErr = err.Error()
+				history.Success = false
+				history.Message = err.Error()
+			} else {
+				m.log.Infof("[DDNS][%d] 域名 %s [%s] 更新成功: %s", id, e.Domain, e.Type, value)
+				successCount++
+				if differs && task.WebhookEnable {
+					go m.sendWebhook(task, e.Domain, e.Type, value)
+				}
+			}
+			m.db.Create(&history)
+		}
+
+		// 全部失败时保留旧 IP，下次检查时重试
+		if successCount > 0 {
+			updates[ipColumn] = currentIP
+			// IP 变化时触发回调
+			if changed && m.callbackFn != nil {
+				m.log.Debugf("[DDNS][%d] 触发 IP 变化回调: %s -> %s", id, oldIP, currentIP)
+				go m.callbackFn(id, oldIP, currentIP)
+			}
 		}
 	}
 
-	now := time.Now()
-	updates := map[string]interface{}{
-		"current_ip":       currentIP,
-		"last_update_time": now,
-		"last_error":       lastErr,
+	if attempted == 0 {
+		if lastErr == "" {
+			m.log.Debugf("[DDNS][%d] IP 未变化且解析记录一致，跳过更新", id)
+		}
+		if dbTask.LastError != lastErr {
+			m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Update("last_error", lastErr)
+		}
+		return
 	}
+
+	updates["last_update_time"] = time.Now()
+	updates["last_error"] = lastErr
 	m.db.Model(&model.DDNSTask{}).Where("id = ?", id).Updates(updates)
+}
 
-	// IP 变化时触发回调
-	if successCount > 0 && m.callbackFn != nil {
-		m.log.Debugf("[DDNS][%d] 触发 IP 变化回调: %s -> %s", id, oldIP, currentIP)
-		go m.callbackFn(id, oldIP, currentIP)
+// forceDue 判断是否已到达强制更新间隔（ForceInterval 为 0 时禁用）
+func forceDue(task *model.DDNSTask) bool {
+	if task.ForceInterval <= 0 {
+		return false
+	}
+	if task.LastUpdateTime == nil {
+		return true
 	}
+	return time.Since(*task.LastUpdateTime) >= time.Duration(task.ForceInterval)*time.Second
 }
 
-// resolveCredentials 解析凭证：优先使用关联域名账号，否则使用任务自身配置
-func (m *Manager) resolveCredentials(task *model.DDNSTask) (accessID, accessSecret string, err error) {
+// httpTimeout 任务配置的 HTTP 超时，未配置时默认 10 秒
+func httpTimeout(task *model.DDNSTask) time.Duration {
+	if task.HttpTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(task.HttpTimeout) * time.Second
+}
+
+// resolveAccount 解析凭证：优先使用关联域名账号，否则使用任务自身配置
+func (m *Manager) resolveAccount(task *model.DDNSTask) (model.DomainAccount, error) {
 	// 若配置了关联域名账号 ID，从账号表读取凭证
 	if task.DomainAccountID > 0 {
 		var account model.DomainAccount
 		if err := m.db.First(&account, task.DomainAccountID).Error; err != nil {
-			return "", "", fmt.Errorf("关联域名账号 [ID=%d] 不存在: %w", task.DomainAccountID, err)
+			return account, fmt.Errorf("关联域名账号 [ID=%d] 不存在: %w", task.DomainAccountID, err)
 		}
 		// 若任务的 Provider 为空，使用账号的 Provider
 		if task.Provider == "" {
 			task.Provider = account.Provider
 		}
-		return account.AccessID, account.AccessSecret, nil
+		account.Provider = task.Provider
+		return account, nil
 	}
 	// 使用任务自身凭证
-	return task.AccessID, task.AccessSecret, nil
+	return model.DomainAccount{
+		Provider:     task.Provider,
+		AccessID:     task.AccessID,
+		AccessSecret: task.AccessSecret,
+	}, nil
 }
 
-// getIP 根据配置获取当前 IP 地址
-func (m *Manager) getIP(task *model.DDNSTask) (string, error) {
+// getIP 根据配置获取当前 IP 地址，ipType 为 IPv4 或 IPv6
+func (m *Manager) getIP(task *model.DDNSTask, ipType string) (string, error) {
 	var ip string
 	var err error
 
 	switch task.IPGetType {
 	case "custom":
-		// 直接使用 NetInterface 字段存储的自定义 IP
-		ip = task.NetInterface
+		// 直接使用 NetInterface 字段存储的自定义 IP（双栈任务以逗号分隔 IPv4 与 IPv6）
+		ip = pickCustomIP(task.NetInterface, ipType)
 		if ip == "" {
-			return "", fmt.Errorf("自定义 IP 为空")
+			return "", fmt.Errorf("自定义 %s 地址为空", ipType)
 		}
 	case "interface":
-		ip, err = getIPFromInterface(task.NetInterface, task.TaskType)
+		ip, err = getIPFromInterface(task.NetInterface, ipType)
+		if err != nil {
+			return "", err
+		}
+	case "command":
+		ip, err = getIPFromCommand(task, ipType)
+		if err != nil {
+			return "", err
+		}
+	case "upnp":
+		ip, err = getIPFromUPnP(ipType)
+		if err != nil {
+			return "", err
+		}
+	case "ddns_task":
+		ip, err = m.getIPFromTask(task, ipType)
+		if err != nil {
+			return "", err
+		}
+	case "stun_rule":
+		ip, err = m.getIPFromStunRule(task.IPSourceID, ipType)
+		if err != nil {
+			return "", err
+		}
+	case "vote":
+		ip, err = getIPByVote(task, ipType)
 		if err != nil {
 			return "", err
 		}
 	default: // "url" 或空
-		ip, err = getIPFromURL(task)
+		ip, err = getIPFromURL(task, ipType)
 		if err != nil {
 			return "", err
 		}
@@ -312,7 +518,33 @@ func (m *Manager) getIP(task *model.DDNSTask) (string, error) {
 }
 
 // getIPFromURL 从 URL 获取 IP
-func getIPFromURL(task *model.DDNSTask) (string, error) {
+func getIPFromURL(task *model.DDNSTask, ipType string) (string, error) {
+	re, err := ipRegexFor(task, ipType)
+	if err != nil {
+		return "", err
+	}
+
+	client := &http.Client{Timeout: httpTimeout(task)}
+	var lastErr error
+	for _, rawURL := range ipURLs(task, ipType) {
+		ip, err := fetchIP(client, rawURL, re)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if ip != "" {
+			return ip, nil
+		}
+	}
+
+	if lastErr != nil {
+		return "", fmt.Errorf("所有 IP 查询接口均失败，最后错误: %w", lastErr)
+	}
+	return "", fmt.Errorf("所有 IP 查询接口均未返回有效 IP")
+}
+
+// ipURLs 任务配置的 IP 查询接口，未配置时使用默认接口
+func ipURLs(task *model.DDNSTask, ipType string) []string {
 	var urls []string
 	if task.IPGetURLs != "" {
 		if err := json.Unmarshal([]byte(task.IPGetURLs), &urls); err != nil {
@@ -320,63 +552,55 @@ func getIPFromURL(task *model.DDNSTask) (string, error) {
 			urls = []string{task.IPGetURLs}
 		}
 	}
+	if len(urls) > 0 {
+		return urls
+	}
 
 	// 使用默认 IP 查询接口
-	if len(urls) == 0 {
-		if task.TaskType == "IPv6" {
-			urls = []string{
-				"https://6.ipw.cn",
-				"https://ipv6.ddnspod.com",
-				"https://v6.ident.me",
-			}
-		} else {
-			urls = []string{
-				"https://4.ipw.cn",
-				"https://ip.3322.net",
-				"https://myip4.ipip.net",
-				"https://v4.ident.me",
-			}
+	if ipType == "IPv6" {
+		return []string{
+			"https://6.ipw.cn",
+			"https://ipv6.ddnspod.com",
+			"https://v6.ident.me",
 		}
 	}
+	return []string{
+		"https://4.ipw.cn",
+		"https://ip.3322.net",
+		"https://myip4.ipip.net",
+		"https://v4.ident.me",
+	}
+}
 
-	// 优先使用用户自定义正则，否则使用默认 IP 正则
+// ipRegexFor 优先使用用户自定义正则，否则使用默认 IP 正则
+func ipRegexFor(task *model.DDNSTask, ipType string) (*regexp.Regexp, error) {
 	var ipRegexStr string
 	if task.IPRegex != "" {
 		ipRegexStr = task.IPRegex
-	} else if task.TaskType == "IPv6" {
+	} else if ipType == "IPv6" {
 		ipRegexStr = `([0-9a-fA-F]{0,4}:){2,7}[0-9a-fA-F]{0,4}`
 	} else {
 		ipRegexStr = `(25[0-5]|2[0-4]\d|1\d{2}|[1-9]\d|\d)(\.(25[0-5]|2[0-4]\d|1\d{2}|[1-9]\d|\d)){3}`
 	}
 	re, err := regexp.Compile(ipRegexStr)
 	if err != nil {
-		return "", fmt.Errorf("IP 正则编译失败: %w", err)
+		return nil, fmt.Errorf("IP 正则编译失败: %w", err)
 	}
+	return re, nil
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	var lastErr error
-	for _, rawURL := range urls {
-		resp, err := client.Get(rawURL)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		ip := re.FindString(string(body))
-		if ip != "" {
-			return ip, nil
-		}
+// fetchIP 请求 URL 并从响应中提取 IP，未匹配时返回空字符串
+func fetchIP(client *http.Client, rawURL string, re *regexp.Regexp) (string, error) {
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return "", err
 	}
-
-	if lastErr != nil {
-		return "", fmt.Errorf("所有 IP 查询接口均失败，最后错误: %w", lastErr)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return "", err
 	}
-	return "", fmt.Errorf("所有 IP 查询接口均未返回有效 IP")
+	return re.FindString(string(body)), nil
 }
 
 // getIPFromInterface 从网络接口获取 IP
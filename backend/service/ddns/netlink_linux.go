@@ -0,0 +1,45 @@
+//go:build linux
+
+package ddns
+
+import (
+	"context"
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+// watchAddrChanges 订阅 netlink RTM_NEWADDR/RTM_DELADDR，地址变化时以接口名调用 notify
+// 订阅中断（ctx 取消或内核通道关闭）时返回
+func watchAddrChanges(ctx context.Context, notify func(ifaceName string), onError func(error)) error {
+	ch := make(chan netlink.AddrUpdate, 64)
+	done := make(chan struct{})
+	defer close(done)
+
+	if err := netlink.AddrSubscribeWithOptions(ch, done, netlink.AddrSubscribeOptions{
+		ErrorCallback: onError,
+	}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			ip := update.LinkAddress.IP
+			// 链路本地与回环地址不参与 DDNS，忽略
+			if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			iface, err := net.InterfaceByIndex(update.LinkIndex)
+			if err != nil {
+				continue
+			}
+			notify(iface.Name)
+		}
+	}
+}
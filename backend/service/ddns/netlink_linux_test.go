@@ -0,0 +1,93 @@
+//go:build linux
+
+package ddns
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+func TestWatchAddrChanges(t *testing.T) {
+	// 在独立网络命名空间中创建 dummy 网卡，不改动宿主机网卡
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	orig, err := netns.Get()
+	if err != nil {
+		t.Skipf("无法获取当前网络命名空间: %v", err)
+	}
+	defer orig.Close()
+	ns, err := netns.New()
+	if err != nil {
+		t.Skipf("无法创建网络命名空间（需要 root）: %v", err)
+	}
+	defer ns.Close()
+	if err := netns.Set(orig); err != nil {
+		t.Fatalf("切回原网络命名空间失败: %v", err)
+	}
+	h, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		t.Skipf("无法打开测试命名空间的 netlink 句柄: %v", err)
+	}
+	defer h.Close()
+	// 内核不支持 dummy 网卡时退而使用命名空间自带的 lo（与宿主机 lo 相互独立）
+	linkName := "ddnstest0"
+	if err := h.LinkAdd(&netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: linkName}}); err != nil {
+		t.Logf("无法创建 dummy 网卡，改用命名空间内的 lo: %v", err)
+		linkName = "lo"
+	}
+	link, err := h.LinkByName(linkName)
+	if err != nil {
+		t.Fatalf("LinkByName: %v", err)
+	}
+	if err := h.LinkSetUp(link); err != nil {
+		t.Fatalf("LinkSetUp: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notified := make(chan string, 16)
+	errCh := make(chan error, 1)
+	go func() {
+		// 不解锁线程：goroutine 退出时该线程随之销毁，不会带着测试命名空间回到调度器
+		runtime.LockOSThread()
+		if err := netns.Set(ns); err != nil {
+			errCh <- err
+			return
+		}
+		errCh <- watchAddrChanges(ctx, func(name string) { notified <- name }, func(err error) { t.Log(err) })
+	}()
+	// 等待订阅建立
+	time.Sleep(200 * time.Millisecond)
+
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: net.IPv4(192, 0, 2, 10), Mask: net.CIDRMask(24, 32)}}
+	if err := h.AddrAdd(link, addr); err != nil {
+		t.Fatalf("AddrAdd: %v", err)
+	}
+
+	select {
+	case name := <-notified:
+		if name != linkName {
+			t.Fatalf("notify(%q), want %s", name, linkName)
+		}
+	case err := <-errCh:
+		t.Fatalf("watchAddrChanges 提前返回: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("添加地址后未收到通知")
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("watchAddrChanges 返回错误: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后 watchAddrChanges 未返回")
+	}
+}
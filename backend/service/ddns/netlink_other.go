@@ -0,0 +1,10 @@
+//go:build !linux
+
+package ddns
+
+import "context"
+
+// watchAddrChanges 非 Linux 平台不支持地址变化通知，仅依靠定时轮询
+func watchAddrChanges(ctx context.Context, notify func(ifaceName string), onError func(error)) error {
+	return errAddrWatchUnsupported
+}
@@ -0,0 +1,73 @@
+package ddns
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// PublishEndpoint 将 STUN 规则当前的公网端点发布为 DNS 记录（实现 stun.EndpointPublisher）
+// srv:   A 记录 domain -> ip，SRV 记录 _service._proto.domain -> "0 5 port domain"
+// txt:   TXT 记录 domain -> "ip:port"
+// https: A 记录 domain -> ip，HTTPS 记录 domain -> "1 . port=port ipv4hint=ip"
+func (m *Manager) PublishEndpoint(rule *model.StunRule, ip string, port int) error {
+	domain := strings.TrimSuffix(strings.TrimSpace(rule.DNSPublishDomain), ".")
+	if domain == "" {
+		return fmt.Errorf("未配置发布域名")
+	}
+	if rule.DNSPublishAccountID == 0 {
+		return fmt.Errorf("未关联域名账号")
+	}
+
+	var account model.DomainAccount
+	if err := m.db.First(&account, rule.DNSPublishAccountID).Error; err != nil {
+		return fmt.Errorf("关联域名账号 [ID=%d] 不存在: %w", rule.DNSPublishAccountID, err)
+	}
+	provider, err := NewProvider(account)
+	if err != nil {
+		return err
+	}
+
+	ttl := "120"
+	if rule.DNSPublishTTL > 0 {
+		ttl = strconv.Itoa(rule.DNSPublishTTL)
+	}
+
+	type record struct {
+		name, recordType, value string
+	}
+	var records []record
+
+	switch strings.ToLower(rule.DNSPublishType) {
+	case "txt":
+		records = append(records, record{domain, "TXT", fmt.Sprintf("%s:%d", ip, port)})
+	case "https":
+		records = append(records,
+			record{domain, "A", ip},
+			record{domain, "HTTPS", fmt.Sprintf("1 . port=%d ipv4hint=%s", port, ip)},
+		)
+	default: // srv
+		service := strings.TrimPrefix(strings.TrimSpace(rule.DNSPublishService), "_")
+		if service == "" {
+			return fmt.Errorf("SRV 发布需要填写服务名")
+		}
+		proto := strings.ToLower(rule.TargetProtocol)
+		if proto != "udp" {
+			proto = "tcp"
+		}
+		records = append(records,
+			record{domain, "A", ip},
+			record{fmt.Sprintf("_%s._%s.%s", service, proto, domain), "SRV", fmt.Sprintf("0 5 %d %s", port, domain)},
+		)
+	}
+
+	for _, r := range records {
+		if err := provider.UpdateRecord(r.name, r.recordType, r.value, ttl, false); err != nil {
+			return fmt.Errorf("发布 %s 记录 %s 失败: %w", r.recordType, r.name, err)
+		}
+		m.log.Infof("[DDNS][STUN发布][%s] %s %s -> %s", rule.Name, r.recordType, r.name, r.value)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package ddns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/service/dnsprovider"
+)
+
+// RecordQuerier 支持查询线上解析记录的服务商
+type RecordQuerier interface {
+	// QueryRecord 返回域名当前的记录值
+	QueryRecord(domain, recordType string) ([]string, error)
+}
+
+// QueryRecord 通过服务商查询 API 获取线上记录值
+func (r *recordProvider) QueryRecord(domain, recordType string) ([]string, error) {
+	host, zone := dnsprovider.Split(r.p, domain)
+	records, err := r.p.FindRecords(zone, host, recordType)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(records))
+	for _, rec := range records {
+		values = append(values, rec.Value)
+	}
+	return values, nil
+}
+
+// liveRecord 获取域名的线上记录值
+// 优先使用服务商查询 API；服务商不支持或查询失败时，若任务配置了解析服务器则向权威服务器查询
+func (m *Manager) liveRecord(provider DNSProvider, task *model.DDNSTask, domain, recordType string) ([]string, error) {
+	var queryErr error
+	if q, ok := provider.(RecordQuerier); ok {
+		values, err := q.QueryRecord(domain, recordType)
+		if err == nil {
+			return values, nil
+		}
+		queryErr = err
+	} else {
+		queryErr = dnsprovider.ErrNotSupported
+	}
+
+	resolvers := parseResolvers(task.VerifyResolvers)
+	if len(resolvers) == 0 {
+		return nil, queryErr
+	}
+	values, err := lookupRecord(resolvers, domain, recordType, task)
+	if err != nil {
+		return nil, errors.Join(queryErr, err)
+	}
+	return values, nil
+}
+
+// parseResolvers 解析任务配置的 DNS 服务器列表（JSON 数组或逗号分隔），未指定端口时使用 53
+func parseResolvers(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		list = strings.Split(raw, ",")
+	}
+	var out []string
+	for _, r := range list {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(r); err != nil {
+			r = net.JoinHostPort(strings.Trim(r, "[]"), "53")
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// lookupRecord 向域名所属区域的权威服务器查询记录（不递归），避免递归解析器缓存的旧记录被误判为不一致
+// 解析服务器仅用于查找权威服务器；域名不存在（NXDOMAIN）视为记录为空
+func lookupRecord(resolvers []string, domain, recordType string, task *model.DDNSTask) ([]string, error) {
+	qtype, ok := dns.StringToType[recordType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的记录类型: %s", recordType)
+	}
+
+	client := &dns.Client{Timeout: httpTimeout(task)}
+	servers, err := authoritativeServers(client, resolvers, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(domain), qtype)
+	msg.RecursionDesired = false
+	in, err := exchangeFirst(client, servers, msg)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, rr := range in.Answer {
+		switch v := rr.(type) {
+		case *dns.A:
+			if qtype == dns.TypeA {
+				values = append(values, v.A.String())
+			}
+		case *dns.AAAA:
+			if qtype == dns.TypeAAAA {
+				values = append(values, v.AAAA.String())
+			}
+		}
+	}
+	return values, nil
+}
+
+// authoritativeServers 通过解析服务器逐级向上查询 NS，返回域名所属区域权威服务器的地址（host:53）
+func authoritativeServers(client *dns.Client, resolvers []string, domain string) ([]string, error) {
+	name := dns.Fqdn(domain)
+	for {
+		msg := new(dns.Msg)
+		msg.SetQuestion(name, dns.TypeNS)
+		in, err := exchangeFirst(client, resolvers, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		var servers []string
+		for _, rr := range in.Answer {
+			ns, ok := rr.(*dns.NS)
+			if !ok {
+				continue
+			}
+			for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+				q := new(dns.Msg)
+				q.SetQuestion(ns.Ns, qtype)
+				resp, err := exchangeFirst(client, resolvers, q)
+				if err != nil {
+					continue
+				}
+				for _, a := range resp.Answer {
+					switch v := a.(type) {
+					case *dns.A:
+						servers = append(servers, net.JoinHostPort(v.A.String(), "53"))
+					case *dns.AAAA:
+						servers = append(servers, net.JoinHostPort(v.AAAA.String(), "53"))
+					}
+				}
+			}
+		}
+		if len(servers) > 0 {
+			return servers, nil
+		}
+
+		// 当前名称不是区域顶点，继续查询上一级
+		off, end := dns.NextLabel(name, 0)
+		if end || name[off:] == "." {
+			return nil, fmt.Errorf("未找到域名 %s 的权威服务器", domain)
+		}
+		name = name[off:]
+	}
+}
+
+// exchangeFirst 依次向服务器发送查询，返回第一个成功（含 NXDOMAIN）的应答
+func exchangeFirst(client *dns.Client, servers []string, msg *dns.Msg) (*dns.Msg, error) {
+	var lastErr error
+	for _, server := range servers {
+		in, _, err := client.Exchange(msg, server)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+			lastErr = fmt.Errorf("%s 返回 %s", server, dns.RcodeToString[in.Rcode])
+			continue
+		}
+		return in, nil
+	}
+	return nil, fmt.Errorf("DNS 查询失败: %w", lastErr)
+}
+
+// containsIP 判断记录值中是否包含指定 IP（按 IP 语义比较，兼容 IPv6 不同写法）
+func containsIP(values []string, ip string) bool {
+	target := net.ParseIP(ip)
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == ip {
+			return true
+		}
+		if target != nil && target.Equal(net.ParseIP(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// liveText 线上记录值的展示文本
+func liveText(values []string) string {
+	if len(values) == 0 {
+		return "无记录"
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,86 @@
+package ddns
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// webhookHeader Webhook 自定义请求头
+type webhookHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// sendWebhook 发送 DDNS 任务配置的 Webhook 通知
+func (m *Manager) sendWebhook(task *model.DDNSTask, domain, recordType, ip string) {
+	if err := doWebhook(task, domain, recordType, ip); err != nil {
+		m.log.Warnf("[DDNS][%d] 域名 %s Webhook 通知失败: %v", task.ID, domain, err)
+		return
+	}
+	m.log.Debugf("[DDNS][%d] 域名 %s Webhook 通知已发送", task.ID, domain)
+}
+
+// doWebhook 构造并发送 Webhook 请求
+// URL 与请求体支持变量：{ip} {domain} {type}，URL 中的变量会进行转义
+func doWebhook(task *model.DDNSTask, domain, recordType, ip string) error {
+	if task.WebhookURL == "" {
+		return fmt.Errorf("Webhook URL 未配置")
+	}
+	method := strings.ToUpper(task.WebhookMethod)
+	if method == "" {
+		method = "POST"
+	}
+
+	reqURL := strings.NewReplacer(
+		"{ip}", url.QueryEscape(ip),
+		"{domain}", url.QueryEscape(domain),
+		"{type}", url.QueryEscape(recordType),
+	).Replace(task.WebhookURL)
+
+	var body io.Reader
+	if task.WebhookBody != "" && method != "GET" {
+		body = strings.NewReader(strings.NewReplacer(
+			"{ip}", ip,
+			"{domain}", domain,
+			"{type}", recordType,
+		).Replace(task.WebhookBody))
+	}
+
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return fmt.Errorf("创建 Webhook 请求失败: %w", err)
+	}
+	if body != nil && json.Valid([]byte(task.WebhookBody)) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if task.WebhookHeaders != "" {
+		var headers []webhookHeader
+		if err := json.Unmarshal([]byte(task.WebhookHeaders), &headers); err != nil {
+			return fmt.Errorf("解析 Webhook 请求头失败: %w", err)
+		}
+		for _, h := range headers {
+			if h.Key != "" {
+				req.Header.Set(h.Key, h.Value)
+			}
+		}
+	}
+
+	client := &http.Client{Timeout: httpTimeout(task)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 Webhook 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Webhook 响应错误 HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
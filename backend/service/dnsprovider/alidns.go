@@ -0,0 +1,219 @@
+package dnsprovider
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== 阿里云 DNS =====
+
+// AliDNS 阿里云 DNS 服务商
+type AliDNS struct {
+	AccessKeyID     string
+	AccessKeySecret string
+}
+
+func newAliDNS(acc model.DomainAccount) Provider {
+	return &AliDNS{AccessKeyID: acc.AccessID, AccessKeySecret: acc.AccessSecret}
+}
+
+const aliDNSEndpoint = "https://alidns.aliyuncs.com/"
+
+// aliSign 生成阿里云 API 签名（HMAC-SHA1）
+func (p *AliDNS) aliSign(params map[string]string) string {
+	var parts []string
+	for _, k := range sortedKeys(params) {
+		parts = append(parts, aliEscape(k)+"="+aliEscape(params[k]))
+	}
+	stringToSign := "GET&%2F&" + aliEscape(strings.Join(parts, "&"))
+
+	mac := hmac.New(sha1.New, []byte(p.AccessKeySecret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliEscape 按阿里云签名要求编码（空格为 %20，* 为 %2A，~ 不编码）
+func aliEscape(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
+
+// aliRequest 发送阿里云 DNS API 请求并解析结果
+func (p *AliDNS) aliRequest(action string, params map[string]string, out interface{}) error {
+	all := map[string]string{
+		"Action":           action,
+		"AccessKeyId":      p.AccessKeyID,
+		"Format":           "JSON",
+		"Version":          "2015-01-09",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   strconv.FormatInt(time.Now().UnixNano(), 10),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for k, v := range params {
+		all[k] = v
+	}
+	all["Signature"] = p.aliSign(all)
+
+	var parts []string
+	for _, k := range sortedKeys(all) {
+		parts = append(parts, aliEscape(k)+"="+aliEscape(all[k]))
+	}
+	body, err := httpGet(aliDNSEndpoint+"?"+strings.Join(parts, "&"), nil)
+	var apiErr struct {
+		Code    string `json:"Code"`
+		Message string `json:"Message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+		return fmt.Errorf("阿里云 API 错误 %s: %s", apiErr.Code, apiErr.Message)
+	}
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("解析阿里云响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+type aliRecord struct {
+	RecordId string `json:"RecordId"`
+	RR       string `json:"RR"`
+	Type     string `json:"Type"`
+	Value    string `json:"Value"`
+	TTL      int    `json:"TTL"`
+}
+
+// describeRecords 分页查询解析记录，extra 为附加的过滤参数
+func (p *AliDNS) describeRecords(zone string, extra map[string]string) ([]Record, error) {
+	var records []Record
+	for page := 1; ; page++ {
+		params := map[string]string{
+			"DomainName": zone,
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   "500",
+		}
+		for k, v := range extra {
+			params[k] = v
+		}
+		var result struct {
+			TotalCount    int `json:"TotalCount"`
+			DomainRecords struct {
+				Record []aliRecord `json:"Record"`
+			} `json:"DomainRecords"`
+		}
+		if err := p.aliRequest("DescribeDomainRecords", params, &result); err != nil {
+			return nil, fmt.Errorf("获取阿里云解析记录失败: %w", err)
+		}
+		for _, r := range result.DomainRecords.Record {
+			records = append(records, Record{ID: r.RecordId, Type: r.Type, Host: r.RR, Value: r.Value, TTL: r.TTL})
+		}
+		if len(result.DomainRecords.Record) == 0 || len(records) >= result.TotalCount {
+			return records, nil
+		}
+	}
+}
+
+// ListDomains 列出账号下的域名
+func (p *AliDNS) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for page := 1; ; page++ {
+		var result struct {
+			TotalCount int `json:"TotalCount"`
+			Domains    struct {
+				Domain []struct {
+					DomainId   string `json:"DomainId"`
+					DomainName string `json:"DomainName"`
+				} `json:"Domain"`
+			} `json:"Domains"`
+		}
+		if err := p.aliRequest("DescribeDomains", map[string]string{
+			"PageNumber": strconv.Itoa(page),
+			"PageSize":   "100",
+		}, &result); err != nil {
+			return nil, fmt.Errorf("获取阿里云域名列表失败: %w", err)
+		}
+		for _, d := range result.Domains.Domain {
+			domains = append(domains, Domain{Name: d.DomainName, ID: d.DomainId})
+		}
+		if len(result.Domains.Domain) == 0 || len(domains) >= result.TotalCount {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *AliDNS) ListRecords(zone string) ([]Record, error) {
+	return p.describeRecords(zone, nil)
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *AliDNS) FindRecords(zone, host, recordType string) ([]Record, error) {
+	list, err := p.describeRecords(zone, map[string]string{"RRKeyWord": host, "Type": recordType})
+	if err != nil {
+		return nil, err
+	}
+	return filterRecords(list, host, recordType), nil
+}
+
+// CreateRecord 新增解析记录
+func (p *AliDNS) CreateRecord(zone string, rec Record) (string, error) {
+	var result struct {
+		RecordId string `json:"RecordId"`
+	}
+	if err := p.aliRequest("AddDomainRecord", map[string]string{
+		"DomainName": zone,
+		"RR":         rec.Host,
+		"Type":       rec.Type,
+		"Value":      rec.Value,
+		"TTL":        strconv.Itoa(ttlOr(rec.TTL, 600, 1)),
+	}, &result); err != nil {
+		return "", fmt.Errorf("新增阿里云解析记录失败: %w", err)
+	}
+	return result.RecordId, nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *AliDNS) UpdateRecord(zone string, rec Record) error {
+	if err := p.aliRequest("UpdateDomainRecord", map[string]string{
+		"RecordId": rec.ID,
+		"RR":       rec.Host,
+		"Type":     rec.Type,
+		"Value":    rec.Value,
+		"TTL":      strconv.Itoa(ttlOr(rec.TTL, 600, 1)),
+	}, nil); err != nil {
+		// 强制提交相同的值时阿里云返回 DomainRecordDuplicate，记录已是期望值
+		if strings.Contains(err.Error(), "DomainRecordDuplicate") {
+			return nil
+		}
+		return fmt.Errorf("修改阿里云解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 删除解析记录
+func (p *AliDNS) DeleteRecord(zone string, rec Record) error {
+	if err := p.aliRequest("DeleteDomainRecord", map[string]string{"RecordId": rec.ID}, nil); err != nil {
+		return fmt.Errorf("删除阿里云解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *AliDNS) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *AliDNS) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
@@ -0,0 +1,289 @@
+package dnsprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== Cloudflare =====
+
+// Cloudflare Cloudflare DNS 服务商
+type Cloudflare struct {
+	APIToken string
+	APIKey   string // Global API Key（api_key 认证方式）
+	Email    string // 邮箱（api_key 认证方式）
+	ZoneID   string // 可选，若为空则按根域名自动查询
+}
+
+// newCloudflare API 令牌方式：AccessID = Zone ID（可选），AccessSecret = API Token；
+// api_key 方式：Email（或 AccessID）= 账号邮箱，AccessSecret = Global API Key
+func newCloudflare(acc model.DomainAccount) Provider {
+	if acc.AuthType == "api_key" {
+		email := acc.Email
+		if email == "" && strings.Contains(acc.AccessID, "@") {
+			email = acc.AccessID
+		}
+		if email != "" {
+			return &Cloudflare{Email: email, APIKey: acc.AccessSecret}
+		}
+	}
+	return &Cloudflare{APIToken: acc.AccessSecret, ZoneID: acc.AccessID}
+}
+
+const cfAPIBase = "https://api.cloudflare.com/client/v4"
+
+func (p *Cloudflare) cfHeaders() map[string]string {
+	if p.APIToken != "" {
+		return map[string]string{"Authorization": "Bearer " + p.APIToken}
+	}
+	return map[string]string{"X-Auth-Email": p.Email, "X-Auth-Key": p.APIKey}
+}
+
+type cfResultInfo struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+}
+
+// cfRequest 发送 Cloudflare API 请求，result 字段解析到 out
+func (p *Cloudflare) cfRequest(method, path string, payload, out interface{}) (*cfResultInfo, error) {
+	body, err := httpJSON(method, cfAPIBase+path, p.cfHeaders(), payload)
+	var result struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Result     json.RawMessage `json:"result"`
+		ResultInfo cfResultInfo    `json:"result_info"`
+	}
+	if jsonErr := json.Unmarshal(body, &result); jsonErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("解析 Cloudflare 响应失败: %w", jsonErr)
+	}
+	if !result.Success {
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("Cloudflare API 错误 %d: %s", result.Errors[0].Code, result.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("Cloudflare API 请求失败")
+	}
+	if out != nil {
+		if err := json.Unmarshal(result.Result, out); err != nil {
+			return nil, fmt.Errorf("解析 Cloudflare 响应失败: %w", err)
+		}
+	}
+	return &result.ResultInfo, nil
+}
+
+// getZoneID 根据根域名获取 Zone ID
+func (p *Cloudflare) getZoneID(zone string) (string, error) {
+	if p.ZoneID != "" {
+		return p.ZoneID, nil
+	}
+	var zones []struct {
+		ID string `json:"id"`
+	}
+	if _, err := p.cfRequest("GET", "/zones?name="+url.QueryEscape(zone), nil, &zones); err != nil {
+		return "", fmt.Errorf("查询 Cloudflare Zone 失败: %w", err)
+	}
+	if len(zones) == 0 {
+		return "", fmt.Errorf("未找到域名 %s 对应的 Cloudflare Zone", zone)
+	}
+	return zones[0].ID, nil
+}
+
+type cfRecord struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied"`
+}
+
+// listRecords 分页查询解析记录，query 为附加的过滤参数
+func (p *Cloudflare) listRecords(zone string, query url.Values) ([]Record, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for page := 1; ; page++ {
+		q := url.Values{"per_page": {"100"}, "page": {strconv.Itoa(page)}}
+		for k, v := range query {
+			q[k] = v
+		}
+		var list []cfRecord
+		info, err := p.cfRequest("GET", "/zones/"+zoneID+"/dns_records?"+q.Encode(), nil, &list)
+		if err != nil {
+			return nil, fmt.Errorf("获取 Cloudflare 解析记录失败: %w", err)
+		}
+		for _, r := range list {
+			records = append(records, Record{
+				ID:      r.ID,
+				Type:    r.Type,
+				Host:    RelativeHost(r.Name, zone),
+				Value:   r.Content,
+				TTL:     r.TTL,
+				Proxied: r.Proxied,
+			})
+		}
+		if page >= info.TotalPages {
+			return records, nil
+		}
+	}
+}
+
+// ListDomains 列出账号下的有效域名
+func (p *Cloudflare) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for page := 1; ; page++ {
+		var zones []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		info, err := p.cfRequest("GET", fmt.Sprintf("/zones?per_page=50&page=%d&status=active", page), nil, &zones)
+		if err != nil {
+			return nil, fmt.Errorf("获取 Cloudflare 域名列表失败: %w", err)
+		}
+		for _, z := range zones {
+			domains = append(domains, Domain{Name: z.Name, ID: z.ID})
+		}
+		if page >= info.TotalPages {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *Cloudflare) ListRecords(zone string) ([]Record, error) {
+	return p.listRecords(zone, nil)
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *Cloudflare) FindRecords(zone, host, recordType string) ([]Record, error) {
+	return p.listRecords(zone, url.Values{"type": {recordType}, "name": {JoinHost(host, zone)}})
+}
+
+// cfPayload 构造记录请求体；SRV/HTTPS/SVCB 记录以结构化 data 提交
+func cfPayload(zone string, rec Record) (map[string]interface{}, error) {
+	payload := map[string]interface{}{
+		"type":    rec.Type,
+		"name":    JoinHost(rec.Host, zone),
+		"content": rec.Value,
+		"ttl":     ttlOr(rec.TTL, 1, 1), // 1 = 自动
+	}
+	switch strings.ToUpper(rec.Type) {
+	case "A", "AAAA", "CNAME":
+		payload["proxied"] = rec.Proxied
+	}
+	data, err := cfRecordData(rec.Type, rec.Value)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		delete(payload, "content")
+		payload["data"] = data
+	}
+	return payload, nil
+}
+
+// CreateRecord 新增解析记录
+func (p *Cloudflare) CreateRecord(zone string, rec Record) (string, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return "", err
+	}
+	payload, err := cfPayload(zone, rec)
+	if err != nil {
+		return "", err
+	}
+	var created cfRecord
+	if _, err := p.cfRequest("POST", "/zones/"+zoneID+"/dns_records", payload, &created); err != nil {
+		return "", fmt.Errorf("新增 Cloudflare 解析记录失败: %w", err)
+	}
+	return created.ID, nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *Cloudflare) UpdateRecord(zone string, rec Record) error {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return err
+	}
+	payload, err := cfPayload(zone, rec)
+	if err != nil {
+		return err
+	}
+	if _, err := p.cfRequest("PUT", "/zones/"+zoneID+"/dns_records/"+rec.ID, payload, nil); err != nil {
+		return fmt.Errorf("修改 Cloudflare 解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 删除解析记录
+func (p *Cloudflare) DeleteRecord(zone string, rec Record) error {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return err
+	}
+	if _, err := p.cfRequest("DELETE", "/zones/"+zoneID+"/dns_records/"+rec.ID, nil, nil); err != nil {
+		return fmt.Errorf("删除 Cloudflare 解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *Cloudflare) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *Cloudflare) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
+
+// cfRecordData 将区域文件格式的 SRV/HTTPS/SVCB 记录值转换为 Cloudflare 的 data 结构
+// SRV:        "优先级 权重 端口 目标主机"，如 "0 5 25565 mc.example.com"
+// HTTPS/SVCB: "优先级 目标 参数..."，如 "1 . port=8443 ipv4hint=1.2.3.4"
+// 其他类型返回 nil，使用 content 字段提交
+func cfRecordData(recordType, value string) (map[string]interface{}, error) {
+	fields := strings.Fields(value)
+	switch strings.ToUpper(recordType) {
+	case "SRV":
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("SRV 记录值格式错误: %s", value)
+		}
+		nums := make([]int, 3)
+		for i := 0; i < 3; i++ {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("SRV 记录值格式错误: %s", value)
+			}
+			nums[i] = v
+		}
+		return map[string]interface{}{
+			"priority": nums[0],
+			"weight":   nums[1],
+			"port":     nums[2],
+			"target":   fields[3],
+		}, nil
+	case "HTTPS", "SVCB":
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s 记录值格式错误: %s", recordType, value)
+		}
+		priority, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s 记录值格式错误: %s", recordType, value)
+		}
+		return map[string]interface{}{
+			"priority": priority,
+			"target":   fields[1],
+			"value":    strings.Join(fields[2:], " "),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,228 @@
+package dnsprovider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== DNS.LA =====
+
+// Dnsla DNS.LA 服务商
+type Dnsla struct {
+	APIID     string
+	APISecret string
+}
+
+func newDnsla(acc model.DomainAccount) Provider {
+	return &Dnsla{APIID: acc.AccessID, APISecret: acc.AccessSecret}
+}
+
+const dnslaEndpoint = "https://api.dns.la/api"
+
+// dnslaTypes DNS.LA 使用数字表示记录类型
+var dnslaTypes = map[string]int{
+	"A": 1, "NS": 2, "CNAME": 5, "MX": 15, "TXT": 16, "AAAA": 28, "SRV": 33, "CAA": 257,
+}
+
+// dnslaTypeName 数字记录类型转为名称
+func dnslaTypeName(t int) string {
+	for name, v := range dnslaTypes {
+		if v == t {
+			return name
+		}
+	}
+	return strconv.Itoa(t)
+}
+
+type dnslaRecord struct {
+	ID   string `json:"id"`
+	Host string `json:"host"`
+	Type int    `json:"type"`
+	Data string `json:"data"`
+	TTL  int    `json:"ttl"`
+}
+
+// dlRequest 调用 DNS.LA API，响应 code 为 200 表示成功
+func (p *Dnsla) dlRequest(method, path string, payload, out interface{}) error {
+	headers := map[string]string{
+		"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(p.APIID+":"+p.APISecret)),
+	}
+	body, err := httpJSON(method, dnslaEndpoint+path, headers, payload)
+	var result struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(body, &result); jsonErr != nil {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("解析 DNS.LA 响应失败: %w", jsonErr)
+	}
+	if result.Code != 200 {
+		return fmt.Errorf("DNS.LA API 错误 %d: %s", result.Code, result.Msg)
+	}
+	if out != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("解析 DNS.LA 响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// getDomainID 查询域名 ID
+func (p *Dnsla) getDomainID(zone string) (string, error) {
+	var data struct {
+		ID string `json:"id"`
+	}
+	if err := p.dlRequest("GET", "/domain?domain="+url.QueryEscape(zone), nil, &data); err != nil {
+		return "", fmt.Errorf("查询 DNS.LA 域名失败: %w", err)
+	}
+	if data.ID == "" {
+		return "", fmt.Errorf("未找到域名 %s 对应的 DNS.LA 域名", zone)
+	}
+	return data.ID, nil
+}
+
+// listRecords 分页查询记录，host 与 recordType 为空时返回全部记录
+func (p *Dnsla) listRecords(zone, host, recordType string) ([]Record, error) {
+	domainID, err := p.getDomainID(zone)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for page := 1; ; page++ {
+		q := url.Values{
+			"domainId":  {domainID},
+			"pageIndex": {strconv.Itoa(page)},
+			"pageSize":  {"100"},
+		}
+		if host != "" {
+			q.Set("host", host)
+		}
+		if recordType != "" {
+			q.Set("type", strconv.Itoa(dnslaTypes[strings.ToUpper(recordType)]))
+		}
+		var data struct {
+			Total   int           `json:"total"`
+			Results []dnslaRecord `json:"results"`
+		}
+		if err := p.dlRequest("GET", "/recordList?"+q.Encode(), nil, &data); err != nil {
+			return nil, fmt.Errorf("查询 DNS.LA 记录失败: %w", err)
+		}
+		for _, r := range data.Results {
+			h := r.Host
+			if h == "" {
+				h = "@"
+			}
+			records = append(records, Record{ID: r.ID, Type: dnslaTypeName(r.Type), Host: h, Value: r.Data, TTL: r.TTL})
+		}
+		if len(data.Results) == 0 || len(records) >= data.Total {
+			return records, nil
+		}
+	}
+}
+
+// dnslaPayload 新增/修改记录的请求参数
+func dnslaPayload(rec Record) (map[string]interface{}, error) {
+	t, ok := dnslaTypes[strings.ToUpper(rec.Type)]
+	if !ok {
+		return nil, fmt.Errorf("DNS.LA 不支持 %s 记录", rec.Type)
+	}
+	return map[string]interface{}{
+		"type": t,
+		"host": rec.Host,
+		"data": rec.Value,
+		"ttl":  ttlOr(rec.TTL, 600, 1),
+	}, nil
+}
+
+// ListDomains 列出账号下的域名
+func (p *Dnsla) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for page := 1; ; page++ {
+		var data struct {
+			Total   int `json:"total"`
+			Results []struct {
+				ID     string `json:"id"`
+				Domain string `json:"domain"`
+			} `json:"results"`
+		}
+		if err := p.dlRequest("GET", fmt.Sprintf("/domainList?pageIndex=%d&pageSize=100", page), nil, &data); err != nil {
+			return nil, fmt.Errorf("查询 DNS.LA 域名失败: %w", err)
+		}
+		for _, d := range data.Results {
+			domains = append(domains, Domain{Name: strings.TrimSuffix(d.Domain, "."), ID: d.ID})
+		}
+		if len(data.Results) == 0 || len(domains) >= data.Total {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *Dnsla) ListRecords(zone string) ([]Record, error) {
+	return p.listRecords(zone, "", "")
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录（host 参数为模糊匹配，需精确过滤）
+func (p *Dnsla) FindRecords(zone, host, recordType string) ([]Record, error) {
+	list, err := p.listRecords(zone, host, recordType)
+	if err != nil {
+		return nil, err
+	}
+	return filterRecords(list, host, recordType), nil
+}
+
+// CreateRecord 新增解析记录
+func (p *Dnsla) CreateRecord(zone string, rec Record) (string, error) {
+	payload, err := dnslaPayload(rec)
+	if err != nil {
+		return "", err
+	}
+	domainID, err := p.getDomainID(zone)
+	if err != nil {
+		return "", err
+	}
+	payload["domainId"] = domainID
+	var data struct {
+		ID string `json:"id"`
+	}
+	if err := p.dlRequest("POST", "/record", payload, &data); err != nil {
+		return "", fmt.Errorf("新增 DNS.LA 记录失败: %w", err)
+	}
+	return data.ID, nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *Dnsla) UpdateRecord(zone string, rec Record) error {
+	payload, err := dnslaPayload(rec)
+	if err != nil {
+		return err
+	}
+	payload["id"] = rec.ID
+	if err := p.dlRequest("PUT", "/record", payload, nil); err != nil {
+		return fmt.Errorf("修改 DNS.LA 记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 删除解析记录
+func (p *Dnsla) DeleteRecord(zone string, rec Record) error {
+	if err := p.dlRequest("DELETE", "/record?id="+url.QueryEscape(rec.ID), nil, nil); err != nil {
+		return fmt.Errorf("删除 DNS.LA 记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *Dnsla) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *Dnsla) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
@@ -0,0 +1,249 @@
+package dnsprovider
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== 腾讯云 API v3 =====
+
+// tcAPIError 腾讯云 API 错误
+type tcAPIError struct {
+	Code    string
+	Message string
+}
+
+func (e *tcAPIError) Error() string {
+	return fmt.Sprintf("腾讯云 API 错误 %s: %s", e.Code, e.Message)
+}
+
+// tc3Request 以 TC3-HMAC-SHA256 签名调用腾讯云 API，Response 字段解析到 out
+func tc3Request(secretID, secretKey, service, version, action string, payload, out interface{}) error {
+	host := service + ".tencentcloudapi.com"
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+	timestamp := strconv.FormatInt(ts, 10)
+	date := time.Unix(ts, 0).UTC().Format("2006-01-02")
+
+	// 规范请求串
+	canonicalRequest := strings.Join([]string{
+		"POST", "/", "",
+		"content-type:application/json; charset=utf-8\nhost:" + host + "\n",
+		"content-type;host",
+		hashSHA256(string(data)),
+	}, "\n")
+	// 待签名字符串
+	credentialScope := date + "/" + service + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		"TC3-HMAC-SHA256", timestamp, credentialScope, hashSHA256(canonicalRequest),
+	}, "\n")
+	// 计算签名
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	req, err := http.NewRequest("POST", "https://"+host, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		secretID, credentialScope, signature,
+	))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Host", host)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Timestamp", timestamp)
+	req.Header.Set("X-TC-Version", version)
+	body, err := doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("解析腾讯云响应失败: %w", err)
+	}
+	var errResp struct {
+		Error *tcAPIError `json:"Error"`
+	}
+	if err := json.Unmarshal(result.Response, &errResp); err == nil && errResp.Error != nil {
+		return errResp.Error
+	}
+	if out != nil {
+		if err := json.Unmarshal(result.Response, out); err != nil {
+			return fmt.Errorf("解析腾讯云响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ===== DNSPod（腾讯云）=====
+
+// Dnspod DNSPod 服务商
+type Dnspod struct {
+	SecretID  string
+	SecretKey string
+}
+
+func newDnspod(acc model.DomainAccount) Provider {
+	return &Dnspod{SecretID: acc.AccessID, SecretKey: acc.AccessSecret}
+}
+
+func (p *Dnspod) request(action string, payload, out interface{}) error {
+	return tc3Request(p.SecretID, p.SecretKey, "dnspod", "2021-03-23", action, payload, out)
+}
+
+// describeRecords 分页查询解析记录，extra 为附加的过滤参数
+func (p *Dnspod) describeRecords(zone string, extra map[string]interface{}) ([]Record, error) {
+	var records []Record
+	for offset := 0; ; offset += 3000 {
+		payload := map[string]interface{}{
+			"Domain": zone,
+			"Offset": offset,
+			"Limit":  3000,
+		}
+		for k, v := range extra {
+			payload[k] = v
+		}
+		var result struct {
+			RecordCountInfo struct {
+				TotalCount int `json:"TotalCount"`
+			} `json:"RecordCountInfo"`
+			RecordList []struct {
+				RecordId uint64 `json:"RecordId"`
+				Name     string `json:"Name"`
+				Type     string `json:"Type"`
+				Value    string `json:"Value"`
+				TTL      int    `json:"TTL"`
+			} `json:"RecordList"`
+		}
+		if err := p.request("DescribeRecordList", payload, &result); err != nil {
+			// 无匹配记录时 DNSPod 返回错误码而非空列表
+			if e, ok := err.(*tcAPIError); ok && e.Code == "ResourceNotFound.NoDataOfRecord" {
+				return records, nil
+			}
+			return nil, fmt.Errorf("获取 DNSPod 解析记录失败: %w", err)
+		}
+		for _, r := range result.RecordList {
+			records = append(records, Record{
+				ID:    strconv.FormatUint(r.RecordId, 10),
+				Type:  r.Type,
+				Host:  r.Name,
+				Value: r.Value,
+				TTL:   r.TTL,
+			})
+		}
+		if len(result.RecordList) == 0 || len(records) >= result.RecordCountInfo.TotalCount {
+			return records, nil
+		}
+	}
+}
+
+// ListDomains 列出账号下的域名
+func (p *Dnspod) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for offset := 0; ; offset += 3000 {
+		var result struct {
+			DomainCountInfo struct {
+				AllTotal int `json:"AllTotal"`
+			} `json:"DomainCountInfo"`
+			DomainList []struct {
+				DomainId uint64 `json:"DomainId"`
+				Name     string `json:"Name"`
+			} `json:"DomainList"`
+		}
+		if err := p.request("DescribeDomainList", map[string]interface{}{"Offset": offset, "Limit": 3000}, &result); err != nil {
+			return nil, fmt.Errorf("获取 DNSPod 域名列表失败: %w", err)
+		}
+		for _, d := range result.DomainList {
+			domains = append(domains, Domain{Name: d.Name, ID: strconv.FormatUint(d.DomainId, 10)})
+		}
+		if len(result.DomainList) == 0 || len(domains) >= result.DomainCountInfo.AllTotal {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *Dnspod) ListRecords(zone string) ([]Record, error) {
+	return p.describeRecords(zone, nil)
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *Dnspod) FindRecords(zone, host, recordType string) ([]Record, error) {
+	list, err := p.describeRecords(zone, map[string]interface{}{"Subdomain": host, "RecordType": recordType})
+	if err != nil {
+		return nil, err
+	}
+	return filterRecords(list, host, recordType), nil
+}
+
+// recordPayload 新增/修改记录的请求参数
+func (p *Dnspod) recordPayload(zone string, rec Record) map[string]interface{} {
+	return map[string]interface{}{
+		"Domain":     zone,
+		"SubDomain":  rec.Host,
+		"RecordType": rec.Type,
+		"RecordLine": "默认",
+		"Value":      rec.Value,
+		"TTL":        ttlOr(rec.TTL, 600, 1),
+	}
+}
+
+// CreateRecord 新增解析记录
+func (p *Dnspod) CreateRecord(zone string, rec Record) (string, error) {
+	var result struct {
+		RecordId uint64 `json:"RecordId"`
+	}
+	if err := p.request("CreateRecord", p.recordPayload(zone, rec), &result); err != nil {
+		return "", fmt.Errorf("新增 DNSPod 解析记录失败: %w", err)
+	}
+	return strconv.FormatUint(result.RecordId, 10), nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *Dnspod) UpdateRecord(zone string, rec Record) error {
+	id, err := strconv.ParseUint(rec.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("DNSPod 记录 ID 无效: %s", rec.ID)
+	}
+	payload := p.recordPayload(zone, rec)
+	payload["RecordId"] = id
+	if err := p.request("ModifyRecord", payload, nil); err != nil {
+		return fmt.Errorf("修改 DNSPod 解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 删除解析记录
+func (p *Dnspod) DeleteRecord(zone string, rec Record) error {
+	id, err := strconv.ParseUint(rec.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("DNSPod 记录 ID 无效: %s", rec.ID)
+	}
+	if err := p.request("DeleteRecord", map[string]interface{}{"Domain": zone, "RecordId": id}, nil); err != nil {
+		return fmt.Errorf("删除 DNSPod 解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *Dnspod) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *Dnspod) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
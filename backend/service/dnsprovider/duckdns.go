@@ -0,0 +1,110 @@
+package dnsprovider
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== DuckDNS =====
+
+// DuckDNS DuckDNS 服务商：仅支持 duckdns.org 子域名的 A/AAAA/TXT 记录，无记录列表接口
+type DuckDNS struct {
+	Token string
+}
+
+func newDuckDNS(acc model.DomainAccount) Provider {
+	return &DuckDNS{Token: acc.AccessSecret}
+}
+
+const duckdnsEndpoint = "https://www.duckdns.org/update"
+
+// duckdnsSubdomain 从完整域名中取出 DuckDNS 子域名，如 _acme-challenge.foo.duckdns.org -> foo
+func duckdnsSubdomain(fqdn string) (string, error) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSuffix(fqdn, ".")), ".duckdns.org")
+	if name == "" || name == "duckdns.org" {
+		return "", fmt.Errorf("无效的 DuckDNS 域名: %s", fqdn)
+	}
+	parts := strings.Split(name, ".")
+	return parts[len(parts)-1], nil
+}
+
+// duckRequest 调用 DuckDNS 更新接口，响应以 OK 开头表示成功
+func (p *DuckDNS) duckRequest(params url.Values) error {
+	params.Set("token", p.Token)
+	body, err := httpGet(duckdnsEndpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(body)), "OK") {
+		return fmt.Errorf("DuckDNS 返回失败，请检查 Token 与域名")
+	}
+	return nil
+}
+
+// SetRecord 更新 A/AAAA/TXT 记录（DuckDNS 不支持自定义 TTL）
+func (p *DuckDNS) SetRecord(fqdn, recordType, value string, ttl int) error {
+	sub, err := duckdnsSubdomain(fqdn)
+	if err != nil {
+		return err
+	}
+	params := url.Values{"domains": {sub}}
+	switch strings.ToUpper(recordType) {
+	case "A":
+		params.Set("ip", value)
+	case "AAAA":
+		params.Set("ipv6", value)
+	case "TXT":
+		params.Set("txt", value)
+	default:
+		return fmt.Errorf("DuckDNS 不支持 %s 记录", recordType)
+	}
+	if err := p.duckRequest(params); err != nil {
+		return fmt.Errorf("更新 DuckDNS 记录失败: %w", err)
+	}
+	return nil
+}
+
+// ListDomains DuckDNS 无域名列表接口
+func (p *DuckDNS) ListDomains() ([]Domain, error) { return nil, ErrNotSupported }
+
+// ListRecords DuckDNS 无记录列表接口
+func (p *DuckDNS) ListRecords(zone string) ([]Record, error) { return nil, ErrNotSupported }
+
+// FindRecords DuckDNS 无记录查询接口
+func (p *DuckDNS) FindRecords(zone, host, recordType string) ([]Record, error) {
+	return nil, ErrNotSupported
+}
+
+// CreateRecord 等同于 SetRecord，DuckDNS 记录无 ID
+func (p *DuckDNS) CreateRecord(zone string, rec Record) (string, error) {
+	return "", p.SetRecord(JoinHost(rec.Host, zone), rec.Type, rec.Value, rec.TTL)
+}
+
+// UpdateRecord 等同于 SetRecord
+func (p *DuckDNS) UpdateRecord(zone string, rec Record) error {
+	return p.SetRecord(JoinHost(rec.Host, zone), rec.Type, rec.Value, rec.TTL)
+}
+
+// DeleteRecord DuckDNS 不支持删除 A/AAAA 记录
+func (p *DuckDNS) DeleteRecord(zone string, rec Record) error { return ErrNotSupported }
+
+// PresentTXT 设置 TXT 记录（每个子域名只有一条 TXT 记录）
+func (p *DuckDNS) PresentTXT(fqdn, value string) error {
+	sub, err := duckdnsSubdomain(fqdn)
+	if err != nil {
+		return err
+	}
+	return p.duckRequest(url.Values{"domains": {sub}, "txt": {value}, "verbose": {"true"}})
+}
+
+// CleanupTXT 清除 TXT 记录
+func (p *DuckDNS) CleanupTXT(fqdn, value string) error {
+	sub, err := duckdnsSubdomain(fqdn)
+	if err != nil {
+		return err
+	}
+	return p.duckRequest(url.Values{"domains": {sub}, "txt": {""}, "clear": {"true"}})
+}
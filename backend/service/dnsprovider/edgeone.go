@@ -0,0 +1,200 @@
+package dnsprovider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== 腾讯云 EdgeOne =====
+
+// EdgeOne 腾讯云 EdgeOne（边缘安全加速平台）DNS 服务商
+type EdgeOne struct {
+	SecretID  string
+	SecretKey string
+}
+
+func newEdgeOne(acc model.DomainAccount) Provider {
+	return &EdgeOne{SecretID: acc.AccessID, SecretKey: acc.AccessSecret}
+}
+
+func (p *EdgeOne) request(action string, payload, out interface{}) error {
+	return tc3Request(p.SecretID, p.SecretKey, "teo", "2022-09-01", action, payload, out)
+}
+
+type teoFilter struct {
+	Name   string   `json:"Name"`
+	Values []string `json:"Values"`
+}
+
+type teoRecord struct {
+	RecordId string `json:"RecordId"`
+	Name     string `json:"Name"`
+	Type     string `json:"Type"`
+	Content  string `json:"Content"`
+	TTL      int    `json:"TTL"`
+}
+
+// getZoneID 查询站点对应的 ZoneId
+func (p *EdgeOne) getZoneID(zone string) (string, error) {
+	var result struct {
+		Zones []struct {
+			ZoneId   string `json:"ZoneId"`
+			ZoneName string `json:"ZoneName"`
+		} `json:"Zones"`
+	}
+	err := p.request("DescribeZones", map[string]interface{}{
+		"Filters": []teoFilter{{Name: "zone-name", Values: []string{zone}}},
+	}, &result)
+	if err != nil {
+		return "", fmt.Errorf("查询 EdgeOne 站点失败: %w", err)
+	}
+	for _, z := range result.Zones {
+		if strings.EqualFold(z.ZoneName, zone) {
+			return z.ZoneId, nil
+		}
+	}
+	return "", fmt.Errorf("未找到域名 %s 对应的 EdgeOne 站点", zone)
+}
+
+// describeRecords 分页查询 DNS 记录，filters 为空时返回全部记录
+func (p *EdgeOne) describeRecords(zone string, filters []teoFilter) ([]Record, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for offset := 0; ; offset += 1000 {
+		payload := map[string]interface{}{
+			"ZoneId": zoneID,
+			"Offset": offset,
+			"Limit":  1000,
+		}
+		if len(filters) > 0 {
+			payload["Filters"] = filters
+		}
+		var result struct {
+			TotalCount int         `json:"TotalCount"`
+			DnsRecords []teoRecord `json:"DnsRecords"`
+		}
+		if err := p.request("DescribeDnsRecords", payload, &result); err != nil {
+			return nil, fmt.Errorf("查询 EdgeOne DNS 记录失败: %w", err)
+		}
+		for _, r := range result.DnsRecords {
+			records = append(records, Record{
+				ID:    r.RecordId,
+				Type:  r.Type,
+				Host:  RelativeHost(r.Name, zone),
+				Value: r.Content,
+				TTL:   r.TTL,
+			})
+		}
+		if len(result.DnsRecords) == 0 || len(records) >= result.TotalCount {
+			return records, nil
+		}
+	}
+}
+
+// ListDomains 列出账号下的站点
+func (p *EdgeOne) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for offset := 0; ; offset += 100 {
+		var result struct {
+			TotalCount int `json:"TotalCount"`
+			Zones      []struct {
+				ZoneId   string `json:"ZoneId"`
+				ZoneName string `json:"ZoneName"`
+			} `json:"Zones"`
+		}
+		if err := p.request("DescribeZones", map[string]interface{}{"Offset": offset, "Limit": 100}, &result); err != nil {
+			return nil, fmt.Errorf("查询 EdgeOne 站点失败: %w", err)
+		}
+		for _, z := range result.Zones {
+			domains = append(domains, Domain{Name: z.ZoneName, ID: z.ZoneId})
+		}
+		if len(result.Zones) == 0 || len(domains) >= result.TotalCount {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出站点下的 DNS 记录
+func (p *EdgeOne) ListRecords(zone string) ([]Record, error) {
+	return p.describeRecords(zone, nil)
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *EdgeOne) FindRecords(zone, host, recordType string) ([]Record, error) {
+	list, err := p.describeRecords(zone, []teoFilter{
+		{Name: "name", Values: []string{JoinHost(host, zone)}},
+		{Name: "type", Values: []string{recordType}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filterRecords(list, host, recordType), nil
+}
+
+// CreateRecord 新增解析记录
+func (p *EdgeOne) CreateRecord(zone string, rec Record) (string, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		RecordId string `json:"RecordId"`
+	}
+	if err := p.request("CreateDnsRecord", map[string]interface{}{
+		"ZoneId":  zoneID,
+		"Name":    JoinHost(rec.Host, zone),
+		"Type":    rec.Type,
+		"Content": rec.Value,
+		"TTL":     ttlOr(rec.TTL, 300, 60),
+	}, &result); err != nil {
+		return "", fmt.Errorf("新增 EdgeOne DNS 记录失败: %w", err)
+	}
+	return result.RecordId, nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *EdgeOne) UpdateRecord(zone string, rec Record) error {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return err
+	}
+	if err := p.request("ModifyDnsRecords", map[string]interface{}{
+		"ZoneId": zoneID,
+		"DnsRecords": []teoRecord{{
+			RecordId: rec.ID,
+			Name:     JoinHost(rec.Host, zone),
+			Type:     rec.Type,
+			Content:  rec.Value,
+			TTL:      ttlOr(rec.TTL, 300, 60),
+		}},
+	}, nil); err != nil {
+		return fmt.Errorf("修改 EdgeOne DNS 记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 删除解析记录
+func (p *EdgeOne) DeleteRecord(zone string, rec Record) error {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return err
+	}
+	if err := p.request("DeleteDnsRecords", map[string]interface{}{
+		"ZoneId":    zoneID,
+		"RecordIds": []string{rec.ID},
+	}, nil); err != nil {
+		return fmt.Errorf("删除 EdgeOne DNS 记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *EdgeOne) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *EdgeOne) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
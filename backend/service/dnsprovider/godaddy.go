@@ -0,0 +1,233 @@
+package dnsprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== GoDaddy =====
+
+// GoDaddy GoDaddy DNS 服务商
+// GoDaddy 记录无 ID，以 "类型/主机记录/值" 标识单条记录
+type GoDaddy struct {
+	APIKey    string
+	APISecret string
+}
+
+func newGoDaddy(acc model.DomainAccount) Provider {
+	return &GoDaddy{APIKey: acc.AccessID, APISecret: acc.AccessSecret}
+}
+
+const godaddyEndpoint = "https://api.godaddy.com/v1"
+
+// godaddyRecord GoDaddy 记录；MX/SRV 的附加字段需原样回写，否则整体替换记录集时会被清空
+type godaddyRecord struct {
+	Type     string `json:"type,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Data     string `json:"data"`
+	TTL      int    `json:"ttl"`
+	Priority *int   `json:"priority,omitempty"`
+	Weight   *int   `json:"weight,omitempty"`
+	Port     *int   `json:"port,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+func (p *GoDaddy) gdHeaders() map[string]string {
+	return map[string]string{
+		"Authorization": "sso-key " + p.APIKey + ":" + p.APISecret,
+		"Accept":        "application/json",
+	}
+}
+
+// rrsetURL 某个主机记录与类型的记录集地址
+func (p *GoDaddy) rrsetURL(zone, recordType, host string) string {
+	return fmt.Sprintf("%s/domains/%s/records/%s/%s",
+		godaddyEndpoint, url.PathEscape(zone), recordType, url.PathEscape(host))
+}
+
+// getRRSet 查询记录集
+func (p *GoDaddy) getRRSet(zone, recordType, host string) ([]godaddyRecord, error) {
+	body, err := httpGet(p.rrsetURL(zone, recordType, host), p.gdHeaders())
+	if err != nil {
+		return nil, fmt.Errorf("查询 GoDaddy 记录失败: %w", err)
+	}
+	var records []godaddyRecord
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, fmt.Errorf("解析 GoDaddy 响应失败: %w", err)
+	}
+	return records, nil
+}
+
+// putRRSet 以给定值整体替换记录集，值为空时删除记录集
+func (p *GoDaddy) putRRSet(zone, recordType, host string, records []godaddyRecord) error {
+	if len(records) == 0 {
+		_, err := httpJSON("DELETE", p.rrsetURL(zone, recordType, host), p.gdHeaders(), nil)
+		return err
+	}
+	payload := make([]godaddyRecord, 0, len(records))
+	for _, r := range records {
+		// 记录集地址已指定类型与主机记录，请求体中不能再携带
+		r.Type, r.Name = "", ""
+		payload = append(payload, r)
+	}
+	_, err := httpJSON("PUT", p.rrsetURL(zone, recordType, host), p.gdHeaders(), payload)
+	return err
+}
+
+func godaddyID(recordType, host, value string) string {
+	return recordType + "/" + host + "/" + value
+}
+
+// ListDomains 列出账号下的有效域名
+func (p *GoDaddy) ListDomains() ([]Domain, error) {
+	body, err := httpGet(godaddyEndpoint+"/domains?statuses=ACTIVE&limit=1000", p.gdHeaders())
+	if err != nil {
+		return nil, fmt.Errorf("查询 GoDaddy 域名失败: %w", err)
+	}
+	var result []struct {
+		DomainID int64  `json:"domainId"`
+		Domain   string `json:"domain"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析 GoDaddy 响应失败: %w", err)
+	}
+	domains := make([]Domain, 0, len(result))
+	for _, d := range result {
+		domains = append(domains, Domain{Name: d.Domain, ID: fmt.Sprint(d.DomainID)})
+	}
+	return domains, nil
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *GoDaddy) ListRecords(zone string) ([]Record, error) {
+	body, err := httpGet(godaddyEndpoint+"/domains/"+url.PathEscape(zone)+"/records", p.gdHeaders())
+	if err != nil {
+		return nil, fmt.Errorf("查询 GoDaddy 记录失败: %w", err)
+	}
+	var list []godaddyRecord
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("解析 GoDaddy 响应失败: %w", err)
+	}
+	records := make([]Record, 0, len(list))
+	for _, r := range list {
+		records = append(records, Record{
+			ID:    godaddyID(r.Type, r.Name, r.Data),
+			Type:  r.Type,
+			Host:  r.Name,
+			Value: r.Data,
+			TTL:   r.TTL,
+		})
+	}
+	return records, nil
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *GoDaddy) FindRecords(zone, host, recordType string) ([]Record, error) {
+	list, err := p.getRRSet(zone, recordType, host)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(list))
+	for _, r := range list {
+		records = append(records, Record{
+			ID:    godaddyID(recordType, host, r.Data),
+			Type:  recordType,
+			Host:  host,
+			Value: r.Data,
+			TTL:   r.TTL,
+		})
+	}
+	return records, nil
+}
+
+// CreateRecord 在记录集中追加值（GoDaddy 最小 TTL 为 600）
+func (p *GoDaddy) CreateRecord(zone string, rec Record) (string, error) {
+	existing, err := p.getRRSet(zone, rec.Type, rec.Host)
+	if err != nil {
+		return "", err
+	}
+	id := godaddyID(rec.Type, rec.Host, rec.Value)
+	for _, r := range existing {
+		if r.Data == rec.Value {
+			return id, nil
+		}
+	}
+	existing = append(existing, godaddyRecord{Data: rec.Value, TTL: ttlOr(rec.TTL, 600, 600)})
+	if err := p.putRRSet(zone, rec.Type, rec.Host, existing); err != nil {
+		return "", fmt.Errorf("新增 GoDaddy 记录失败: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateRecord 修改解析记录：主机记录与类型未变时在原记录集内替换值，否则移动到新记录集
+func (p *GoDaddy) UpdateRecord(zone string, rec Record) error {
+	parts := strings.SplitN(rec.ID, "/", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("GoDaddy 记录 ID 无效: %s", rec.ID)
+	}
+	oldType, oldHost, oldValue := parts[0], parts[1], parts[2]
+	if !strings.EqualFold(oldType, rec.Type) || !strings.EqualFold(oldHost, rec.Host) {
+		if err := p.DeleteRecord(zone, Record{ID: rec.ID}); err != nil {
+			return err
+		}
+		_, err := p.CreateRecord(zone, rec)
+		return err
+	}
+	existing, err := p.getRRSet(zone, rec.Type, rec.Host)
+	if err != nil {
+		return err
+	}
+	ttl := ttlOr(rec.TTL, 600, 600)
+	replaced := false
+	for i := range existing {
+		if existing[i].Data == oldValue {
+			existing[i].Data = rec.Value
+			existing[i].TTL = ttl
+			replaced = true
+		}
+	}
+	if !replaced {
+		existing = append(existing, godaddyRecord{Data: rec.Value, TTL: ttl})
+	}
+	if err := p.putRRSet(zone, rec.Type, rec.Host, existing); err != nil {
+		return fmt.Errorf("修改 GoDaddy 记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 从记录集中删除值，记录集为空时删除整个记录集
+func (p *GoDaddy) DeleteRecord(zone string, rec Record) error {
+	parts := strings.SplitN(rec.ID, "/", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("GoDaddy 记录 ID 无效: %s", rec.ID)
+	}
+	recordType, host, value := parts[0], parts[1], parts[2]
+	existing, err := p.getRRSet(zone, recordType, host)
+	if err != nil {
+		return err
+	}
+	var remain []godaddyRecord
+	for _, r := range existing {
+		if r.Data != value {
+			remain = append(remain, r)
+		}
+	}
+	if len(remain) == len(existing) {
+		return nil
+	}
+	if err := p.putRRSet(zone, recordType, host, remain); err != nil {
+		return fmt.Errorf("删除 GoDaddy 记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *GoDaddy) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *GoDaddy) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
@@ -0,0 +1,358 @@
+package dnsprovider
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== 华为云 DNS =====
+
+// HuaweiCloud 华为云 DNS 服务商（AK/SK 签名）
+// 华为云以记录集（同名同类型的多个值）为单位管理解析，记录 ID 形如 "记录集ID/值"
+type HuaweiCloud struct {
+	AccessKey string
+	SecretKey string
+}
+
+func newHuaweiCloud(acc model.DomainAccount) Provider {
+	return &HuaweiCloud{AccessKey: acc.AccessID, SecretKey: acc.AccessSecret}
+}
+
+const huaweiDNSHost = "dns.myhuaweicloud.com"
+
+// hwRequest 发送华为云 API 请求（SDK-HMAC-SHA256 签名）
+func (p *HuaweiCloud) hwRequest(method, path string, query url.Values, payload, out interface{}) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+	sdkDate := time.Now().UTC().Format("20060102T150405Z")
+
+	// 规范查询串：参数名排序后编码
+	var qs []string
+	for _, k := range sortedKeys(query) {
+		for _, v := range query[k] {
+			qs = append(qs, hwEscape(k)+"="+hwEscape(v))
+		}
+	}
+	canonicalQuery := strings.Join(qs, "&")
+
+	// 规范 URI 需以 / 结尾
+	canonicalURI := path
+	if !strings.HasSuffix(canonicalURI, "/") {
+		canonicalURI += "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		"content-type:application/json\nhost:" + huaweiDNSHost + "\nx-sdk-date:" + sdkDate + "\n",
+		"content-type;host;x-sdk-date",
+		hashSHA256(string(body)),
+	}, "\n")
+	stringToSign := "SDK-HMAC-SHA256\n" + sdkDate + "\n" + hashSHA256(canonicalRequest)
+	signature := hex.EncodeToString(hmacSHA256([]byte(p.SecretKey), stringToSign))
+
+	reqURL := "https://" + huaweiDNSHost + path
+	if canonicalQuery != "" {
+		reqURL += "?" + canonicalQuery
+	}
+	req, err := http.NewRequest(method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sdk-Date", sdkDate)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"SDK-HMAC-SHA256 Access=%s, SignedHeaders=content-type;host;x-sdk-date, Signature=%s",
+		p.AccessKey, signature,
+	))
+	respBody, err := doRequest(req)
+	if err != nil {
+		var apiErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("华为云 API 错误 %s: %s", apiErr.Code, apiErr.Message)
+		}
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("解析华为云 DNS 响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// hwEscape 按华为云签名要求进行 URI 编码（空格编码为 %20）
+func hwEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+type hwRecordset struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int      `json:"ttl"`
+	Records []string `json:"records"`
+}
+
+// hwValue 华为云 TXT 记录值需带双引号
+func hwValue(recordType, value string) string {
+	if strings.EqualFold(recordType, "TXT") && !strings.HasPrefix(value, `"`) {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// getZoneID 查询公网域名对应的 Zone ID
+func (p *HuaweiCloud) getZoneID(zone string) (string, error) {
+	var result struct {
+		Zones []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"zones"`
+	}
+	if err := p.hwRequest("GET", "/v2/zones", url.Values{"type": {"public"}, "name": {zone + "."}}, nil, &result); err != nil {
+		return "", fmt.Errorf("查询华为云 DNS 域名失败: %w", err)
+	}
+	for _, z := range result.Zones {
+		if strings.EqualFold(strings.TrimSuffix(z.Name, "."), zone) {
+			return z.ID, nil
+		}
+	}
+	return "", fmt.Errorf("未找到域名 %s 对应的华为云 DNS 公网域名", zone)
+}
+
+// listRecordsets 分页查询记录集，query 为附加的过滤参数
+func (p *HuaweiCloud) listRecordsets(zoneID string, query url.Values) ([]hwRecordset, error) {
+	var sets []hwRecordset
+	for offset := 0; ; offset += 500 {
+		q := url.Values{"limit": {"500"}, "offset": {fmt.Sprint(offset)}}
+		for k, v := range query {
+			q[k] = v
+		}
+		var result struct {
+			Recordsets []hwRecordset `json:"recordsets"`
+		}
+		if err := p.hwRequest("GET", "/v2.1/zones/"+zoneID+"/recordsets", q, nil, &result); err != nil {
+			return nil, fmt.Errorf("查询华为云 DNS 记录失败: %w", err)
+		}
+		sets = append(sets, result.Recordsets...)
+		if len(result.Recordsets) < 500 {
+			return sets, nil
+		}
+	}
+}
+
+// expandRecordsets 将记录集中的每个值展开为一条记录
+func expandRecordsets(sets []hwRecordset, zone string) []Record {
+	var records []Record
+	for _, rs := range sets {
+		for _, v := range rs.Records {
+			records = append(records, Record{
+				ID:    rs.ID + "/" + v,
+				Type:  rs.Type,
+				Host:  RelativeHost(rs.Name, zone),
+				Value: v,
+				TTL:   rs.TTL,
+			})
+		}
+	}
+	return records
+}
+
+// ListDomains 列出账号下的公网域名
+func (p *HuaweiCloud) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for offset := 0; ; offset += 500 {
+		var result struct {
+			Zones []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"zones"`
+		}
+		if err := p.hwRequest("GET", "/v2/zones", url.Values{
+			"type":   {"public"},
+			"limit":  {"500"},
+			"offset": {fmt.Sprint(offset)},
+		}, nil, &result); err != nil {
+			return nil, fmt.Errorf("查询华为云 DNS 域名失败: %w", err)
+		}
+		for _, z := range result.Zones {
+			domains = append(domains, Domain{Name: strings.TrimSuffix(z.Name, "."), ID: z.ID})
+		}
+		if len(result.Zones) < 500 {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *HuaweiCloud) ListRecords(zone string) ([]Record, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := p.listRecordsets(zoneID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return expandRecordsets(sets, zone), nil
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *HuaweiCloud) FindRecords(zone, host, recordType string) ([]Record, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := p.listRecordsets(zoneID, url.Values{"name": {JoinHost(host, zone) + "."}, "type": {recordType}})
+	if err != nil {
+		return nil, err
+	}
+	// name 参数为模糊匹配，需精确过滤
+	return filterRecords(expandRecordsets(sets, zone), host, recordType), nil
+}
+
+// getRecordset 按 ID 查询记录集
+func (p *HuaweiCloud) getRecordset(zoneID, id string) (*hwRecordset, error) {
+	var rs hwRecordset
+	if err := p.hwRequest("GET", "/v2.1/zones/"+zoneID+"/recordsets/"+id, nil, nil, &rs); err != nil {
+		return nil, fmt.Errorf("查询华为云 DNS 记录集失败: %w", err)
+	}
+	return &rs, nil
+}
+
+// putRecordset 更新记录集的值与 TTL，值为空时删除记录集
+func (p *HuaweiCloud) putRecordset(zoneID string, rs *hwRecordset) error {
+	if len(rs.Records) == 0 {
+		return p.hwRequest("DELETE", "/v2.1/zones/"+zoneID+"/recordsets/"+rs.ID, nil, nil, nil)
+	}
+	return p.hwRequest("PUT", "/v2.1/zones/"+zoneID+"/recordsets/"+rs.ID, nil, map[string]interface{}{
+		"name":    rs.Name,
+		"type":    rs.Type,
+		"ttl":     rs.TTL,
+		"records": rs.Records,
+	}, nil)
+}
+
+// CreateRecord 新增解析记录：同名同类型记录集已存在时追加值
+func (p *HuaweiCloud) CreateRecord(zone string, rec Record) (string, error) {
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return "", err
+	}
+	value := hwValue(rec.Type, rec.Value)
+	name := JoinHost(rec.Host, zone) + "."
+	sets, err := p.listRecordsets(zoneID, url.Values{"name": {name}, "type": {rec.Type}})
+	if err != nil {
+		return "", err
+	}
+	for i := range sets {
+		rs := &sets[i]
+		if !strings.EqualFold(rs.Name, name) || !strings.EqualFold(rs.Type, rec.Type) {
+			continue
+		}
+		for _, v := range rs.Records {
+			if v == value {
+				return rs.ID + "/" + value, nil
+			}
+		}
+		rs.Records = append(rs.Records, value)
+		if err := p.putRecordset(zoneID, rs); err != nil {
+			return "", fmt.Errorf("新增华为云 DNS 记录失败: %w", err)
+		}
+		return rs.ID + "/" + value, nil
+	}
+
+	var created hwRecordset
+	if err := p.hwRequest("POST", "/v2.1/zones/"+zoneID+"/recordsets", nil, map[string]interface{}{
+		"name":    name,
+		"type":    rec.Type,
+		"ttl":     ttlOr(rec.TTL, 300, 1),
+		"records": []string{value},
+	}, &created); err != nil {
+		return "", fmt.Errorf("新增华为云 DNS 记录失败: %w", err)
+	}
+	return created.ID + "/" + value, nil
+}
+
+// UpdateRecord 修改解析记录：主机记录与类型未变时在原记录集内替换值，否则移动到新记录集
+func (p *HuaweiCloud) UpdateRecord(zone string, rec Record) error {
+	setID, oldValue, _ := strings.Cut(rec.ID, "/")
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return err
+	}
+	rs, err := p.getRecordset(zoneID, setID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(RelativeHost(rs.Name, zone), rec.Host) || !strings.EqualFold(rs.Type, rec.Type) {
+		if err := p.DeleteRecord(zone, Record{ID: rec.ID}); err != nil {
+			return err
+		}
+		_, err := p.CreateRecord(zone, rec)
+		return err
+	}
+	value := hwValue(rec.Type, rec.Value)
+	replaced := false
+	for i, v := range rs.Records {
+		if v == oldValue {
+			rs.Records[i] = value
+			replaced = true
+		}
+	}
+	if !replaced {
+		rs.Records = append(rs.Records, value)
+	}
+	rs.TTL = ttlOr(rec.TTL, rs.TTL, 1)
+	if err := p.putRecordset(zoneID, rs); err != nil {
+		return fmt.Errorf("修改华为云 DNS 记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 从记录集中删除值，记录集为空时删除整个记录集
+func (p *HuaweiCloud) DeleteRecord(zone string, rec Record) error {
+	setID, value, _ := strings.Cut(rec.ID, "/")
+	zoneID, err := p.getZoneID(zone)
+	if err != nil {
+		return err
+	}
+	rs, err := p.getRecordset(zoneID, setID)
+	if err != nil {
+		return err
+	}
+	remain := rs.Records[:0]
+	for _, v := range rs.Records {
+		if v != value {
+			remain = append(remain, v)
+		}
+	}
+	rs.Records = remain
+	if err := p.putRecordset(zoneID, rs); err != nil {
+		return fmt.Errorf("删除华为云 DNS 记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *HuaweiCloud) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *HuaweiCloud) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
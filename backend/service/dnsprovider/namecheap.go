@@ -0,0 +1,272 @@
+package dnsprovider
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== Namecheap =====
+
+// Namecheap Namecheap DNS 服务商
+// Namecheap API 要求调用方 IP 在白名单中，并且只能整体替换域名的全部解析记录
+type Namecheap struct {
+	APIUser string
+	APIKey  string
+}
+
+func newNamecheap(acc model.DomainAccount) Provider {
+	return &Namecheap{APIUser: acc.AccessID, APIKey: acc.AccessSecret}
+}
+
+const namecheapEndpoint = "https://api.namecheap.com/xml.response"
+
+type namecheapHost struct {
+	HostID  string `xml:"HostId,attr"`
+	Name    string `xml:"Name,attr"`
+	Type    string `xml:"Type,attr"`
+	Address string `xml:"Address,attr"`
+	MXPref  string `xml:"MXPref,attr"`
+	TTL     int    `xml:"TTL,attr"`
+}
+
+type namecheapResponse struct {
+	Status string `xml:"Status,attr"`
+	Errors []struct {
+		Number  string `xml:"Number,attr"`
+		Message string `xml:",chardata"`
+	} `xml:"Errors>Error"`
+	HostsResult struct {
+		EmailType string          `xml:"EmailType,attr"`
+		Hosts     []namecheapHost `xml:"host"`
+	} `xml:"CommandResponse>DomainDNSGetHostsResult"`
+	Domains []struct {
+		ID   string `xml:"ID,attr"`
+		Name string `xml:"Name,attr"`
+	} `xml:"CommandResponse>DomainGetListResult>Domain"`
+	SetHosts struct {
+		IsSuccess bool `xml:"IsSuccess,attr"`
+	} `xml:"CommandResponse>DomainDNSSetHostsResult"`
+}
+
+// clientIP 获取本机公网 IPv4，作为 API 要求的 ClientIp 参数
+func (p *Namecheap) clientIP() (string, error) {
+	body, err := httpGet("https://dynamicdns.park-your-domain.com/getip", nil)
+	if err != nil {
+		return "", fmt.Errorf("获取本机公网 IP 失败: %w", err)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// ncRequest 调用 Namecheap API（POST 表单，避免 setHosts 参数过长）
+func (p *Namecheap) ncRequest(command string, params url.Values) (*namecheapResponse, error) {
+	ip, err := p.clientIP()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"ApiUser":  {p.APIUser},
+		"ApiKey":   {p.APIKey},
+		"UserName": {p.APIUser},
+		"ClientIp": {ip},
+		"Command":  {command},
+	}
+	for k, v := range params {
+		form[k] = v
+	}
+	req, err := http.NewRequest("POST", namecheapEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var result namecheapResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析 Namecheap 响应失败: %w", err)
+	}
+	if !strings.EqualFold(result.Status, "OK") {
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("Namecheap API 错误 %s: %s", result.Errors[0].Number, strings.TrimSpace(result.Errors[0].Message))
+		}
+		return nil, fmt.Errorf("Namecheap API 返回状态 %s", result.Status)
+	}
+	return &result, nil
+}
+
+// splitSLD 将根域名拆分为 SLD 与 TLD
+func splitSLD(zone string) (sld, tld string) {
+	sld, tld, _ = strings.Cut(zone, ".")
+	return sld, tld
+}
+
+// namecheapHosts 域名的全部解析记录及邮件设置
+// setHosts 会整体替换域名设置，必须原样提交 EmailType，否则 Namecheap 会丢弃自定义 MX 记录
+type namecheapHosts struct {
+	EmailType string
+	Hosts     []namecheapHost
+}
+
+// namecheapZoneLocks 按账号与域名串行化“读取-修改-整体替换”，避免并发写入（DDNS 与证书验证）互相覆盖
+var namecheapZoneLocks sync.Map // map[string]*sync.Mutex
+
+// getHosts 查询域名全部解析记录
+func (p *Namecheap) getHosts(zone string) (*namecheapHosts, error) {
+	sld, tld := splitSLD(zone)
+	result, err := p.ncRequest("namecheap.domains.dns.getHosts", url.Values{"SLD": {sld}, "TLD": {tld}})
+	if err != nil {
+		return nil, fmt.Errorf("查询 Namecheap 记录失败: %w", err)
+	}
+	return &namecheapHosts{EmailType: result.HostsResult.EmailType, Hosts: result.HostsResult.Hosts}, nil
+}
+
+// modifyHosts 在域名锁内读取全部记录，经 modify 修改后整体提交；modify 返回 false 表示无需提交
+func (p *Namecheap) modifyHosts(zone string, modify func(hosts []namecheapHost) ([]namecheapHost, bool, error)) error {
+	val, _ := namecheapZoneLocks.LoadOrStore(p.APIUser+"/"+strings.ToLower(zone), &sync.Mutex{})
+	mu := val.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	current, err := p.getHosts(zone)
+	if err != nil {
+		return err
+	}
+	hosts, changed, err := modify(current.Hosts)
+	if err != nil || !changed {
+		return err
+	}
+	return p.setHosts(zone, &namecheapHosts{EmailType: current.EmailType, Hosts: hosts})
+}
+
+// setHosts 以给定记录整体替换域名的解析记录
+func (p *Namecheap) setHosts(zone string, set *namecheapHosts) error {
+	sld, tld := splitSLD(zone)
+	params := url.Values{"SLD": {sld}, "TLD": {tld}}
+	if set.EmailType != "" {
+		params.Set("EmailType", set.EmailType)
+	}
+	for i, h := range set.Hosts {
+		n := strconv.Itoa(i + 1)
+		params.Set("HostName"+n, h.Name)
+		params.Set("RecordType"+n, h.Type)
+		params.Set("Address"+n, h.Address)
+		params.Set("TTL"+n, strconv.Itoa(h.TTL))
+		if h.Type == "MX" {
+			params.Set("MXPref"+n, h.MXPref)
+		}
+	}
+	result, err := p.ncRequest("namecheap.domains.dns.setHosts", params)
+	if err != nil {
+		return fmt.Errorf("更新 Namecheap 记录失败: %w", err)
+	}
+	if !result.SetHosts.IsSuccess {
+		return fmt.Errorf("更新 Namecheap 记录失败")
+	}
+	return nil
+}
+
+func namecheapRecord(h namecheapHost) Record {
+	return Record{ID: h.HostID, Type: h.Type, Host: h.Name, Value: h.Address, TTL: h.TTL}
+}
+
+// ListDomains 列出账号下的域名
+func (p *Namecheap) ListDomains() ([]Domain, error) {
+	result, err := p.ncRequest("namecheap.domains.getList", url.Values{"PageSize": {"100"}})
+	if err != nil {
+		return nil, fmt.Errorf("查询 Namecheap 域名失败: %w", err)
+	}
+	domains := make([]Domain, 0, len(result.Domains))
+	for _, d := range result.Domains {
+		domains = append(domains, Domain{Name: d.Name, ID: d.ID})
+	}
+	return domains, nil
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *Namecheap) ListRecords(zone string) ([]Record, error) {
+	hosts, err := p.getHosts(zone)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(hosts.Hosts))
+	for _, h := range hosts.Hosts {
+		records = append(records, namecheapRecord(h))
+	}
+	return records, nil
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *Namecheap) FindRecords(zone, host, recordType string) ([]Record, error) {
+	list, err := p.ListRecords(zone)
+	if err != nil {
+		return nil, err
+	}
+	return filterRecords(list, host, recordType), nil
+}
+
+// CreateRecord 新增解析记录，提交后重新查询以获取记录 ID
+func (p *Namecheap) CreateRecord(zone string, rec Record) (string, error) {
+	err := p.modifyHosts(zone, func(hosts []namecheapHost) ([]namecheapHost, bool, error) {
+		return append(hosts, namecheapHost{
+			Name:    rec.Host,
+			Type:    rec.Type,
+			Address: rec.Value,
+			MXPref:  "10",
+			TTL:     ttlOr(rec.TTL, 1800, 60),
+		}), true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if current, err := p.getHosts(zone); err == nil {
+		for _, h := range current.Hosts {
+			if strings.EqualFold(h.Name, rec.Host) && strings.EqualFold(h.Type, rec.Type) && h.Address == rec.Value {
+				return h.HostID, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *Namecheap) UpdateRecord(zone string, rec Record) error {
+	return p.modifyHosts(zone, func(hosts []namecheapHost) ([]namecheapHost, bool, error) {
+		for i := range hosts {
+			if hosts[i].HostID == rec.ID {
+				hosts[i].Name = rec.Host
+				hosts[i].Type = rec.Type
+				hosts[i].Address = rec.Value
+				hosts[i].TTL = ttlOr(rec.TTL, hosts[i].TTL, 60)
+				return hosts, true, nil
+			}
+		}
+		return nil, false, fmt.Errorf("Namecheap 记录不存在: %s", rec.ID)
+	})
+}
+
+// DeleteRecord 删除解析记录
+func (p *Namecheap) DeleteRecord(zone string, rec Record) error {
+	return p.modifyHosts(zone, func(hosts []namecheapHost) ([]namecheapHost, bool, error) {
+		var remain []namecheapHost
+		for _, h := range hosts {
+			if h.HostID != rec.ID {
+				remain = append(remain, h)
+			}
+		}
+		return remain, len(remain) != len(hosts), nil
+	})
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *Namecheap) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *Namecheap) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
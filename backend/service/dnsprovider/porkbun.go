@@ -0,0 +1,163 @@
+package dnsprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== Porkbun =====
+
+// Porkbun Porkbun DNS 服务商
+type Porkbun struct {
+	APIKey       string
+	SecretAPIKey string
+}
+
+func newPorkbun(acc model.DomainAccount) Provider {
+	return &Porkbun{APIKey: acc.AccessID, SecretAPIKey: acc.AccessSecret}
+}
+
+const porkbunEndpoint = "https://api.porkbun.com/api/json/v3"
+
+type porkbunRecord struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	TTL     string `json:"ttl"`
+}
+
+// pbRequest 调用 Porkbun API（所有接口均为 POST，凭据放在请求体中）
+func (p *Porkbun) pbRequest(path string, params map[string]interface{}, out interface{}) error {
+	payload := map[string]interface{}{
+		"apikey":       p.APIKey,
+		"secretapikey": p.SecretAPIKey,
+	}
+	for k, v := range params {
+		payload[k] = v
+	}
+	body, err := httpJSON("POST", porkbunEndpoint+path, nil, payload)
+	var status struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &status) == nil && status.Status == "ERROR" {
+		return fmt.Errorf("Porkbun API 错误: %s", status.Message)
+	}
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("解析 Porkbun 响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// retrieve 查询记录，path 为 /dns/retrieve 或 /dns/retrieveByNameType 接口路径
+func (p *Porkbun) retrieve(zone, path string) ([]Record, error) {
+	var result struct {
+		Records []porkbunRecord `json:"records"`
+	}
+	if err := p.pbRequest(path, nil, &result); err != nil {
+		return nil, fmt.Errorf("查询 Porkbun 记录失败: %w", err)
+	}
+	records := make([]Record, 0, len(result.Records))
+	for _, r := range result.Records {
+		ttl, _ := strconv.Atoi(r.TTL)
+		records = append(records, Record{
+			ID:    r.ID,
+			Type:  r.Type,
+			Host:  RelativeHost(r.Name, zone),
+			Value: r.Content,
+			TTL:   ttl,
+		})
+	}
+	return records, nil
+}
+
+// porkbunParams 新增/修改记录的参数；name 为相对主机记录，根域名留空（最小 TTL 为 600）
+func porkbunParams(rec Record) map[string]interface{} {
+	name := rec.Host
+	if name == "@" {
+		name = ""
+	}
+	return map[string]interface{}{
+		"name":    name,
+		"type":    rec.Type,
+		"content": rec.Value,
+		"ttl":     strconv.Itoa(ttlOr(rec.TTL, 600, 600)),
+	}
+}
+
+// ListDomains 列出账号下的域名
+func (p *Porkbun) ListDomains() ([]Domain, error) {
+	var domains []Domain
+	for start := 0; ; start += 1000 {
+		var result struct {
+			Domains []struct {
+				Domain string `json:"domain"`
+			} `json:"domains"`
+		}
+		if err := p.pbRequest("/domain/listAll", map[string]interface{}{"start": strconv.Itoa(start)}, &result); err != nil {
+			return nil, fmt.Errorf("查询 Porkbun 域名失败: %w", err)
+		}
+		for _, d := range result.Domains {
+			domains = append(domains, Domain{Name: d.Domain})
+		}
+		if len(result.Domains) < 1000 {
+			return domains, nil
+		}
+	}
+}
+
+// ListRecords 列出域名下的解析记录
+func (p *Porkbun) ListRecords(zone string) ([]Record, error) {
+	return p.retrieve(zone, "/dns/retrieve/"+zone)
+}
+
+// FindRecords 查询指定主机记录与类型的解析记录
+func (p *Porkbun) FindRecords(zone, host, recordType string) ([]Record, error) {
+	path := "/dns/retrieveByNameType/" + zone + "/" + recordType + "/"
+	if host != "@" {
+		path += host
+	}
+	return p.retrieve(zone, path)
+}
+
+// CreateRecord 新增解析记录
+func (p *Porkbun) CreateRecord(zone string, rec Record) (string, error) {
+	var result struct {
+		ID json.Number `json:"id"`
+	}
+	if err := p.pbRequest("/dns/create/"+zone, porkbunParams(rec), &result); err != nil {
+		return "", fmt.Errorf("新增 Porkbun 记录失败: %w", err)
+	}
+	return result.ID.String(), nil
+}
+
+// UpdateRecord 修改解析记录
+func (p *Porkbun) UpdateRecord(zone string, rec Record) error {
+	if err := p.pbRequest("/dns/edit/"+zone+"/"+rec.ID, porkbunParams(rec), nil); err != nil {
+		return fmt.Errorf("修改 Porkbun 记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecord 删除解析记录
+func (p *Porkbun) DeleteRecord(zone string, rec Record) error {
+	if err := p.pbRequest("/dns/delete/"+zone+"/"+rec.ID, nil, nil); err != nil {
+		return fmt.Errorf("删除 Porkbun 记录失败: %w", err)
+	}
+	return nil
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *Porkbun) PresentTXT(fqdn, value string) error { return presentTXT(p, fqdn, value) }
+
+// CleanupTXT 删除 TXT 记录
+func (p *Porkbun) CleanupTXT(fqdn, value string) error { return cleanupTXT(p, fqdn, value) }
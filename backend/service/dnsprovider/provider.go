@@ -0,0 +1,302 @@
+// Package dnsprovider 统一的 DNS 服务商实现，供 DDNS、域名解析管理与 ACME DNS-01 验证共用
+package dnsprovider
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ErrNotSupported 服务商不支持该操作
+var ErrNotSupported = errors.New("该 DNS 服务商不支持此操作")
+
+// Domain 服务商侧的域名（区域）
+type Domain struct {
+	Name string
+	ID   string // 服务商侧域名 ID（部分服务商为空）
+}
+
+// Record 服务商侧的解析记录
+type Record struct {
+	ID      string // 服务商侧记录 ID，修改与删除时使用
+	Type    string
+	Host    string // 主机记录（相对根域名，如 www、@）
+	Value   string
+	TTL     int  // 0 表示使用服务商默认值
+	Proxied bool // 仅 Cloudflare 支持
+}
+
+// Provider DNS 服务商接口
+// zone 为根域名（如 example.com），fqdn 为完整域名（如 www.example.com），均不带末尾的点
+type Provider interface {
+	// ListDomains 列出账号下的域名
+	ListDomains() ([]Domain, error)
+	// ListRecords 列出域名下的全部解析记录
+	ListRecords(zone string) ([]Record, error)
+	// FindRecords 查询指定主机记录与类型的解析记录
+	FindRecords(zone, host, recordType string) ([]Record, error)
+	// CreateRecord 新增解析记录，返回服务商侧记录 ID
+	CreateRecord(zone string, rec Record) (string, error)
+	// UpdateRecord 按 rec.ID 修改解析记录
+	UpdateRecord(zone string, rec Record) error
+	// DeleteRecord 按 rec.ID 删除解析记录
+	DeleteRecord(zone string, rec Record) error
+	// PresentTXT 添加 TXT 记录（ACME DNS-01 验证）
+	PresentTXT(fqdn, value string) error
+	// CleanupTXT 删除 TXT 记录
+	CleanupTXT(fqdn, value string) error
+}
+
+// RecordSetter 支持直接覆盖写入记录的服务商（如 DuckDNS、RFC 2136），SetRecord 优先使用
+type RecordSetter interface {
+	SetRecord(fqdn, recordType, value string, ttl int) error
+}
+
+// ===== 服务商注册表 =====
+
+// Factory 根据域名账号创建服务商实例
+type Factory func(acc model.DomainAccount) Provider
+
+type registration struct {
+	names   []string // 第一个为主名称，其余为别名
+	label   string
+	factory Factory
+}
+
+// providers 已支持的服务商
+var providers = []registration{
+	{[]string{"alidns", "aliyun"}, "阿里云", newAliDNS},
+	{[]string{"cloudflare", "cf"}, "Cloudflare", newCloudflare},
+	{[]string{"dnspod"}, "DNSPod", newDnspod},
+	{[]string{"huaweicloud", "huawei"}, "华为云", newHuaweiCloud},
+	{[]string{"edgeone"}, "腾讯云 EdgeOne", newEdgeOne},
+	{[]string{"godaddy"}, "GoDaddy", newGoDaddy},
+	{[]string{"namecheap"}, "Namecheap", newNamecheap},
+	{[]string{"porkbun"}, "Porkbun", newPorkbun},
+	{[]string{"duckdns"}, "DuckDNS", newDuckDNS},
+	{[]string{"dnsla"}, "DNS.LA", newDnsla},
+	{[]string{"rfc2136"}, "RFC 2136", newRFC2136},
+}
+
+// registry 以 DomainAccount.Provider 为键（小写，含别名）
+var registry = func() map[string]*registration {
+	m := make(map[string]*registration)
+	for i := range providers {
+		for _, n := range providers[i].names {
+			m[n] = &providers[i]
+		}
+	}
+	return m
+}()
+
+// New 根据域名账号的 Provider 字段创建服务商实例
+func New(acc model.DomainAccount) (Provider, error) {
+	r, ok := registry[strings.ToLower(strings.TrimSpace(acc.Provider))]
+	if !ok {
+		return nil, fmt.Errorf("不支持的 DNS 服务商: %s", acc.Provider)
+	}
+	return r.factory(acc), nil
+}
+
+// Supported 判断服务商名称是否已注册
+func Supported(name string) bool {
+	_, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// Info 已注册服务商的描述
+type Info struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// List 列出已注册的服务商（不含别名）
+func List() []Info {
+	list := make([]Info, 0, len(providers))
+	for _, r := range providers {
+		list = append(list, Info{Name: r.names[0], Label: r.label})
+	}
+	return list
+}
+
+// ===== 通用操作 =====
+
+// SetRecord 将 fqdn 的 recordType 记录设置为 value：已存在则修改第一条，不存在则新增
+// 值未变化时不做修改，force 为 true 时仍重新提交
+func SetRecord(p Provider, fqdn, recordType, value string, ttl int, force bool) error {
+	if s, ok := p.(RecordSetter); ok {
+		return s.SetRecord(fqdn, recordType, value, ttl)
+	}
+	host, zone := Split(p, fqdn)
+	existing, err := p.FindRecords(zone, host, recordType)
+	if err != nil {
+		return fmt.Errorf("查询解析记录失败: %w", err)
+	}
+	rec := Record{Type: recordType, Host: host, Value: value, TTL: ttl}
+	if len(existing) > 0 {
+		if existing[0].Value == value && !force {
+			return nil // 值未变化
+		}
+		rec.ID = existing[0].ID
+		rec.Proxied = existing[0].Proxied
+		if err := p.UpdateRecord(zone, rec); err != nil {
+			return fmt.Errorf("更新解析记录失败: %w", err)
+		}
+		return nil
+	}
+	if _, err := p.CreateRecord(zone, rec); err != nil {
+		return fmt.Errorf("新增解析记录失败: %w", err)
+	}
+	return nil
+}
+
+// presentTXT 以新增记录的方式添加 TXT 记录，适用于每条记录独立的服务商
+func presentTXT(p Provider, fqdn, value string) error {
+	host, zone := Split(p, fqdn)
+	_, err := p.CreateRecord(zone, Record{Type: "TXT", Host: host, Value: value, TTL: 120})
+	return err
+}
+
+// cleanupTXT 删除值匹配的 TXT 记录，适用于每条记录独立的服务商
+func cleanupTXT(p Provider, fqdn, value string) error {
+	host, zone := Split(p, fqdn)
+	existing, err := p.FindRecords(zone, host, "TXT")
+	if err != nil {
+		return err
+	}
+	for _, r := range existing {
+		if strings.Trim(r.Value, `"`) != value {
+			continue
+		}
+		if err := p.DeleteRecord(zone, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterRecords 按主机记录与类型精确过滤（多数服务商的查询参数为模糊匹配）
+func filterRecords(list []Record, host, recordType string) []Record {
+	var out []Record
+	for _, r := range list {
+		if strings.EqualFold(r.Host, host) && strings.EqualFold(r.Type, recordType) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ===== 域名工具 =====
+
+// RelativeHost 完整域名相对根域名的主机记录，根域名本身返回 @
+func RelativeHost(fqdn, zone string) string {
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+	zone = strings.TrimSuffix(strings.ToLower(zone), ".")
+	if fqdn == zone {
+		return "@"
+	}
+	return strings.TrimSuffix(fqdn, "."+zone)
+}
+
+// JoinHost 由主机记录与根域名拼接完整域名
+func JoinHost(host, zone string) string {
+	if host == "" || host == "@" {
+		return zone
+	}
+	return host + "." + zone
+}
+
+// ttlOr 返回有效 TTL：未设置时取默认值 def，小于 min 时取 min
+func ttlOr(ttl, def, min int) int {
+	if ttl <= 0 {
+		return def
+	}
+	if ttl < min {
+		return min
+	}
+	return ttl
+}
+
+// ===== HTTP 与签名工具 =====
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// doRequest 发送请求，HTTP 状态码 >= 400 时返回错误（同时返回响应体便于解析服务商错误信息）
+func doRequest(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return body, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+func httpGet(reqURL string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return doRequest(req)
+}
+
+func httpJSON(method, reqURL string, headers map[string]string, payload interface{}) ([]byte, error) {
+	var bodyReader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, reqURL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return doRequest(req)
+}
+
+func hashSHA256(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sortedKeys 返回排序后的键，用于构造签名串
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,273 @@
+package dnsprovider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== RFC 2136 动态更新 =====
+
+// RFC2136 通过 RFC 2136 动态更新协议修改权威 DNS 服务器（BIND、PowerDNS、Knot 等）
+// 记录 ID 为区域文件格式的 "名称 类型 数据"
+type RFC2136 struct {
+	Nameserver    string // 权威服务器地址 host:port
+	TSIGAlgorithm string // TSIG 算法（FQDN 形式），为空表示不签名
+	TSIGKey       string // TSIG 密钥名（FQDN 形式）
+	TSIGSecret    string // TSIG 密钥（base64）
+}
+
+// newRFC2136 AccessID 为权威服务器地址 nameserver[:port]（默认端口 53）；
+// AccessSecret 为 TSIG 配置 [algorithm:]keyname:secret（默认 hmac-sha256），为空表示不使用 TSIG
+func newRFC2136(acc model.DomainAccount) Provider {
+	p := &RFC2136{Nameserver: acc.AccessID}
+	if _, _, err := net.SplitHostPort(acc.AccessID); err != nil {
+		p.Nameserver = net.JoinHostPort(strings.Trim(acc.AccessID, "[]"), "53")
+	}
+	if acc.AccessSecret != "" {
+		parts := strings.SplitN(acc.AccessSecret, ":", 3)
+		algorithm := "hmac-sha256"
+		if len(parts) == 3 {
+			algorithm, parts = parts[0], parts[1:]
+		}
+		if len(parts) == 2 {
+			p.TSIGAlgorithm = dns.Fqdn(strings.ToLower(algorithm))
+			p.TSIGKey = dns.Fqdn(parts[0])
+			p.TSIGSecret = parts[1]
+		}
+	}
+	return p
+}
+
+func (p *RFC2136) client(network string) *dns.Client {
+	c := &dns.Client{Net: network, Timeout: 10 * time.Second}
+	if p.TSIGKey != "" {
+		c.TsigSecret = map[string]string{p.TSIGKey: p.TSIGSecret}
+	}
+	return c
+}
+
+func (p *RFC2136) sign(m *dns.Msg) {
+	if p.TSIGKey != "" {
+		m.SetTsig(p.TSIGKey, p.TSIGAlgorithm, 300, time.Now().Unix())
+	}
+}
+
+// findZone 自完整域名起逐级向上查询 SOA，确定所属区域
+func (p *RFC2136) findZone(fqdn string) (string, error) {
+	name := dns.Fqdn(fqdn)
+	for {
+		m := new(dns.Msg)
+		m.SetQuestion(name, dns.TypeSOA)
+		m.RecursionDesired = false
+		resp, _, err := p.client("udp").Exchange(m, p.Nameserver)
+		if err != nil {
+			return "", fmt.Errorf("查询 SOA 失败: %w", err)
+		}
+		// 权威服务器在 Answer 或 Authority 段返回所属区域的 SOA
+		for _, rr := range append(resp.Answer, resp.Ns...) {
+			if soa, ok := rr.(*dns.SOA); ok {
+				return soa.Hdr.Name, nil
+			}
+		}
+		off, end := dns.NextLabel(name, 0)
+		if end {
+			return "", fmt.Errorf("未找到域名 %s 所属的区域", fqdn)
+		}
+		name = name[off:]
+	}
+}
+
+// update 向 fqdn 所属区域发送动态更新消息，build 填充更新内容
+func (p *RFC2136) update(fqdn string, build func(m *dns.Msg)) error {
+	zone, err := p.findZone(fqdn)
+	if err != nil {
+		return err
+	}
+	m := new(dns.Msg)
+	m.SetUpdate(zone)
+	build(m)
+	p.sign(m)
+	resp, _, err := p.client("tcp").Exchange(m, p.Nameserver)
+	if err != nil {
+		return fmt.Errorf("RFC 2136 更新失败: %w", err)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("RFC 2136 更新失败: 服务器返回 %s", dns.RcodeToString[resp.Rcode])
+	}
+	return nil
+}
+
+// buildRR 构造资源记录；TXT 记录值按原文处理，不需要用户加引号
+func buildRR(fqdn, recordType, value string, ttl int) (dns.RR, error) {
+	name := dns.Fqdn(fqdn)
+	if strings.EqualFold(recordType, "TXT") {
+		return &dns.TXT{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: uint32(ttl)},
+			Txt: []string{strings.Trim(value, `"`)},
+		}, nil
+	}
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, ttl, strings.ToUpper(recordType), value))
+	if err != nil {
+		return nil, fmt.Errorf("构造 DNS 记录失败: %w", err)
+	}
+	if rr == nil {
+		return nil, fmt.Errorf("构造 DNS 记录失败: 记录值为空")
+	}
+	return rr, nil
+}
+
+// toRecord 资源记录转为 Record
+func toRecord(rr dns.RR, zone string) Record {
+	hdr := rr.Header()
+	recordType := dns.TypeToString[hdr.Rrtype]
+	data := strings.TrimPrefix(rr.String(), hdr.String())
+	value := data
+	if txt, ok := rr.(*dns.TXT); ok {
+		value = strings.Join(txt.Txt, "")
+	}
+	return Record{
+		ID:    hdr.Name + " " + recordType + " " + data,
+		Type:  recordType,
+		Host:  RelativeHost(hdr.Name, zone),
+		Value: value,
+		TTL:   int(hdr.Ttl),
+	}
+}
+
+// parseRecordID 由记录 ID 还原资源记录
+func parseRecordID(id string) (dns.RR, error) {
+	rr, err := dns.NewRR(id)
+	if err != nil || rr == nil {
+		return nil, fmt.Errorf("RFC 2136 记录 ID 无效: %s", id)
+	}
+	return rr, nil
+}
+
+// SetRecord 以新值原子替换记录集
+func (p *RFC2136) SetRecord(fqdn, recordType, value string, ttl int) error {
+	rr, err := buildRR(fqdn, recordType, value, ttlOr(ttl, 300, 1))
+	if err != nil {
+		return err
+	}
+	return p.update(fqdn, func(m *dns.Msg) {
+		m.RemoveRRset([]dns.RR{rr})
+		m.Insert([]dns.RR{rr})
+	})
+}
+
+// ListDomains RFC 2136 无法枚举服务器上的区域
+func (p *RFC2136) ListDomains() ([]Domain, error) {
+	return nil, fmt.Errorf("RFC 2136 不支持列出域名，请手动添加: %w", ErrNotSupported)
+}
+
+// ListRecords 通过区域传送（AXFR）列出区域内的记录，需服务器允许本机传送
+func (p *RFC2136) ListRecords(zone string) ([]Record, error) {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(zone))
+	p.sign(m)
+	t := &dns.Transfer{}
+	if p.TSIGKey != "" {
+		t.TsigSecret = map[string]string{p.TSIGKey: p.TSIGSecret}
+	}
+	ch, err := t.In(m, p.Nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("区域传送失败: %w", err)
+	}
+	var records []Record
+	for env := range ch {
+		if env.Error != nil {
+			return nil, fmt.Errorf("区域传送失败: %w", env.Error)
+		}
+		for _, rr := range env.RR {
+			if rr.Header().Rrtype == dns.TypeSOA {
+				continue
+			}
+			records = append(records, toRecord(rr, zone))
+		}
+	}
+	return records, nil
+}
+
+// FindRecords 直接向权威服务器查询指定名称与类型的记录
+func (p *RFC2136) FindRecords(zone, host, recordType string) ([]Record, error) {
+	qtype, ok := dns.StringToType[strings.ToUpper(recordType)]
+	if !ok {
+		return nil, fmt.Errorf("不支持的记录类型: %s", recordType)
+	}
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(JoinHost(host, zone)), qtype)
+	m.RecursionDesired = false
+	resp, _, err := p.client("udp").Exchange(m, p.Nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("查询 DNS 记录失败: %w", err)
+	}
+	var records []Record
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == qtype {
+			records = append(records, toRecord(rr, zone))
+		}
+	}
+	return records, nil
+}
+
+// CreateRecord 向记录集中添加记录
+func (p *RFC2136) CreateRecord(zone string, rec Record) (string, error) {
+	fqdn := JoinHost(rec.Host, zone)
+	rr, err := buildRR(fqdn, rec.Type, rec.Value, ttlOr(rec.TTL, 300, 1))
+	if err != nil {
+		return "", err
+	}
+	if err := p.update(fqdn, func(m *dns.Msg) { m.Insert([]dns.RR{rr}) }); err != nil {
+		return "", err
+	}
+	return toRecord(rr, zone).ID, nil
+}
+
+// UpdateRecord 在同一更新消息中删除旧记录并添加新记录
+func (p *RFC2136) UpdateRecord(zone string, rec Record) error {
+	old, err := parseRecordID(rec.ID)
+	if err != nil {
+		return err
+	}
+	fqdn := JoinHost(rec.Host, zone)
+	rr, err := buildRR(fqdn, rec.Type, rec.Value, ttlOr(rec.TTL, 300, 1))
+	if err != nil {
+		return err
+	}
+	return p.update(fqdn, func(m *dns.Msg) {
+		m.Remove([]dns.RR{old})
+		m.Insert([]dns.RR{rr})
+	})
+}
+
+// DeleteRecord 删除单条记录
+func (p *RFC2136) DeleteRecord(zone string, rec Record) error {
+	old, err := parseRecordID(rec.ID)
+	if err != nil {
+		return err
+	}
+	return p.update(old.Header().Name, func(m *dns.Msg) { m.Remove([]dns.RR{old}) })
+}
+
+// PresentTXT 添加 TXT 记录
+func (p *RFC2136) PresentTXT(fqdn, value string) error {
+	rr, err := buildRR(fqdn, "TXT", value, 60)
+	if err != nil {
+		return err
+	}
+	return p.update(fqdn, func(m *dns.Msg) { m.Insert([]dns.RR{rr}) })
+}
+
+// CleanupTXT 删除 TXT 记录
+func (p *RFC2136) CleanupTXT(fqdn, value string) error {
+	rr, err := buildRR(fqdn, "TXT", value, 0)
+	if err != nil {
+		return err
+	}
+	return p.update(fqdn, func(m *dns.Msg) { m.Remove([]dns.RR{rr}) })
+}
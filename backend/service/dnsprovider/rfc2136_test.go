@@ -0,0 +1,220 @@
+package dnsprovider
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/netpanel/netpanel/model"
+)
+
+const (
+	testZone       = "example.test."
+	testTSIGKey    = "netpanel-key."
+	testTSIGSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0IQ=="
+)
+
+// fakeAuthServer 仅承载 testZone 的最小权威服务器，更新请求必须携带有效 TSIG
+type fakeAuthServer struct {
+	mu      sync.Mutex
+	records []dns.RR
+}
+
+func (s *fakeAuthServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(r)
+	resp.Authoritative = true
+
+	if r.Opcode == dns.OpcodeUpdate {
+		if r.IsTsig() == nil || w.TsigStatus() != nil {
+			resp.Rcode = dns.RcodeNotAuth
+		} else {
+			s.applyUpdate(r.Ns)
+		}
+	} else if len(r.Question) == 1 {
+		s.answer(resp, r.Question[0])
+	}
+
+	if t := r.IsTsig(); t != nil && w.TsigStatus() == nil {
+		resp.SetTsig(t.Hdr.Name, t.Algorithm, 300, time.Now().Unix())
+	}
+	w.WriteMsg(resp)
+}
+
+func (s *fakeAuthServer) answer(resp *dns.Msg, q dns.Question) {
+	name := strings.ToLower(q.Name)
+	if name != testZone && !strings.HasSuffix(name, "."+testZone) {
+		resp.Rcode = dns.RcodeRefused
+		return
+	}
+	soa, _ := dns.NewRR(testZone + " 300 IN SOA ns.example.test. admin.example.test. 1 3600 600 86400 300")
+	if q.Qtype == dns.TypeSOA {
+		if name == testZone {
+			resp.Answer = append(resp.Answer, soa)
+		} else {
+			resp.Ns = append(resp.Ns, soa)
+		}
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rr := range s.records {
+		if strings.EqualFold(rr.Header().Name, name) && rr.Header().Rrtype == q.Qtype {
+			resp.Answer = append(resp.Answer, dns.Copy(rr))
+		}
+	}
+}
+
+// applyUpdate 按 RFC 2136 第 2.5 节处理更新段：ANY 删除记录集，NONE 删除单条记录，IN 添加记录
+func (s *fakeAuthServer) applyUpdate(updates []dns.RR) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range updates {
+		hdr := u.Header()
+		switch hdr.Class {
+		case dns.ClassANY:
+			s.records = filterRR(s.records, func(rr dns.RR) bool {
+				return strings.EqualFold(rr.Header().Name, hdr.Name) && rr.Header().Rrtype == hdr.Rrtype
+			})
+		case dns.ClassNONE:
+			s.records = filterRR(s.records, func(rr dns.RR) bool {
+				c := dns.Copy(u)
+				c.Header().Class, c.Header().Ttl = dns.ClassINET, rr.Header().Ttl
+				return dns.IsDuplicate(rr, c)
+			})
+		default:
+			s.records = append(s.records, dns.Copy(u))
+		}
+	}
+}
+
+func filterRR(rrs []dns.RR, drop func(dns.RR) bool) []dns.RR {
+	kept := rrs[:0]
+	for _, rr := range rrs {
+		if !drop(rr) {
+			kept = append(kept, rr)
+		}
+	}
+	return kept
+}
+
+// startFakeAuthServer 在同一端口上启动 UDP 与 TCP 服务，返回地址
+func startFakeAuthServer(t *testing.T, handler dns.Handler) string {
+	t.Helper()
+	secrets := map[string]string{testTSIGKey: testTSIGSecret}
+	for attempt := 0; attempt < 10; attempt++ {
+		tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		udpConn, err := net.ListenPacket("udp", tcpLn.Addr().String())
+		if err != nil {
+			tcpLn.Close()
+			continue
+		}
+		// 默认的 MsgAcceptFunc 会以 NOTIMP 拒绝 UPDATE 操作码
+		accept := func(dh dns.Header) dns.MsgAcceptAction {
+			if int(dh.Bits>>11)&0xF == dns.OpcodeUpdate {
+				return dns.MsgAccept
+			}
+			return dns.DefaultMsgAcceptFunc(dh)
+		}
+		started := make(chan struct{}, 2)
+		notify := func() { started <- struct{}{} }
+		udpSrv := &dns.Server{PacketConn: udpConn, Handler: handler, TsigSecret: secrets, MsgAcceptFunc: accept, NotifyStartedFunc: notify}
+		tcpSrv := &dns.Server{Listener: tcpLn, Handler: handler, TsigSecret: secrets, MsgAcceptFunc: accept, NotifyStartedFunc: notify}
+		go udpSrv.ActivateAndServe()
+		go tcpSrv.ActivateAndServe()
+		<-started
+		<-started
+		t.Cleanup(func() {
+			udpSrv.Shutdown()
+			tcpSrv.Shutdown()
+		})
+		return tcpLn.Addr().String()
+	}
+	t.Fatal("无法在同一端口上监听 UDP 与 TCP")
+	return ""
+}
+
+func TestRFC2136Update(t *testing.T) {
+	srv := &fakeAuthServer{}
+	addr := startFakeAuthServer(t, srv)
+	p := newRFC2136(model.DomainAccount{AccessID: addr, AccessSecret: "hmac-sha256:" + testTSIGKey + ":" + testTSIGSecret})
+
+	// SetRecord 替换整个记录集
+	if err := SetRecord(p, "home.example.test", "A", "192.0.2.1", 120, false); err != nil {
+		t.Fatalf("SetRecord: %v", err)
+	}
+	if err := SetRecord(p, "home.example.test", "A", "192.0.2.2", 120, false); err != nil {
+		t.Fatalf("SetRecord: %v", err)
+	}
+	recs, err := p.FindRecords("example.test", "home", "A")
+	if err != nil {
+		t.Fatalf("FindRecords: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Value != "192.0.2.2" || recs[0].Host != "home" || recs[0].TTL != 120 {
+		t.Fatalf("FindRecords = %+v, want single home A 192.0.2.2 ttl 120", recs)
+	}
+
+	// CreateRecord / UpdateRecord / DeleteRecord 以记录 ID 定位单条记录
+	id, err := p.CreateRecord("example.test", Record{Host: "_acme", Type: "TXT", Value: "token-1", TTL: 60})
+	if err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+	if err := p.UpdateRecord("example.test", Record{ID: id, Host: "_acme", Type: "TXT", Value: "token-2", TTL: 60}); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	recs, err = p.FindRecords("example.test", "_acme", "TXT")
+	if err != nil {
+		t.Fatalf("FindRecords: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Value != "token-2" {
+		t.Fatalf("FindRecords = %+v, want single TXT token-2", recs)
+	}
+	if err := p.DeleteRecord("example.test", recs[0]); err != nil {
+		t.Fatalf("DeleteRecord: %v", err)
+	}
+	if recs, _ = p.FindRecords("example.test", "_acme", "TXT"); len(recs) != 0 {
+		t.Fatalf("DeleteRecord 后仍存在记录: %+v", recs)
+	}
+}
+
+func TestRFC2136RejectsBadTSIG(t *testing.T) {
+	srv := &fakeAuthServer{}
+	addr := startFakeAuthServer(t, srv)
+
+	unsigned := newRFC2136(model.DomainAccount{AccessID: addr})
+	if err := SetRecord(unsigned, "home.example.test", "A", "192.0.2.1", 120, true); err == nil {
+		t.Fatal("未签名的更新应被拒绝")
+	}
+
+	wrong := newRFC2136(model.DomainAccount{AccessID: addr, AccessSecret: testTSIGKey + ":" + "d3Jvbmctc2VjcmV0"})
+	if err := SetRecord(wrong, "home.example.test", "A", "192.0.2.1", 120, true); err == nil {
+		t.Fatal("TSIG 密钥错误的更新应被拒绝")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.records) != 0 {
+		t.Fatalf("被拒绝的更新不应生效: %v", srv.records)
+	}
+}
+
+func TestNewRFC2136(t *testing.T) {
+	p := newRFC2136(model.DomainAccount{AccessID: "ns1.example.test", AccessSecret: "key:c2VjcmV0"}).(*RFC2136)
+	if p.Nameserver != "ns1.example.test:53" {
+		t.Errorf("Nameserver = %q, want default port 53", p.Nameserver)
+	}
+	if p.TSIGAlgorithm != dns.HmacSHA256 || p.TSIGKey != "key." || p.TSIGSecret != "c2VjcmV0" {
+		t.Errorf("TSIG = %q %q %q, want default hmac-sha256", p.TSIGAlgorithm, p.TSIGKey, p.TSIGSecret)
+	}
+
+	p = newRFC2136(model.DomainAccount{AccessID: "[2001:db8::53]:5353", AccessSecret: "HMAC-SHA512:key.:c2VjcmV0"}).(*RFC2136)
+	if p.Nameserver != "[2001:db8::53]:5353" || p.TSIGAlgorithm != dns.HmacSHA512 {
+		t.Errorf("Nameserver/TSIG = %q %q", p.Nameserver, p.TSIGAlgorithm)
+	}
+}
@@ -0,0 +1,85 @@
+package dnsprovider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ===== 根域名拆分 =====
+
+// zoneCacheTTL 服务商域名列表的缓存时间
+const zoneCacheTTL = 10 * time.Minute
+
+type zoneCacheEntry struct {
+	zones   []string
+	expires time.Time
+}
+
+// zoneCache 服务商托管的域名列表，按服务商类型与凭据缓存
+var zoneCache sync.Map // map[string]*zoneCacheEntry
+
+// Split 将完整域名拆分为主机记录和根域名
+// 优先在服务商托管的域名中选取最长匹配（支持子域名单独托管），无法获取域名列表时按公共后缀列表拆分
+func Split(p Provider, fqdn string) (host, zone string) {
+	if zone, ok := FindZone(fqdn, managedZones(p)); ok {
+		return RelativeHost(fqdn, zone), zone
+	}
+	return SplitDomain(fqdn)
+}
+
+// SplitDomain 按公共后缀列表（Public Suffix List）将完整域名拆分为主机记录和可注册域名
+// 例如 home.example.com -> ("home", "example.com")
+// 例如 nas.example.com.cn -> ("nas", "example.com.cn")
+// 例如 example.co.uk -> ("@", "example.co.uk")
+func SplitDomain(fqdn string) (host, zone string) {
+	fqdn = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(fqdn), "."))
+	zone, err := publicsuffix.EffectiveTLDPlusOne(fqdn)
+	if err != nil {
+		// 单标签域名或域名本身为公共后缀
+		return "@", fqdn
+	}
+	return RelativeHost(fqdn, zone), zone
+}
+
+// FindZone 在域名列表中选取与完整域名匹配的最长根域名
+func FindZone(fqdn string, zones []string) (string, bool) {
+	fqdn = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(fqdn), "."))
+	best := ""
+	for _, z := range zones {
+		z = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(z), "."))
+		if z == "" || len(z) <= len(best) {
+			continue
+		}
+		if fqdn == z || strings.HasSuffix(fqdn, "."+z) {
+			best = z
+		}
+	}
+	return best, best != ""
+}
+
+// managedZones 获取服务商托管的域名列表（带缓存），不支持或查询失败时返回空
+func managedZones(p Provider) []string {
+	key := hashSHA256(fmt.Sprintf("%T%+v", p, p))
+	if v, ok := zoneCache.Load(key); ok {
+		if e := v.(*zoneCacheEntry); time.Now().Before(e.expires) {
+			return e.zones
+		}
+	}
+
+	entry := &zoneCacheEntry{expires: time.Now().Add(zoneCacheTTL)}
+	domains, err := p.ListDomains()
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		// 查询失败时短暂缓存空结果，避免每次拆分都重复请求
+		entry.expires = time.Now().Add(time.Minute)
+	}
+	for _, d := range domains {
+		entry.zones = append(entry.zones, d.Name)
+	}
+	zoneCache.Store(key, entry)
+	return entry.zones
+}
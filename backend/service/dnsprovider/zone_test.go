@@ -0,0 +1,50 @@
+package dnsprovider
+
+import "testing"
+
+func TestSplitDomain(t *testing.T) {
+	tests := []struct {
+		fqdn, host, zone string
+	}{
+		{"home.example.com", "home", "example.com"},
+		{"a.b.example.com.", "a.b", "example.com"},
+		{"example.com", "@", "example.com"},
+		{" NAS.Example.COM.CN ", "nas", "example.com.cn"},
+		{"example.co.uk", "@", "example.co.uk"},
+		{"localhost", "@", "localhost"},
+		{"com", "@", "com"},
+	}
+	for _, tt := range tests {
+		host, zone := SplitDomain(tt.fqdn)
+		if host != tt.host || zone != tt.zone {
+			t.Errorf("SplitDomain(%q) = (%q, %q), want (%q, %q)", tt.fqdn, host, zone, tt.host, tt.zone)
+		}
+	}
+}
+
+func TestFindZone(t *testing.T) {
+	zones := []string{"example.com", "sub.example.com.", "EXAMPLE.ORG", ""}
+	tests := []struct {
+		fqdn string
+		zone string
+		ok   bool
+	}{
+		{"www.example.com", "example.com", true},
+		// 子域名单独托管时取最长匹配
+		{"a.sub.example.com", "sub.example.com", true},
+		{"sub.example.com.", "sub.example.com", true},
+		{"Host.Example.Org", "example.org", true},
+		// 只按标签边界匹配
+		{"badexample.com", "", false},
+		{"example.net", "", false},
+	}
+	for _, tt := range tests {
+		zone, ok := FindZone(tt.fqdn, zones)
+		if zone != tt.zone || ok != tt.ok {
+			t.Errorf("FindZone(%q) = (%q, %v), want (%q, %v)", tt.fqdn, zone, ok, tt.zone, tt.ok)
+		}
+	}
+	if _, ok := FindZone("www.example.com", nil); ok {
+		t.Error("FindZone 在空列表中不应匹配")
+	}
+}
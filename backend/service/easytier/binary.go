@@ -0,0 +1,667 @@
+package easytier
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+const (
+	easytierReleaseAPI = "https://api.github.com/repos/EasyTier/EasyTier/releases"
+	maxArchiveSize     = 200 << 20 // 发行包大小上限
+
+	// offlineModeKey 系统配置项：为 "true" 时只允许上传发行包安装，不访问 GitHub
+	offlineModeKey = "easytier_offline_mode"
+)
+
+// versionPattern 合法的版本号（同时用作版本目录名）
+var versionPattern = regexp.MustCompile(`^v?[0-9][0-9A-Za-z._-]*$`)
+
+// archiveVersionPattern 从发行包文件名中提取版本号，如 easytier-linux-x86_64-v2.4.5.zip
+var archiveVersionPattern = regexp.MustCompile(`-(v[0-9][0-9A-Za-z._-]*?)\.(zip|tar\.gz|tgz)$`)
+
+// BinaryVersion 已安装的 easytier 版本
+type BinaryVersion struct {
+	Version     string    `json:"version"`
+	Asset       string    `json:"asset"`  // 发行包文件名
+	SHA256      string    `json:"sha256"` // 发行包校验值
+	Source      string    `json:"source"` // download/upload
+	InstalledAt time.Time `json:"installed_at"`
+	Active      bool      `json:"active"`
+}
+
+// BinaryStatus 二进制管理状态
+type BinaryStatus struct {
+	Platform  string          `json:"platform"`  // 当前平台对应的发行包标识，如 linux-x86_64
+	Available bool            `json:"available"` // easytier-core 是否可用
+	Active    string          `json:"active"`    // 当前使用的版本
+	Pinned    string          `json:"pinned"`    // 固定版本，为空表示跟随最新版
+	Offline   bool            `json:"offline"`   // 离线模式，只允许上传发行包安装
+	Versions  []BinaryVersion `json:"versions"`
+}
+
+// ReleaseInfo 发行版信息
+type ReleaseInfo struct {
+	Version     string    `json:"version"`
+	Asset       string    `json:"asset"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	SHA256      string    `json:"sha256"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// binaryState 版本记录，保存在 versions.json
+type binaryState struct {
+	Active   string          `json:"active"`
+	Pinned   string          `json:"pinned"`
+	Versions []BinaryVersion `json:"versions"`
+}
+
+// binaryStore 管理 easytier 发行包的下载、校验、解包与版本切换
+// 各版本解包到 bin/easytier-versions/<version>/，当前版本的文件复制到 bin/ 下供进程使用
+type binaryStore struct {
+	mu         sync.Mutex   // 保护版本目录、versions.json 及 bin 目录下的文件
+	installing sync.Mutex   // 串行化缺失二进制时的自动下载
+	switching  sync.RWMutex // 版本切换（停止、替换、重启）期间阻止实例启动，切换持写锁，启动持读锁
+}
+
+// offlineMode 是否处于离线模式（系统配置 easytier_offline_mode）
+func (m *Manager) offlineMode() bool {
+	var cfg model.SystemConfig
+	if err := m.db.Where("key = ?", offlineModeKey).First(&cfg).Error; err != nil {
+		return false
+	}
+	return cfg.Value == "true"
+}
+
+// errOffline 离线模式下拒绝访问 GitHub 的错误
+var errOffline = fmt.Errorf("EasyTier 已启用离线模式（系统配置 %s），请上传发行包安装", offlineModeKey)
+
+func (m *Manager) versionsDir() string {
+	return filepath.Join(m.dataDir, "bin", "easytier-versions")
+}
+
+func (m *Manager) loadBinaryState() *binaryState {
+	st := &binaryState{}
+	data, err := os.ReadFile(filepath.Join(m.versionsDir(), "versions.json"))
+	if err == nil {
+		_ = json.Unmarshal(data, st)
+	}
+	return st
+}
+
+func (m *Manager) saveBinaryState(st *binaryState) error {
+	if err := os.MkdirAll(m.versionsDir(), 0755); err != nil {
+		return err
+	}
+	data, _ := json.MarshalIndent(st, "", "  ")
+	return os.WriteFile(filepath.Join(m.versionsDir(), "versions.json"), data, 0644)
+}
+
+// platformNames 返回当前平台在发行包名称中的系统名与候选架构名
+func platformNames() (string, []string) {
+	goos := runtime.GOOS
+	if goos == "darwin" {
+		goos = "macos"
+	}
+	var arches []string
+	switch runtime.GOARCH {
+	case "amd64":
+		arches = []string{"x86_64"}
+	case "arm64":
+		arches = []string{"aarch64"}
+	case "arm":
+		arches = []string{"armv7hf", "armv7", "armhf", "arm"}
+	case "386":
+		arches = []string{"i686", "i386"}
+	case "mips":
+		arches = []string{"mips"}
+	case "mipsle":
+		arches = []string{"mipsel"}
+	case "riscv64":
+		arches = []string{"riscv64"}
+	case "loong64":
+		arches = []string{"loongarch64"}
+	default:
+		arches = []string{runtime.GOARCH}
+	}
+	return goos, arches
+}
+
+// selectAsset 按 GOOS/GOARCH 选择发行包
+func selectAsset(names []string) (string, bool) {
+	goos, arches := platformNames()
+	for _, arch := range arches {
+		for _, name := range names {
+			lower := strings.ToLower(name)
+			if !strings.HasPrefix(lower, "easytier-"+goos+"-") || !strings.HasSuffix(lower, ".zip") {
+				continue
+			}
+			if strings.Contains(lower, "-"+arch+"-") {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ===== 发行版查询 =====
+
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name               string `json:"name"`
+		Size               int64  `json:"size"`
+		Digest             string `json:"digest"` // 形如 sha256:<hex>
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+var binaryHTTPClient = &http.Client{Timeout: 10 * time.Minute}
+
+// GetRelease 查询指定版本（为空时查询最新版）的发行包信息
+func (m *Manager) GetRelease(version string) (*ReleaseInfo, error) {
+	if m.offlineMode() {
+		return nil, errOffline
+	}
+	url := easytierReleaseAPI + "/latest"
+	if version != "" {
+		if !versionPattern.MatchString(version) {
+			return nil, fmt.Errorf("版本号格式无效: %s", version)
+		}
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		url = easytierReleaseAPI + "/tags/" + version
+	}
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("查询 EasyTier 发行版失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("查询 EasyTier 发行版失败: HTTP %d", resp.StatusCode)
+	}
+	var rel githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("解析发行版信息失败: %w", err)
+	}
+
+	names := make([]string, 0, len(rel.Assets))
+	for _, a := range rel.Assets {
+		names = append(names, a.Name)
+	}
+	assetName, ok := selectAsset(names)
+	if !ok {
+		goos, arches := platformNames()
+		return nil, fmt.Errorf("发行版 %s 中没有适用于 %s-%s 的发行包", rel.TagName, goos, arches[0])
+	}
+	info := &ReleaseInfo{Version: rel.TagName, Asset: assetName, PublishedAt: rel.PublishedAt}
+	for _, a := range rel.Assets {
+		if a.Name == assetName {
+			info.Size = a.Size
+			info.URL = a.BrowserDownloadURL
+			info.SHA256 = strings.TrimPrefix(a.Digest, "sha256:")
+		}
+	}
+	// 未提供 digest 时尝试读取同名 .sha256 校验文件
+	if info.SHA256 == "" {
+		for _, a := range rel.Assets {
+			if a.Name == assetName+".sha256" {
+				if sum, err := fetchChecksum(a.BrowserDownloadURL); err == nil {
+					info.SHA256 = sum
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// fetchChecksum 下载 .sha256 校验文件并取第一个字段
+func fetchChecksum(url string) (string, error) {
+	resp, err := binaryHTTPClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("校验文件为空")
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// ===== 安装 =====
+
+// InstallRelease 下载、校验并安装指定版本，完成后切换到该版本
+// version 为空时安装固定版本，未固定时安装最新版；expectSHA256 非空时以其为准校验发行包
+func (m *Manager) InstallRelease(version, expectSHA256 string) (*BinaryVersion, error) {
+	if version == "" {
+		version = m.loadBinaryState().Pinned
+	}
+	rel, err := m.GetRelease(version)
+	if err != nil {
+		return nil, err
+	}
+	sum := strings.ToLower(expectSHA256)
+	if sum == "" {
+		sum = rel.SHA256
+	}
+	if sum == "" {
+		return nil, fmt.Errorf("发行包 %s 未提供校验值，请手动填写 SHA256 后重试", rel.Asset)
+	}
+
+	m.log.Infof("[EasyTier] 开始下载 %s", rel.Asset)
+	resp, err := binaryHTTPClient.Get(rel.URL)
+	if err != nil {
+		return nil, fmt.Errorf("下载发行包失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载发行包失败: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("下载发行包失败: %w", err)
+	}
+	if len(data) > maxArchiveSize {
+		return nil, fmt.Errorf("发行包超过大小上限")
+	}
+
+	v, err := m.installArchive(rel.Version, rel.Asset, data, sum, "download")
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ActivateVersion(v.Version); err != nil {
+		return nil, err
+	}
+	v.Active = true
+	return v, nil
+}
+
+// InstallArchive 离线安装：从上传的发行包安装并切换到该版本
+// version 为空时从文件名中解析；expectSHA256 非空时校验发行包
+func (m *Manager) InstallArchive(filename string, data []byte, version, expectSHA256 string) (*BinaryVersion, error) {
+	if version == "" {
+		if match := archiveVersionPattern.FindStringSubmatch(filepath.Base(filename)); match != nil {
+			version = match[1]
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("无法从文件名识别版本号，请手动填写版本")
+	}
+	v, err := m.installArchive(version, filepath.Base(filename), data, strings.ToLower(expectSHA256), "upload")
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ActivateVersion(v.Version); err != nil {
+		return nil, err
+	}
+	v.Active = true
+	return v, nil
+}
+
+// installArchive 校验并解包发行包到版本目录，记录版本信息
+func (m *Manager) installArchive(version, asset string, data []byte, expectSHA256, source string) (*BinaryVersion, error) {
+	if !versionPattern.MatchString(version) {
+		return nil, fmt.Errorf("版本号格式无效: %s", version)
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	digest := sha256.Sum256(data)
+	sum := hex.EncodeToString(digest[:])
+	if expectSHA256 != "" && sum != expectSHA256 {
+		return nil, fmt.Errorf("发行包校验失败: 期望 %s，实际 %s", expectSHA256, sum)
+	}
+
+	files, err := extractArchive(asset, data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := files[binaryFileName("easytier-core")]; !ok {
+		return nil, fmt.Errorf("发行包中未找到 %s", binaryFileName("easytier-core"))
+	}
+
+	m.binary.mu.Lock()
+	defer m.binary.mu.Unlock()
+
+	dir := filepath.Join(m.versionsDir(), version)
+	tmpDir := dir + ".tmp"
+	os.RemoveAll(tmpDir)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), content, 0755); err != nil {
+			os.RemoveAll(tmpDir)
+			return nil, fmt.Errorf("写入 %s 失败: %w", name, err)
+		}
+	}
+	os.RemoveAll(dir)
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return nil, err
+	}
+
+	v := BinaryVersion{
+		Version:     version,
+		Asset:       asset,
+		SHA256:      sum,
+		Source:      source,
+		InstalledAt: time.Now(),
+	}
+	st := m.loadBinaryState()
+	versions := st.Versions[:0]
+	for _, old := range st.Versions {
+		if old.Version != version {
+			versions = append(versions, old)
+		}
+	}
+	st.Versions = append(versions, v)
+	if err := m.saveBinaryState(st); err != nil {
+		return nil, err
+	}
+	m.log.Infof("[EasyTier] 已安装 %s（%s，SHA256: %s）", version, asset, sum)
+	return &v, nil
+}
+
+func binaryFileName(name string) string {
+	if runtime.GOOS == "windows" {
+		return name + ".exe"
+	}
+	return name
+}
+
+// extractArchive 解包 zip/tar.gz 发行包，按文件名平铺返回（忽略目录结构）
+func extractArchive(name string, data []byte) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	add := func(p string, r io.Reader) error {
+		base := path.Base(strings.ReplaceAll(p, "\\", "/"))
+		if base == "" || base == "." || base == ".." || strings.HasPrefix(base, ".") {
+			return nil
+		}
+		content, err := io.ReadAll(io.LimitReader(r, maxArchiveSize))
+		if err != nil {
+			return err
+		}
+		files[base] = content
+		return nil
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解包失败: %w", err)
+		}
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("解包失败: %w", err)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := add(hdr.Name, tr); err != nil {
+				return nil, fmt.Errorf("解包失败: %w", err)
+			}
+		}
+	default:
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("解包失败（仅支持 zip/tar.gz）: %w", err)
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("解包失败: %w", err)
+			}
+			err = add(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("解包失败: %w", err)
+			}
+		}
+	}
+	return files, nil
+}
+
+// ===== 版本切换 =====
+
+// ActivateVersion 切换到已安装的版本（用于升级与回滚），运行中的实例会重启到新版本
+func (m *Manager) ActivateVersion(version string) error {
+	m.binary.switching.Lock()
+	defer m.binary.switching.Unlock()
+
+	src := filepath.Join(m.versionsDir(), version)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("版本 %s 未安装", version)
+	}
+
+	// 先停止运行中的实例，避免二进制文件被占用（Windows 下无法覆盖运行中的程序）
+	var clientIDs, serverIDs, webIDs []uint
+	m.clients.Range(func(key, _ interface{}) bool {
+		clientIDs = append(clientIDs, key.(uint))
+		return true
+	})
+	m.servers.Range(func(key, _ interface{}) bool {
+		serverIDs = append(serverIDs, key.(uint))
+		return true
+	})
+	m.webs.Range(func(key, _ interface{}) bool {
+		webIDs = append(webIDs, key.(uint))
+		return true
+	})
+	for _, id := range clientIDs {
+		m.stopProcess(&m.clients, id)
+	}
+	for _, id := range serverIDs {
+		m.stopProcess(&m.servers, id)
+	}
+	for _, id := range webIDs {
+		m.StopWebServer(id)
+	}
+
+	err = func() error {
+		m.binary.mu.Lock()
+		defer m.binary.mu.Unlock()
+		binDir := filepath.Dir(m.getBinaryPath())
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(src, e.Name()))
+			if err != nil {
+				return err
+			}
+			dst := filepath.Join(binDir, e.Name())
+			tmp := dst + ".new"
+			if err := os.WriteFile(tmp, content, 0755); err != nil {
+				return fmt.Errorf("写入 %s 失败: %w", e.Name(), err)
+			}
+			if err := os.Rename(tmp, dst); err != nil {
+				os.Remove(tmp)
+				return fmt.Errorf("替换 %s 失败: %w", e.Name(), err)
+			}
+		}
+		st := m.loadBinaryState()
+		st.Active = version
+		return m.saveBinaryState(st)
+	}()
+	if err != nil {
+		m.log.Errorf("[EasyTier] 切换到 %s 失败: %v", version, err)
+	} else {
+		m.log.Infof("[EasyTier] 已切换到 %s", version)
+	}
+
+	// 重启之前运行的实例
+	for _, id := range clientIDs {
+		if startErr := m.startClient(id); startErr != nil {
+			m.log.Errorf("[EasyTier客户端][%d] 切换版本后重启失败: %v", id, startErr)
+		}
+	}
+	for _, id := range serverIDs {
+		if startErr := m.startServer(id); startErr != nil {
+			m.log.Errorf("[EasyTier服务端][%d] 切换版本后重启失败: %v", id, startErr)
+		}
+	}
+	for _, id := range webIDs {
+		if startErr := m.startWebServer(id); startErr != nil {
+			m.log.Errorf("[EasyTier配置服务器][%d] 切换版本后重启失败: %v", id, startErr)
+		}
+	}
+	return err
+}
+
+// PinVersion 固定版本，自动安装与升级时使用该版本；version 为空时取消固定
+func (m *Manager) PinVersion(version string) error {
+	if version != "" {
+		if !versionPattern.MatchString(version) {
+			return fmt.Errorf("版本号格式无效: %s", version)
+		}
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+	}
+	m.binary.mu.Lock()
+	defer m.binary.mu.Unlock()
+	st := m.loadBinaryState()
+	st.Pinned = version
+	return m.saveBinaryState(st)
+}
+
+// RemoveVersion 删除已安装的非当前版本
+func (m *Manager) RemoveVersion(version string) error {
+	m.binary.mu.Lock()
+	defer m.binary.mu.Unlock()
+	st := m.loadBinaryState()
+	if st.Active == version {
+		return fmt.Errorf("不能删除当前使用的版本")
+	}
+	found := false
+	versions := st.Versions[:0]
+	for _, v := range st.Versions {
+		if v.Version == version {
+			found = true
+			continue
+		}
+		versions = append(versions, v)
+	}
+	if !found {
+		return fmt.Errorf("版本 %s 未安装", version)
+	}
+	st.Versions = versions
+	if err := os.RemoveAll(filepath.Join(m.versionsDir(), version)); err != nil {
+		return err
+	}
+	return m.saveBinaryState(st)
+}
+
+// GetBinaryStatus 获取二进制安装状态
+func (m *Manager) GetBinaryStatus() *BinaryStatus {
+	goos, arches := platformNames()
+	st := m.loadBinaryState()
+	versions := make([]BinaryVersion, 0, len(st.Versions))
+	for _, v := range st.Versions {
+		v.Active = v.Version == st.Active
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].InstalledAt.After(versions[j].InstalledAt) })
+	return &BinaryStatus{
+		Platform:  goos + "-" + arches[0],
+		Available: m.isBinaryAvailable(),
+		Active:    st.Active,
+		Pinned:    st.Pinned,
+		Offline:   m.offlineMode(),
+		Versions:  versions,
+	}
+}
+
+// ensureBinary 确保 easytier-core 可用：当前版本文件缺失时从版本目录恢复，
+// 未安装任何版本时自动下载固定版本（未固定时为最新版），离线模式下不下载
+func (m *Manager) ensureBinary() error {
+	if m.isBinaryAvailable() {
+		return nil
+	}
+	m.binary.installing.Lock()
+	defer m.binary.installing.Unlock()
+	if m.isBinaryAvailable() {
+		return nil
+	}
+
+	st := m.loadBinaryState()
+	if st.Active != "" {
+		if _, err := os.Stat(filepath.Join(m.versionsDir(), st.Active)); err == nil {
+			return m.restoreActive(st.Active)
+		}
+	}
+	if m.offlineMode() {
+		return fmt.Errorf("easytier-core 不存在: %w", errOffline)
+	}
+	m.log.Infof("[EasyTier] easytier-core 不存在，开始自动下载")
+	if _, err := m.InstallRelease("", ""); err != nil {
+		return fmt.Errorf("easytier-core 不存在且自动下载失败（可上传发行包离线安装）: %w", err)
+	}
+	return nil
+}
+
+// restoreActive 将当前版本的文件复制回 bin 目录（不重启实例）
+func (m *Manager) restoreActive(version string) error {
+	m.binary.mu.Lock()
+	defer m.binary.mu.Unlock()
+	src := filepath.Join(m.versionsDir(), version)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	binDir := filepath.Dir(m.getBinaryPath())
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(binDir, e.Name()), content, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
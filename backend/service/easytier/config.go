@@ -0,0 +1,782 @@
+package easytier
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// easytier config.toml 中 data_compress_algo 的取值
+const (
+	compressAlgoNone = 1
+	compressAlgoZstd = 2
+)
+
+// tomlDoc easytier config.toml 文档（顶层键 -> 值）
+type tomlDoc map[string]any
+
+// splitList 按逗号（或换行）拆分列表并去除空项
+func splitList(s string, seps string) []string {
+	var out []string
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// expandListeners 将监听端口配置展开为监听 URL，规则与 easytier-core -l 参数一致：
+// 12345 展开为 tcp/udp:12345、wg/ws:12346、wss:12347；tcp:11010 展开为 tcp://bindAddr:11010；完整 URL 原样保留
+func expandListeners(spec, bindAddr string) []string {
+	if bindAddr == "" {
+		bindAddr = "0.0.0.0"
+	}
+	var out []string
+	for _, l := range splitList(spec, ",") {
+		if !strings.Contains(l, ":") {
+			port, err := strconv.Atoi(l)
+			if err != nil {
+				continue
+			}
+			for _, po := range []struct {
+				proto  string
+				offset int
+			}{{"tcp", 0}, {"udp", 0}, {"wg", 1}, {"ws", 1}, {"wss", 2}} {
+				out = append(out, fmt.Sprintf("%s://%s", po.proto, net.JoinHostPort(bindAddr, strconv.Itoa(port+po.offset))))
+			}
+			continue
+		}
+		if strings.Contains(l, "://") {
+			out = append(out, l)
+			continue
+		}
+		parts := strings.SplitN(l, ":", 2)
+		out = append(out, fmt.Sprintf("%s://%s", parts[0], net.JoinHostPort(bindAddr, parts[1])))
+	}
+	return out
+}
+
+// rpcPortalAddr 将 RpcPortal 配置转换为 config.toml 中的 rpc_portal 地址（仅端口时监听本机）
+func rpcPortalAddr(portal string) string {
+	if _, err := strconv.Atoi(portal); err == nil {
+		return net.JoinHostPort("127.0.0.1", portal)
+	}
+	return portal
+}
+
+// parsePortForward 解析端口转发：proto:bind_ip:bind_port:dst_ip:dst_port 或 proto://bind_ip:bind_port/dst_ip:dst_port
+func parsePortForward(pf string) (map[string]any, bool) {
+	if u, err := url.Parse(pf); err == nil && u.Scheme != "" && u.Host != "" {
+		return map[string]any{
+			"proto":     u.Scheme,
+			"bind_addr": u.Host,
+			"dst_addr":  strings.TrimPrefix(u.Path, "/"),
+		}, true
+	}
+	parts := strings.Split(pf, ":")
+	if len(parts) != 5 {
+		return nil, false
+	}
+	return map[string]any{
+		"proto":     parts[0],
+		"bind_addr": net.JoinHostPort(parts[1], parts[2]),
+		"dst_addr":  net.JoinHostPort(parts[3], parts[4]),
+	}, true
+}
+
+// commonOptions 客户端与服务端共有的配置项
+type commonOptions struct {
+	hostname, instanceName                             string
+	rpcPortal, rpcPortalWhitelist                      string
+	noTun, disableP2P, relayAllPeerRpc, enableExitNode bool
+	proxyForwardBySystem                               bool
+	defaultProtocol                                    string
+	enableKcpProxy, disableKcpInput                    bool
+	enableQuicProxy, disableQuicInput                  bool
+	quicListenPort                                     int
+	disableEncryption, privateMode                     bool
+	encryptionAlgorithm                                string
+	relayNetworkWhitelist                              string
+	foreignRelayBpsLimit                               int64
+	disableRelayKcp, enableRelayForeignNetworkKcp      bool
+	tcpWhitelist, udpWhitelist, compression            string
+	stunServers, stunServersV6                         string
+	enableManualRoutes                                 bool
+	manualRoutes, portForwards                         string
+	consoleLogLevel, fileLogLevel, fileLogDir          string
+	fileLogSize, fileLogCount                          int
+	multiThread                                        bool
+	multiThreadCount                                   int
+}
+
+// apply 写入共有配置项
+func (o *commonOptions) apply(doc tomlDoc, flags map[string]any) {
+	if o.hostname != "" {
+		doc["hostname"] = o.hostname
+	}
+	if o.instanceName != "" {
+		doc["instance_name"] = o.instanceName
+	}
+	if o.rpcPortal != "" {
+		doc["rpc_portal"] = rpcPortalAddr(o.rpcPortal)
+	}
+	if list := splitList(o.rpcPortalWhitelist, ","); len(list) > 0 {
+		doc["rpc_portal_whitelist"] = list
+	}
+
+	setBool := func(key string, v bool) {
+		if v {
+			flags[key] = true
+		}
+	}
+	setBool("no_tun", o.noTun)
+	setBool("disable_p2p", o.disableP2P)
+	setBool("relay_all_peer_rpc", o.relayAllPeerRpc)
+	setBool("enable_exit_node", o.enableExitNode)
+	setBool("proxy_forward_by_system", o.proxyForwardBySystem)
+	setBool("enable_kcp_proxy", o.enableKcpProxy)
+	setBool("disable_kcp_input", o.disableKcpInput)
+	setBool("enable_quic_proxy", o.enableQuicProxy)
+	setBool("disable_quic_input", o.disableQuicInput)
+	setBool("private_mode", o.privateMode)
+	setBool("disable_relay_kcp", o.disableRelayKcp)
+	setBool("enable_relay_foreign_network_kcp", o.enableRelayForeignNetworkKcp)
+	setBool("multi_thread", o.multiThread)
+	if o.multiThread && o.multiThreadCount > 2 {
+		flags["multi_thread_count"] = o.multiThreadCount
+	}
+	if o.defaultProtocol != "" {
+		flags["default_protocol"] = o.defaultProtocol
+	}
+	if o.quicListenPort > 0 {
+		flags["quic_listen_port"] = o.quicListenPort
+	}
+	if o.disableEncryption {
+		flags["enable_encryption"] = false
+	}
+	if o.encryptionAlgorithm != "" {
+		flags["encryption_algorithm"] = o.encryptionAlgorithm
+	}
+	if o.relayNetworkWhitelist != "" {
+		// config.toml 中以空格分隔
+		flags["relay_network_whitelist"] = strings.Join(splitList(o.relayNetworkWhitelist, ", "), " ")
+	}
+	if o.foreignRelayBpsLimit > 0 {
+		flags["foreign_relay_bps_limit"] = o.foreignRelayBpsLimit
+	}
+	switch o.compression {
+	case "zstd":
+		flags["data_compress_algo"] = compressAlgoZstd
+	}
+
+	if list := splitList(o.tcpWhitelist, ","); len(list) > 0 {
+		doc["tcp_whitelist"] = list
+	}
+	if list := splitList(o.udpWhitelist, ","); len(list) > 0 {
+		doc["udp_whitelist"] = list
+	}
+	if list := splitList(o.stunServers, ","); len(list) > 0 {
+		doc["stun_servers"] = list
+	}
+	if list := splitList(o.stunServersV6, ","); len(list) > 0 {
+		doc["stun_servers_v6"] = list
+	}
+	if o.enableManualRoutes {
+		if list := splitList(o.manualRoutes, ","); len(list) > 0 {
+			doc["routes"] = list
+		}
+	}
+
+	var forwards []map[string]any
+	for _, pf := range splitList(o.portForwards, "\n") {
+		if f, ok := parsePortForward(pf); ok {
+			forwards = append(forwards, f)
+		}
+	}
+	if len(forwards) > 0 {
+		doc["port_forward"] = forwards
+	}
+
+	if o.consoleLogLevel != "" {
+		doc["console_logger"] = map[string]any{"level": o.consoleLogLevel}
+	}
+	if o.fileLogLevel != "" || o.fileLogDir != "" {
+		fl := map[string]any{}
+		if o.fileLogLevel != "" {
+			fl["level"] = o.fileLogLevel
+		}
+		if o.fileLogDir != "" {
+			fl["dir"] = o.fileLogDir
+		}
+		if o.fileLogSize > 0 {
+			fl["size_mb"] = o.fileLogSize
+		}
+		if o.fileLogCount > 0 {
+			fl["count"] = o.fileLogCount
+		}
+		doc["file_logger"] = fl
+	}
+}
+
+// buildClientConfig 生成客户端 config.toml
+func buildClientConfig(cfg *model.EasytierClient) (tomlDoc, error) {
+	doc := tomlDoc{}
+	flags := map[string]any{}
+
+	o := commonOptions{
+		hostname:                     cfg.Hostname,
+		instanceName:                 cfg.InstanceName,
+		rpcPortal:                    cfg.RpcPortal,
+		rpcPortalWhitelist:           cfg.RpcPortalWhitelist,
+		noTun:                        cfg.NoTun,
+		disableP2P:                   cfg.DisableP2P,
+		relayAllPeerRpc:              cfg.RelayAllPeerRpc,
+		enableExitNode:               cfg.EnableExitNode,
+		proxyForwardBySystem:         cfg.ProxyForwardBySystem,
+		defaultProtocol:              cfg.DefaultProtocol,
+		enableKcpProxy:               cfg.EnableKcpProxy,
+		disableKcpInput:              cfg.DisableKcpInput,
+		enableQuicProxy:              cfg.EnableQuicProxy,
+		disableQuicInput:             cfg.DisableQuicInput,
+		quicListenPort:               cfg.QuicListenPort,
+		disableEncryption:            cfg.DisableEncryption,
+		privateMode:                  cfg.PrivateMode,
+		encryptionAlgorithm:          cfg.EncryptionAlgorithm,
+		relayNetworkWhitelist:        cfg.RelayNetworkWhitelist,
+		foreignRelayBpsLimit:         cfg.ForeignRelayBpsLimit,
+		disableRelayKcp:              cfg.DisableRelayKcp,
+		enableRelayForeignNetworkKcp: cfg.EnableRelayForeignNetworkKcp,
+		tcpWhitelist:                 cfg.TcpWhitelist,
+		udpWhitelist:                 cfg.UdpWhitelist,
+		compression:                  cfg.Compression,
+		stunServers:                  cfg.StunServers,
+		stunServersV6:                cfg.StunServersV6,
+		enableManualRoutes:           cfg.EnableManualRoutes,
+		manualRoutes:                 cfg.ManualRoutes,
+		portForwards:                 cfg.PortForwards,
+		consoleLogLevel:              cfg.ConsoleLogLevel,
+		fileLogLevel:                 cfg.FileLogLevel,
+		fileLogDir:                   cfg.FileLogDir,
+		fileLogSize:                  cfg.FileLogSize,
+		fileLogCount:                 cfg.FileLogCount,
+		multiThread:                  cfg.MultiThread,
+		multiThreadCount:             cfg.MultiThreadCount,
+	}
+	o.apply(doc, flags)
+
+	// ===== 网络设置 =====
+	if cfg.NetworkName != "" || cfg.NetworkPassword != "" {
+		doc["network_identity"] = map[string]any{
+			"network_name":   cfg.NetworkName,
+			"network_secret": cfg.NetworkPassword,
+		}
+	}
+	if cfg.EnableDhcp {
+		doc["dhcp"] = true
+	} else if cfg.VirtualIP != "" {
+		doc["ipv4"] = cfg.VirtualIP
+	}
+	if cfg.IPv6 != "" {
+		doc["ipv6"] = cfg.IPv6
+	}
+
+	// 对等节点（服务器地址与公共共享节点）
+	var peers []map[string]any
+	for _, uri := range append(splitList(cfg.ServerAddr, ","), splitList(cfg.ExternalNodes, ",")...) {
+		peers = append(peers, map[string]any{"uri": uri})
+	}
+	if len(peers) > 0 {
+		doc["peer"] = peers
+	}
+
+	// ===== 监听器 =====
+	// 显式写出空列表，等价于 --no-listener；省略该键时 easytier 会使用默认监听端口
+	listeners := []string{}
+	if !cfg.NoListener {
+		listeners = append(listeners, expandListeners(cfg.ListenPorts, "")...)
+	}
+	doc["listeners"] = listeners
+	if list := splitList(cfg.MappedListeners, ","); len(list) > 0 {
+		doc["mapped_listeners"] = list
+	}
+
+	// ===== 子网代理 =====
+	var proxies []map[string]any
+	for _, cidr := range splitList(cfg.ProxyCidrs, ",") {
+		pn := map[string]any{"cidr": cidr}
+		if real, mapped, ok := strings.Cut(cidr, "->"); ok {
+			pn["cidr"] = strings.TrimSpace(real)
+			pn["mapped_cidr"] = strings.TrimSpace(mapped)
+		}
+		proxies = append(proxies, pn)
+	}
+	if len(proxies) > 0 {
+		doc["proxy_network"] = proxies
+	}
+	if list := splitList(cfg.ExitNodes, ","); len(list) > 0 {
+		doc["exit_nodes"] = list
+	}
+
+	// ===== 其他开关 =====
+	if cfg.P2POnly {
+		flags["p2p_only"] = true
+	}
+	if cfg.LatencyFirst {
+		flags["latency_first"] = true
+	}
+	if cfg.DisableUdpHolePunching {
+		flags["disable_udp_hole_punching"] = true
+	}
+	if cfg.DisableTcpHolePunching {
+		flags["disable_tcp_hole_punching"] = true
+	}
+	if cfg.DisableSymHolePunching {
+		flags["disable_sym_hole_punching"] = true
+	}
+	if cfg.DevName != "" {
+		flags["dev_name"] = cfg.DevName
+	}
+	if cfg.UseSmoltcp {
+		flags["use_smoltcp"] = true
+	}
+	if cfg.DisableIpv6 {
+		flags["enable_ipv6"] = false
+	}
+	if cfg.Mtu > 0 {
+		flags["mtu"] = cfg.Mtu
+	}
+	if cfg.AcceptDns {
+		flags["accept_dns"] = true
+		if cfg.TldDnsZone != "" {
+			flags["tld_dns_zone"] = cfg.TldDnsZone
+		}
+	}
+	if cfg.BindDevice != "" {
+		// config.toml 中 bind_device 为布尔值，表示是否绑定物理网卡
+		bind, err := strconv.ParseBool(cfg.BindDevice)
+		flags["bind_device"] = err != nil || bind
+	}
+
+	// ===== VPN 门户 =====
+	if cfg.EnableVpnPortal && cfg.VpnPortalListenPort > 0 && cfg.VpnPortalClientNetwork != "" {
+		doc["vpn_portal_config"] = map[string]any{
+			"client_cidr":      cfg.VpnPortalClientNetwork,
+			"wireguard_listen": net.JoinHostPort("0.0.0.0", strconv.Itoa(cfg.VpnPortalListenPort)),
+		}
+	}
+
+	// ===== SOCKS5 代理 =====
+	if cfg.EnableSocks5 && cfg.Socks5Port > 0 {
+		doc["socks5_proxy"] = "socks5://" + net.JoinHostPort("0.0.0.0", strconv.Itoa(cfg.Socks5Port))
+	}
+
+	if len(flags) > 0 {
+		doc["flags"] = flags
+	}
+	if err := mergeExtraConfig(doc, cfg.ExtraConfig); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// buildServerConfig 生成服务端（standalone 模式）config.toml
+func buildServerConfig(cfg *model.EasytierServer) (tomlDoc, error) {
+	doc := tomlDoc{}
+	flags := map[string]any{}
+
+	// 服务端必须设置中继白名单，否则 easytier-core 会以普通节点模式启动并立即退出。
+	// 默认值 "*" 表示中继所有网络
+	relayWhitelist := cfg.RelayNetworkWhitelist
+	if relayWhitelist == "" {
+		relayWhitelist = "*"
+	}
+	o := commonOptions{
+		hostname:                     cfg.Hostname,
+		instanceName:                 cfg.InstanceName,
+		rpcPortal:                    cfg.RpcPortal,
+		rpcPortalWhitelist:           cfg.RpcPortalWhitelist,
+		noTun:                        cfg.NoTun,
+		disableP2P:                   cfg.DisableP2P,
+		relayAllPeerRpc:              cfg.RelayAllPeerRpc,
+		enableExitNode:               cfg.EnableExitNode,
+		proxyForwardBySystem:         cfg.ProxyForwardBySystem,
+		defaultProtocol:              cfg.DefaultProtocol,
+		enableKcpProxy:               cfg.EnableKcpProxy,
+		disableKcpInput:              cfg.DisableKcpInput,
+		enableQuicProxy:              cfg.EnableQuicProxy,
+		disableQuicInput:             cfg.DisableQuicInput,
+		quicListenPort:               cfg.QuicListenPort,
+		disableEncryption:            cfg.DisableEncryption,
+		privateMode:                  cfg.PrivateMode,
+		encryptionAlgorithm:          cfg.EncryptionAlgorithm,
+		relayNetworkWhitelist:        relayWhitelist,
+		foreignRelayBpsLimit:         cfg.ForeignRelayBpsLimit,
+		disableRelayKcp:              cfg.DisableRelayKcp,
+		enableRelayForeignNetworkKcp: cfg.EnableRelayForeignNetworkKcp,
+		tcpWhitelist:                 cfg.TcpWhitelist,
+		udpWhitelist:                 cfg.UdpWhitelist,
+		compression:                  cfg.Compression,
+		stunServers:                  cfg.StunServers,
+		stunServersV6:                cfg.StunServersV6,
+		enableManualRoutes:           cfg.EnableManualRoutes,
+		manualRoutes:                 cfg.ManualRoutes,
+		portForwards:                 cfg.PortForwards,
+		consoleLogLevel:              cfg.ConsoleLogLevel,
+		fileLogLevel:                 cfg.FileLogLevel,
+		fileLogDir:                   cfg.FileLogDir,
+		fileLogSize:                  cfg.FileLogSize,
+		fileLogCount:                 cfg.FileLogCount,
+		multiThread:                  cfg.MultiThread,
+		multiThreadCount:             cfg.MultiThreadCount,
+	}
+	o.apply(doc, flags)
+
+	if listeners := expandListeners(cfg.ListenPorts, cfg.ListenAddr); len(listeners) > 0 {
+		doc["listeners"] = listeners
+	}
+	doc["flags"] = flags
+	if err := mergeExtraConfig(doc, cfg.ExtraConfig); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergeExtraConfig 将自定义 TOML 合并到生成的配置中（表逐键合并，其余值直接覆盖），
+// 用于填写 NetPanel 未提供表单项的 easytier 配置
+func mergeExtraConfig(doc tomlDoc, extra string) error {
+	if strings.TrimSpace(extra) == "" {
+		return nil
+	}
+	var extraDoc map[string]any
+	if err := toml.Unmarshal([]byte(extra), &extraDoc); err != nil {
+		return fmt.Errorf("自定义配置不是有效的 TOML: %w", err)
+	}
+	for k, v := range extraDoc {
+		if sub, ok := v.(map[string]any); ok {
+			if cur, ok := doc[k].(map[string]any); ok {
+				for sk, sv := range sub {
+					cur[sk] = sv
+				}
+				continue
+			}
+		}
+		doc[k] = v
+	}
+	return nil
+}
+
+// writeConfigFile 写入实例配置文件，返回文件路径
+func (m *Manager) writeConfigFile(name string, doc tomlDoc) (string, error) {
+	dir := filepath.Join(m.dataDir, "easytier")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	data, err := toml.Marshal(map[string]any(doc))
+	if err != nil {
+		return "", fmt.Errorf("生成配置文件失败: %w", err)
+	}
+	path := filepath.Join(dir, name)
+	// 配置中包含网络密钥，仅限当前用户读取
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return path, nil
+}
+
+// ExportClientConfig 导出客户端 config.toml
+func (m *Manager) ExportClientConfig(id uint) ([]byte, error) {
+	var cfg model.EasytierClient
+	if err := m.db.First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("EasyTier 客户端配置不存在: %w", err)
+	}
+	doc, err := buildClientConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return toml.Marshal(map[string]any(doc))
+}
+
+// ExportServerConfig 导出服务端 config.toml（config-server 节点模式无本地配置文件）
+func (m *Manager) ExportServerConfig(id uint) ([]byte, error) {
+	var cfg model.EasytierServer
+	if err := m.db.First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("EasyTier 服务端配置不存在: %w", err)
+	}
+	if cfg.ServerMode == "config-server" {
+		return nil, fmt.Errorf("config-server 节点模式的配置由 config-server 下发，无本地配置文件")
+	}
+	doc, err := buildServerConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return toml.Marshal(map[string]any(doc))
+}
+
+// ===== 导入 =====
+
+// ImportClientConfig 导入 easytier config.toml 为客户端配置，无法对应到表单项的内容保留到自定义配置中
+func (m *Manager) ImportClientConfig(name string, content []byte) (*model.EasytierClient, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("解析 TOML 配置失败: %w", err)
+	}
+	cfg, err := clientConfigFromDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Name = name
+	if cfg.Name == "" {
+		cfg.Name = cfg.InstanceName
+	}
+	if cfg.Name == "" {
+		cfg.Name = "导入-" + cfg.NetworkName
+	}
+	if err := m.db.Create(cfg).Error; err != nil {
+		return nil, fmt.Errorf("保存配置失败: %w", err)
+	}
+	return cfg, nil
+}
+
+// tomlReader 从 TOML 文档中按键取值，取出的键会被删除，剩余内容即为未识别的配置
+type tomlReader map[string]any
+
+func (r tomlReader) str(key string) string {
+	v, ok := r[key]
+	if !ok {
+		return ""
+	}
+	delete(r, key)
+	switch val := v.(type) {
+	case string:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func (r tomlReader) boolean(key string) bool {
+	v, ok := r[key].(bool)
+	if ok {
+		delete(r, key)
+	}
+	return v
+}
+
+func (r tomlReader) integer(key string) int64 {
+	v, ok := r[key].(int64)
+	if ok {
+		delete(r, key)
+	}
+	return v
+}
+
+func (r tomlReader) list(key string) []string {
+	arr, ok := r[key].([]any)
+	if !ok {
+		return nil
+	}
+	delete(r, key)
+	out := make([]string, 0, len(arr))
+	for _, v := range arr {
+		out = append(out, fmt.Sprint(v))
+	}
+	return out
+}
+
+func (r tomlReader) table(key string) tomlReader {
+	t, ok := r[key].(map[string]any)
+	if !ok {
+		return tomlReader{}
+	}
+	return t
+}
+
+// tables 取出表数组（如 [[peer]]）
+func (r tomlReader) tables(key string) []tomlReader {
+	arr, ok := r[key].([]any)
+	if !ok {
+		return nil
+	}
+	delete(r, key)
+	out := make([]tomlReader, 0, len(arr))
+	for _, v := range arr {
+		if t, ok := v.(map[string]any); ok {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// dropEmpty 删除已全部读取的表
+func (r tomlReader) dropEmpty(key string) {
+	if t, ok := r[key].(map[string]any); ok && len(t) == 0 {
+		delete(r, key)
+	}
+}
+
+func clientConfigFromDoc(doc map[string]any) (*model.EasytierClient, error) {
+	r := tomlReader(doc)
+	cfg := &model.EasytierClient{}
+
+	cfg.Hostname = r.str("hostname")
+	cfg.InstanceName = r.str("instance_name")
+	// instance_id 每次由 easytier 自动生成即可
+	delete(r, "instance_id")
+	cfg.VirtualIP = r.str("ipv4")
+	cfg.IPv6 = r.str("ipv6")
+	cfg.EnableDhcp = r.boolean("dhcp")
+
+	ni := r.table("network_identity")
+	cfg.NetworkName = ni.str("network_name")
+	cfg.NetworkPassword = ni.str("network_secret")
+	r.dropEmpty("network_identity")
+
+	var peers []string
+	for _, p := range r.tables("peer") {
+		if uri := p.str("uri"); uri != "" {
+			peers = append(peers, uri)
+		}
+	}
+	cfg.ServerAddr = strings.Join(peers, ",")
+
+	if _, ok := r["listeners"]; ok {
+		cfg.ListenPorts = strings.Join(r.list("listeners"), ",")
+	}
+	cfg.NoListener = cfg.ListenPorts == ""
+	cfg.MappedListeners = strings.Join(r.list("mapped_listeners"), ",")
+	cfg.ExitNodes = strings.Join(r.list("exit_nodes"), ",")
+
+	var proxies []string
+	for _, pn := range r.tables("proxy_network") {
+		cidr := pn.str("cidr")
+		if mapped := pn.str("mapped_cidr"); mapped != "" {
+			cidr += "->" + mapped
+		}
+		if cidr != "" {
+			proxies = append(proxies, cidr)
+		}
+	}
+	cfg.ProxyCidrs = strings.Join(proxies, ",")
+
+	cfg.RpcPortal = r.str("rpc_portal")
+	cfg.RpcPortalWhitelist = strings.Join(r.list("rpc_portal_whitelist"), ",")
+
+	if vpn := r.table("vpn_portal_config"); len(vpn) > 0 {
+		cfg.VpnPortalClientNetwork = vpn.str("client_cidr")
+		if _, port, err := net.SplitHostPort(vpn.str("wireguard_listen")); err == nil {
+			cfg.VpnPortalListenPort, _ = strconv.Atoi(port)
+		}
+		cfg.EnableVpnPortal = cfg.VpnPortalClientNetwork != "" && cfg.VpnPortalListenPort > 0
+		r.dropEmpty("vpn_portal_config")
+	}
+
+	if routes := r.list("routes"); len(routes) > 0 {
+		cfg.EnableManualRoutes = true
+		cfg.ManualRoutes = strings.Join(routes, ",")
+	}
+
+	if s := r.str("socks5_proxy"); s != "" {
+		if u, err := url.Parse(s); err == nil {
+			cfg.Socks5Port, _ = strconv.Atoi(u.Port())
+			cfg.EnableSocks5 = cfg.Socks5Port > 0
+		}
+	}
+
+	var forwards []string
+	for _, pf := range r.tables("port_forward") {
+		bindHost, bindPort, err1 := net.SplitHostPort(pf.str("bind_addr"))
+		dstHost, dstPort, err2 := net.SplitHostPort(pf.str("dst_addr"))
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		proto := pf.str("proto")
+		if proto == "" {
+			proto = "tcp"
+		}
+		forwards = append(forwards, strings.Join([]string{proto, bindHost, bindPort, dstHost, dstPort}, ":"))
+	}
+	cfg.PortForwards = strings.Join(forwards, "\n")
+
+	cfg.TcpWhitelist = strings.Join(r.list("tcp_whitelist"), ",")
+	cfg.UdpWhitelist = strings.Join(r.list("udp_whitelist"), ",")
+	cfg.StunServers = strings.Join(r.list("stun_servers"), ",")
+	cfg.StunServersV6 = strings.Join(r.list("stun_servers_v6"), ",")
+
+	cl := r.table("console_logger")
+	cfg.ConsoleLogLevel = cl.str("level")
+	r.dropEmpty("console_logger")
+	fl := r.table("file_logger")
+	cfg.FileLogLevel = fl.str("level")
+	cfg.FileLogDir = fl.str("dir")
+	cfg.FileLogSize = int(fl.integer("size_mb"))
+	cfg.FileLogCount = int(fl.integer("count"))
+	r.dropEmpty("file_logger")
+
+	f := r.table("flags")
+	cfg.NoTun = f.boolean("no_tun")
+	cfg.DisableP2P = f.boolean("disable_p2p")
+	cfg.P2POnly = f.boolean("p2p_only")
+	cfg.LatencyFirst = f.boolean("latency_first")
+	cfg.EnableExitNode = f.boolean("enable_exit_node")
+	cfg.RelayAllPeerRpc = f.boolean("relay_all_peer_rpc")
+	cfg.ProxyForwardBySystem = f.boolean("proxy_forward_by_system")
+	cfg.DefaultProtocol = f.str("default_protocol")
+	cfg.DisableUdpHolePunching = f.boolean("disable_udp_hole_punching")
+	cfg.DisableTcpHolePunching = f.boolean("disable_tcp_hole_punching")
+	cfg.DisableSymHolePunching = f.boolean("disable_sym_hole_punching")
+	cfg.EnableKcpProxy = f.boolean("enable_kcp_proxy")
+	cfg.DisableKcpInput = f.boolean("disable_kcp_input")
+	cfg.EnableQuicProxy = f.boolean("enable_quic_proxy")
+	cfg.DisableQuicInput = f.boolean("disable_quic_input")
+	cfg.QuicListenPort = int(f.integer("quic_listen_port"))
+	cfg.DevName = f.str("dev_name")
+	cfg.UseSmoltcp = f.boolean("use_smoltcp")
+	if v, ok := f["enable_ipv6"].(bool); ok {
+		cfg.DisableIpv6 = !v
+		delete(f, "enable_ipv6")
+	}
+	cfg.Mtu = int(f.integer("mtu"))
+	cfg.AcceptDns = f.boolean("accept_dns")
+	cfg.TldDnsZone = f.str("tld_dns_zone")
+	if v, ok := f["bind_device"].(bool); ok {
+		cfg.BindDevice = strconv.FormatBool(v)
+		delete(f, "bind_device")
+	}
+	if v, ok := f["enable_encryption"].(bool); ok {
+		cfg.DisableEncryption = !v
+		delete(f, "enable_encryption")
+	}
+	cfg.EncryptionAlgorithm = f.str("encryption_algorithm")
+	cfg.PrivateMode = f.boolean("private_mode")
+	cfg.RelayNetworkWhitelist = strings.Join(strings.Fields(f.str("relay_network_whitelist")), ",")
+	cfg.ForeignRelayBpsLimit = f.integer("foreign_relay_bps_limit")
+	cfg.DisableRelayKcp = f.boolean("disable_relay_kcp")
+	cfg.EnableRelayForeignNetworkKcp = f.boolean("enable_relay_foreign_network_kcp")
+	switch f.integer("data_compress_algo") {
+	case compressAlgoZstd:
+		cfg.Compression = "zstd"
+	case compressAlgoNone:
+		cfg.Compression = "none"
+	}
+	cfg.MultiThread = f.boolean("multi_thread")
+	cfg.MultiThreadCount = int(f.integer("multi_thread_count"))
+	r.dropEmpty("flags")
+
+	// 剩余未识别的配置保留到自定义配置中，启动时原样合并
+	if len(r) > 0 {
+		extra, err := toml.Marshal(map[string]any(r))
+		if err != nil {
+			return nil, fmt.Errorf("保存未识别的配置项失败: %w", err)
+		}
+		cfg.ExtraConfig = string(extra)
+	}
+	return cfg, nil
+}
@@ -1,27 +1,23 @@
 package easytier
 
 import (
-	"bytes"
-	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/pkg/supervisor"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type processEntry struct {
-	cmd    *exec.Cmd
-	cancel context.CancelFunc
-	done   chan struct{} // 进程退出后关闭，用于等待进程完全退出
+	proc *supervisor.Process
+	rpc  *rpcPoller // 仅客户端：RPC 门户状态轮询
 }
 
 // Manager EasyTier 管理器（命令行进程管理）
@@ -31,13 +27,16 @@ type Manager struct {
 	dataDir  string
 	clients  sync.Map // map[uint]*processEntry
 	servers  sync.Map // map[uint]*processEntry
+	webs     sync.Map // map[uint]*webEntry 托管的 config-server
+	portals  sync.Map // map[portalKey]string 自动分配的 RPC 门户地址，重启后保持不变
 	stopping bool     // 标记是否正在关闭，关闭期间禁止自动重启
 	mu       sync.Mutex
+	binary   binaryStore // easytier 发行包版本管理
 }
 
-// isWinPcapPanic 检测 stderr 输出中是否包含 WinPcap/Npcap 接口枚举失败的 panic 信息
+// isWinPcapPanic 检测进程输出中是否包含 WinPcap/Npcap 接口枚举失败的 panic 信息
 // EasyTier 进程 panic 时，详细信息输出到 stderr，cmd.Wait() 返回的 error 仅为退出码，
-// 因此必须通过捕获 stderr 内容来判断崩溃原因。
+// 因此必须通过 supervisor 捕获的输出内容来判断崩溃原因。
 func isWinPcapPanic(stderr string) bool {
 	msg := strings.ToLower(stderr)
 	return strings.Contains(msg, "unable to get interface list") ||
@@ -92,6 +91,17 @@ func (m *Manager) StartAll() {
 				}
 			}()
 		}
+
+		var webs []model.EasytierWebServer
+		m.db.Where("enable = ?", true).Find(&webs)
+		for _, w := range webs {
+			w := w
+			go func() {
+				if err := m.StartWebServer(w.ID); err != nil {
+					m.log.Errorf("EasyTier 配置服务器 [%s] 启动失败: %v", w.Name, err)
+				}
+			}()
+		}
 	}()
 }
 
@@ -103,29 +113,24 @@ func (m *Manager) StopAll() {
 
 	var wg sync.WaitGroup
 
-	m.clients.Range(func(key, value interface{}) bool {
+	stop := func(key, value interface{}) bool {
 		entry := value.(*processEntry)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			entry.cancel()
-			if entry.cmd.Process != nil {
-				_ = entry.cmd.Process.Kill()
-			}
-			_ = entry.cmd.Wait()
+			entry.proc.Stop()
 		}()
 		return true
-	})
-	m.servers.Range(func(key, value interface{}) bool {
-		entry := value.(*processEntry)
+	}
+	m.clients.Range(stop)
+	m.servers.Range(stop)
+	m.webs.Range(func(key, value interface{}) bool {
+		entry := value.(*webEntry)
+		entry.cancel()
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			entry.cancel()
-			if entry.cmd.Process != nil {
-				_ = entry.cmd.Process.Kill()
-			}
-			_ = entry.cmd.Wait()
+			entry.proc.Stop()
 		}()
 		return true
 	})
@@ -135,7 +140,17 @@ func (m *Manager) StopAll() {
 
 // ===== 客户端 =====
 
+// StartClient 启动客户端，easytier-core 缺失时自动安装
 func (m *Manager) StartClient(id uint) error {
+	if err := m.ensureBinary(); err != nil {
+		return err
+	}
+	m.binary.switching.RLock()
+	defer m.binary.switching.RUnlock()
+	return m.startClient(id)
+}
+
+func (m *Manager) startClient(id uint) error {
 	m.StopClient(id)
 
 	if !m.isBinaryAvailable() {
@@ -147,398 +162,65 @@ func (m *Manager) StartClient(id uint) error {
 		return fmt.Errorf("EasyTier 客户端配置不存在: %w", err)
 	}
 
-	args := m.buildClientArgs(&cfg)
-	ctx, cancel := context.WithCancel(context.Background())
-	cmd := exec.CommandContext(ctx, m.getBinaryPath(), args...)
-	// 设置工作目录为二进制文件所在目录，确保能找到 wintun.dll 等依赖文件
-	cmd.Dir = filepath.Dir(m.getBinaryPath())
-	// 使用 bytes.Buffer 捕获 stdout/stderr，同时保留输出到控制台
-	var stdoutBuf bytes.Buffer
-	var stderrBuf bytes.Buffer
-	cmd.Stdout = io.MultiWriter(os.Stdout, &stdoutBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
-
-	if err := cmd.Start(); err != nil {
-		cancel()
-		m.db.Model(&model.EasytierClient{}).Where("id = ?", id).Updates(map[string]interface{}{
-			"status":     "error",
-			"last_error": err.Error(),
-		})
-		return fmt.Errorf("启动 EasyTier 客户端失败: %w", err)
+	// 未配置 RPC 门户时自动分配本机端口，用于查询节点、路由等运行状态
+	portal, err := m.rpcPortalFor("client", id, cfg.RpcPortal)
+	if err != nil {
+		return err
 	}
 
-	entry := &processEntry{cmd: cmd, cancel: cancel, done: make(chan struct{})}
-	m.clients.Store(id, entry)
-
-	go func() {
-		err := cmd.Wait()
-		// 进程已退出，立即关闭 done channel，通知 StopClient 端口等资源已释放
-		close(entry.done)
-		stderrOutput := stderrBuf.String()
-		m.clients.Delete(id)
-		if err != nil {
-			errMsg := fmt.Sprintf("进程异常退出: %v", err)
-			m.log.Warnf("[EasyTier客户端][%d] %s", id, errMsg)
-			if stdoutOutput := stdoutBuf.String(); stdoutOutput != "" {
-				m.log.Warnf("[EasyTier客户端][%d] stdout输出:\n%s", id, stdoutOutput)
-			}
-			m.db.Model(&model.EasytierClient{}).Where("id = ?", id).Updates(map[string]interface{}{
-				"status":     "error",
-				"last_error": errMsg,
-			})
-			// 自动重启（延迟5秒，避免快速循环崩溃）
-			time.Sleep(5 * time.Second)
-			// 关闭期间不自动重启
-			m.mu.Lock()
-			isStopping := m.stopping
-			m.mu.Unlock()
-			if isStopping {
-				return
-			}
-			var cur model.EasytierClient
-			if m.db.First(&cur, id).Error == nil && cur.Enable {
-				// 检测 WinPcap/Npcap 崩溃（通过 stderr 输出判断），自动开启 no_tun 选项
-				if isWinPcapPanic(stderrOutput) && !cur.NoTun {
-					m.log.Warnf("[EasyTier客户端][%d] 检测到 WinPcap/Npcap 崩溃，自动开启 --no-tun 模式", id)
-					m.db.Model(&model.EasytierClient{}).Where("id = ?", id).Update("no_tun", true)
-				}
-				m.log.Infof("[EasyTier客户端][%d] 尝试自动重启...", id)
-				if restartErr := m.StartClient(id); restartErr != nil {
-					m.log.Errorf("[EasyTier客户端][%d] 自动重启失败: %v", id, restartErr)
-				}
-			}
-		} else {
-			m.db.Model(&model.EasytierClient{}).Where("id = ?", id).Update("status", "stopped")
-			m.log.Infof("[EasyTier客户端][%d] 进程已退出", id)
+	return m.startProcess(&m.clients, id, "EasyTier客户端", &model.EasytierClient{}, rpcQueryAddr(portal), func() ([]string, bool, bool, error) {
+		var cur model.EasytierClient
+		if err := m.db.First(&cur, id).Error; err != nil {
+			return nil, false, false, err
 		}
-	}()
-
-	m.db.Model(&model.EasytierClient{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":     "running",
-		"last_error": "",
+		cur.RpcPortal = portal
+		args, err := m.clientLaunchArgs(&cur)
+		return args, cur.Enable, cur.NoTun, err
 	})
-	m.log.Infof("[EasyTier客户端][%d] 已启动，PID: %d", id, cmd.Process.Pid)
-	return nil
 }
 
 func (m *Manager) StopClient(id uint) {
-	if val, ok := m.clients.Load(id); ok {
-		entry := val.(*processEntry)
-		entry.cancel()
-		if entry.cmd.Process != nil {
-			_ = entry.cmd.Process.Kill()
-		}
-		// 等待进程完全退出，确保端口等资源已释放
-		<-entry.done
-		m.clients.Delete(id)
-	}
+	m.stopProcess(&m.clients, id)
 	m.db.Model(&model.EasytierClient{}).Where("id = ?", id).Update("status", "stopped")
 }
 
 func (m *Manager) GetClientStatus(id uint) string {
-	if _, ok := m.clients.Load(id); ok {
-		return "running"
-	}
-	return "stopped"
+	return processStatus(&m.clients, id)
 }
 
-// buildClientArgs 构建 easytier-core 客户端命令行参数
-func (m *Manager) buildClientArgs(cfg *model.EasytierClient) []string {
-	var args []string
-
-	// ===== 运行时选项 =====
-	if cfg.MultiThread {
-		args = append(args, "--multi-thread")
-		if cfg.MultiThreadCount > 2 {
-			args = append(args, "--multi-thread-count", fmt.Sprintf("%d", cfg.MultiThreadCount))
-		}
-	}
-
-	// ===== 基本设置 =====
-	if cfg.Hostname != "" {
-		args = append(args, "--hostname", cfg.Hostname)
-	}
-	if cfg.InstanceName != "" {
-		args = append(args, "--instance-name", cfg.InstanceName)
-	}
-
-	// ===== 网络设置 =====
-	if cfg.NetworkName != "" {
-		args = append(args, "--network-name", cfg.NetworkName)
-	}
-	if cfg.NetworkPassword != "" {
-		args = append(args, "--network-secret", cfg.NetworkPassword)
-	}
-
-	// 虚拟 IP（DHCP 模式与手动指定互斥）
-	if cfg.EnableDhcp {
-		args = append(args, "--dhcp")
-	} else if cfg.VirtualIP != "" {
-		args = append(args, "--ipv4", cfg.VirtualIP)
-	}
-	if cfg.IPv6 != "" {
-		args = append(args, "--ipv6", cfg.IPv6)
-	}
-
-	// 服务器地址（支持多个）
-	if cfg.ServerAddr != "" {
-		for _, s := range strings.Split(cfg.ServerAddr, ",") {
-			s = strings.TrimSpace(s)
-			if s != "" {
-				args = append(args, "-p", s)
-			}
-		}
-	}
-	// 外部节点（公共共享节点）
-	if cfg.ExternalNodes != "" {
-		for _, s := range strings.Split(cfg.ExternalNodes, ",") {
-			s = strings.TrimSpace(s)
-			if s != "" {
-				args = append(args, "-e", s)
-			}
-		}
-	}
-
-	// ===== 监听器设置 =====
-	if cfg.NoListener || cfg.ListenPorts == "" {
-		// 未指定监听端口时默认不监听，避免占用随机端口
-		args = append(args, "--no-listener")
-	} else {
-		for _, p := range strings.Split(cfg.ListenPorts, ",") {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				args = append(args, "-l", p)
-			}
-		}
-	}
-	if cfg.MappedListeners != "" {
-		for _, ml := range strings.Split(cfg.MappedListeners, ",") {
-			ml = strings.TrimSpace(ml)
-			if ml != "" {
-				args = append(args, "--mapped-listeners", ml)
-			}
-		}
-	}
-
-	// ===== RPC 设置 =====
-	if cfg.RpcPortal != "" {
-		args = append(args, "--rpc-portal", cfg.RpcPortal)
-	}
-	if cfg.RpcPortalWhitelist != "" {
-		args = append(args, "--rpc-portal-whitelist", cfg.RpcPortalWhitelist)
-	}
-
-	// ===== 子网代理 =====
-	if cfg.ProxyCidrs != "" {
-		for _, cidr := range strings.Split(cfg.ProxyCidrs, ",") {
-			cidr = strings.TrimSpace(cidr)
-			if cidr != "" {
-				args = append(args, "--proxy-networks", cidr)
-			}
-		}
-	}
-
-	// ===== 出口节点 =====
-	if cfg.ExitNodes != "" {
-		for _, node := range strings.Split(cfg.ExitNodes, ",") {
-			node = strings.TrimSpace(node)
-			if node != "" {
-				args = append(args, "--exit-nodes", node)
-			}
-		}
-	}
-
-	// ===== 网络行为选项 =====
-	if cfg.LatencyFirst {
-		args = append(args, "--latency-first")
-	}
-	if cfg.DisableP2P {
-		args = append(args, "--disable-p2p")
-	}
-	if cfg.P2POnly {
-		args = append(args, "--p2p-only")
-	}
-	if cfg.EnableExitNode {
-		args = append(args, "--enable-exit-node")
-	}
-	if cfg.RelayAllPeerRpc {
-		args = append(args, "--relay-all-peer-rpc")
-	}
-	if cfg.ProxyForwardBySystem {
-		args = append(args, "--proxy-forward-by-system")
-	}
-	if cfg.DefaultProtocol != "" {
-		args = append(args, "--default-protocol", cfg.DefaultProtocol)
-	}
-
-	// ===== 打洞选项 =====
-	if cfg.DisableUdpHolePunching {
-		args = append(args, "--disable-udp-hole-punching")
-	}
-	if cfg.DisableTcpHolePunching {
-		args = append(args, "--disable-tcp-hole-punching")
-	}
-	if cfg.DisableSymHolePunching {
-		args = append(args, "--disable-sym-hole-punching")
-	}
-
-	// ===== 协议加速选项 =====
-	if cfg.EnableKcpProxy {
-		args = append(args, "--enable-kcp-proxy")
-	}
-	if cfg.DisableKcpInput {
-		args = append(args, "--disable-kcp-input")
-	}
-	if cfg.EnableQuicProxy {
-		args = append(args, "--enable-quic-proxy")
-	}
-	if cfg.DisableQuicInput {
-		args = append(args, "--disable-quic-input")
-	}
-	if cfg.QuicListenPort > 0 {
-		args = append(args, "--quic-listen-port", fmt.Sprintf("%d", cfg.QuicListenPort))
-	}
-
-	// ===== TUN/网卡选项 =====
-	if cfg.NoTun {
-		args = append(args, "--no-tun")
-	}
-	if cfg.DevName != "" {
-		args = append(args, "--dev-name", cfg.DevName)
-	}
-	if cfg.UseSmoltcp {
-		args = append(args, "--use-smoltcp")
-	}
-	if cfg.DisableIpv6 {
-		args = append(args, "--disable-ipv6")
-	}
-	if cfg.Mtu > 0 {
-		args = append(args, "--mtu", fmt.Sprintf("%d", cfg.Mtu))
-	}
-	if cfg.AcceptDns {
-		args = append(args, "--accept-dns")
-		if cfg.TldDnsZone != "" {
-			args = append(args, "--tld-dns-zone", cfg.TldDnsZone)
-		}
-	}
-	if cfg.BindDevice != "" {
-		args = append(args, "--bind-device", cfg.BindDevice)
-	}
-
-	// ===== 安全选项 =====
-	if cfg.DisableEncryption {
-		args = append(args, "--disable-encryption")
-	}
-	if cfg.EncryptionAlgorithm != "" {
-		args = append(args, "--encryption-algorithm", cfg.EncryptionAlgorithm)
-	}
-	if cfg.PrivateMode {
-		args = append(args, "--private-mode")
-	}
-
-	// ===== 中继选项 =====
-	if cfg.RelayNetworkWhitelist != "" {
-		args = append(args, "--relay-network-whitelist", cfg.RelayNetworkWhitelist)
-	}
-	if cfg.ForeignRelayBpsLimit > 0 {
-		args = append(args, "--foreign-relay-bps-limit", fmt.Sprintf("%d", cfg.ForeignRelayBpsLimit))
-	}
-	if cfg.DisableRelayKcp {
-		args = append(args, "--disable-relay-kcp")
-	}
-	if cfg.EnableRelayForeignNetworkKcp {
-		args = append(args, "--enable-relay-foreign-network-kcp")
-	}
-
-	// ===== 流量控制 =====
-	if cfg.TcpWhitelist != "" {
-		args = append(args, "--tcp-whitelist", cfg.TcpWhitelist)
-	}
-	if cfg.UdpWhitelist != "" {
-		args = append(args, "--udp-whitelist", cfg.UdpWhitelist)
-	}
-	if cfg.Compression != "" && cfg.Compression != "none" {
-		args = append(args, "--compression", cfg.Compression)
-	}
-
-	// ===== STUN 服务器 =====
-	if cfg.StunServers != "" {
-		for _, s := range strings.Split(cfg.StunServers, ",") {
-			s = strings.TrimSpace(s)
-			if s != "" {
-				args = append(args, "--stun-servers", s)
-			}
-		}
-	}
-	if cfg.StunServersV6 != "" {
-		for _, s := range strings.Split(cfg.StunServersV6, ",") {
-			s = strings.TrimSpace(s)
-			if s != "" {
-				args = append(args, "--stun-servers-v6", s)
-			}
-		}
-	}
-
-	// ===== VPN 门户 =====
-	if cfg.EnableVpnPortal && cfg.VpnPortalListenPort > 0 && cfg.VpnPortalClientNetwork != "" {
-		args = append(args, "--vpn-portal",
-			fmt.Sprintf("wg://0.0.0.0:%d/%s", cfg.VpnPortalListenPort, cfg.VpnPortalClientNetwork))
-	}
-
-	// ===== SOCKS5 代理 =====
-	if cfg.EnableSocks5 && cfg.Socks5Port > 0 {
-		args = append(args, "--socks5", fmt.Sprintf("%d", cfg.Socks5Port))
-	}
-
-	// ===== 手动路由 =====
-	if cfg.EnableManualRoutes && cfg.ManualRoutes != "" {
-		for _, route := range strings.Split(cfg.ManualRoutes, ",") {
-			route = strings.TrimSpace(route)
-			if route != "" {
-				args = append(args, "--manual-routes", route)
-			}
-		}
-	}
-
-	// ===== 端口转发 =====
-	if cfg.PortForwards != "" {
-		for _, pf := range strings.Split(cfg.PortForwards, "\n") {
-			pf = strings.TrimSpace(pf)
-			if pf != "" {
-				args = append(args, "--port-forward", pf)
-			}
-		}
-	}
+// GetClientProcess 获取客户端进程的守护状态（运行时长、重启次数、最近输出），未运行时返回 nil
+func (m *Manager) GetClientProcess(id uint) *supervisor.Status {
+	return processInfo(&m.clients, id)
+}
 
-	// ===== 日志选项 =====
-	if cfg.ConsoleLogLevel != "" {
-		args = append(args, "--console-log-level", cfg.ConsoleLogLevel)
-	}
-	if cfg.FileLogLevel != "" {
-		args = append(args, "--file-log-level", cfg.FileLogLevel)
+// clientLaunchArgs 生成客户端 config.toml，返回 easytier-core 启动参数
+func (m *Manager) clientLaunchArgs(cfg *model.EasytierClient) ([]string, error) {
+	doc, err := buildClientConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
-	if cfg.FileLogDir != "" {
-		args = append(args, "--file-log-dir", cfg.FileLogDir)
+	path, err := m.writeConfigFile(fmt.Sprintf("client_%d.toml", cfg.ID), doc)
+	if err != nil {
+		return nil, err
 	}
-	if cfg.FileLogSize > 0 {
-		args = append(args, "--file-log-size", fmt.Sprintf("%d", cfg.FileLogSize))
-	}
-	if cfg.FileLogCount > 0 {
-		args = append(args, "--file-log-count", fmt.Sprintf("%d", cfg.FileLogCount))
-	}
-
-	// 额外参数（兜底，用于不常用的高级参数）
-	if cfg.ExtraArgs != "" {
-		extraParts := strings.Fields(cfg.ExtraArgs)
-		args = append(args, extraParts...)
-	}
-
-	return args
+	args := []string{"-c", path}
+	// 额外参数（兜底，命令行参数会覆盖配置文件中的同名项）
+	return append(args, strings.Fields(cfg.ExtraArgs)...), nil
 }
 
 // ===== 服务端 =====
 
+// StartServer 启动服务端，easytier-core 缺失时自动安装
 func (m *Manager) StartServer(id uint) error {
+	if err := m.ensureBinary(); err != nil {
+		return err
+	}
+	m.binary.switching.RLock()
+	defer m.binary.switching.RUnlock()
+	return m.startServer(id)
+}
+
+func (m *Manager) startServer(id uint) error {
 	m.StopServer(id)
 
 	if !m.isBinaryAvailable() {
@@ -550,121 +232,58 @@ func (m *Manager) StartServer(id uint) error {
 		return fmt.Errorf("EasyTier 服务端配置不存在: %w", err)
 	}
 
-	args := m.buildServerArgs(&cfg)
-	m.log.Infof("[EasyTier服务端][%d] 启动命令: %s %s", id, m.getBinaryPath(), strings.Join(args, " "))
-	ctx, cancel := context.WithCancel(context.Background())
-	cmd := exec.CommandContext(ctx, m.getBinaryPath(), args...)
-	// 设置工作目录为二进制文件所在目录，确保能找到 wintun.dll 等依赖文件
-	cmd.Dir = filepath.Dir(m.getBinaryPath())
-	// 使用 bytes.Buffer 捕获 stdout/stderr，同时保留输出到控制台
-	var stdoutBuf bytes.Buffer
-	var stderrBuf bytes.Buffer
-	cmd.Stdout = io.MultiWriter(os.Stdout, &stdoutBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
-
-	if err := cmd.Start(); err != nil {
-		cancel()
-		m.db.Model(&model.EasytierServer{}).Where("id = ?", id).Updates(map[string]interface{}{
-			"status":     "error",
-			"last_error": err.Error(),
-		})
-		return fmt.Errorf("启动 EasyTier 服务端失败: %w", err)
+	// standalone 模式同样自动分配 RPC 门户，用于拓扑汇总；config-server 节点模式由 config-server 管理
+	var portal, rpcAddr string
+	if cfg.ServerMode != "config-server" {
+		var err error
+		if portal, err = m.rpcPortalFor("server", id, cfg.RpcPortal); err != nil {
+			return err
+		}
+		rpcAddr = rpcQueryAddr(portal)
 	}
 
-	entry := &processEntry{cmd: cmd, cancel: cancel, done: make(chan struct{})}
-	m.servers.Store(id, entry)
-
-	go func() {
-		err := cmd.Wait()
-		// 进程已退出，立即关闭 done channel，通知 StopServer 端口等资源已释放
-		close(entry.done)
-		stderrOutput := stderrBuf.String()
-		m.servers.Delete(id)
-		if err != nil {
-			errMsg := fmt.Sprintf("进程异常退出: %v", err)
-			m.log.Warnf("[EasyTier服务端][%d] %s", id, errMsg)
-			if stdoutOutput := stdoutBuf.String(); stdoutOutput != "" {
-				m.log.Warnf("[EasyTier服务端][%d] stdout输出:\n%s", id, stdoutOutput)
-			}
-			if stderrOutput != "" {
-				m.log.Warnf("[EasyTier服务端][%d] stderr输出:\n%s", id, stderrOutput)
-			}
-			m.db.Model(&model.EasytierServer{}).Where("id = ?", id).Updates(map[string]interface{}{
-				"status":     "error",
-				"last_error": errMsg,
-			})
-			// 自动重启（延迟5秒，避免快速循环崩溃）
-			time.Sleep(5 * time.Second)
-			// 关闭期间不自动重启
-			m.mu.Lock()
-			isStopping := m.stopping
-			m.mu.Unlock()
-			if isStopping {
-				return
-			}
-			var cur model.EasytierServer
-			if m.db.First(&cur, id).Error == nil && cur.Enable {
-				// 检测 WinPcap/Npcap 崩溃（通过 stderr 输出判断），自动开启 no_tun 选项
-				if isWinPcapPanic(stderrOutput) && !cur.NoTun {
-					m.log.Warnf("[EasyTier服务端][%d] 检测到 WinPcap/Npcap 崩溃，自动开启 --no-tun 模式", id)
-					m.db.Model(&model.EasytierServer{}).Where("id = ?", id).Update("no_tun", true)
-				}
-				m.log.Infof("[EasyTier服务端][%d] 尝试自动重启...", id)
-				if restartErr := m.StartServer(id); restartErr != nil {
-					m.log.Errorf("[EasyTier服务端][%d] 自动重启失败: %v", id, restartErr)
-				}
-			}
-		} else {
-			m.db.Model(&model.EasytierServer{}).Where("id = ?", id).Update("status", "stopped")
-			m.log.Infof("[EasyTier服务端][%d] 进程已退出", id)
+	return m.startProcess(&m.servers, id, "EasyTier服务端", &model.EasytierServer{}, rpcAddr, func() ([]string, bool, bool, error) {
+		var cur model.EasytierServer
+		if err := m.db.First(&cur, id).Error; err != nil {
+			return nil, false, false, err
 		}
-	}()
-
-	m.db.Model(&model.EasytierServer{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":     "running",
-		"last_error": "",
+		if portal != "" {
+			cur.RpcPortal = portal
+		}
+		args, err := m.serverLaunchArgs(&cur)
+		return args, cur.Enable, cur.NoTun, err
 	})
-	m.log.Infof("[EasyTier服务端][%d] 已启动，PID: %d", id, cmd.Process.Pid)
-	return nil
 }
 
 func (m *Manager) StopServer(id uint) {
-	if val, ok := m.servers.Load(id); ok {
-		entry := val.(*processEntry)
-		entry.cancel()
-		if entry.cmd.Process != nil {
-			_ = entry.cmd.Process.Kill()
-		}
-		// 等待进程完全退出，确保端口等资源已释放
-		<-entry.done
-		m.servers.Delete(id)
-	}
+	m.stopProcess(&m.servers, id)
 	m.db.Model(&model.EasytierServer{}).Where("id = ?", id).Update("status", "stopped")
 }
 
 func (m *Manager) GetServerStatus(id uint) string {
-	if _, ok := m.servers.Load(id); ok {
-		return "running"
-	}
-	return "stopped"
+	return processStatus(&m.servers, id)
 }
 
-// buildServerArgs 构建 easytier-core 服务端命令行参数
-func (m *Manager) buildServerArgs(cfg *model.EasytierServer) []string {
-	var args []string
+// GetServerProcess 获取服务端进程的守护状态（运行时长、重启次数、最近输出），未运行时返回 nil
+func (m *Manager) GetServerProcess(id uint) *supervisor.Status {
+	return processInfo(&m.servers, id)
+}
 
-	// ===== 运行时选项 =====
-	if cfg.MultiThread {
-		args = append(args, "--multi-thread")
-		if cfg.MultiThreadCount > 2 {
-			args = append(args, "--multi-thread-count", fmt.Sprintf("%d", cfg.MultiThreadCount))
-		}
-	}
+// serverLaunchArgs 返回 easytier-core 服务端启动参数
+// standalone 模式生成 config.toml；config-server 节点模式仅需命令行参数
+func (m *Manager) serverLaunchArgs(cfg *model.EasytierServer) ([]string, error) {
+	var args []string
 
 	// ===== config-server 节点模式 =====
 	// 节点模式下只需传入 --config-server 地址，其余参数由 config-server 下发，不再手动配置
 	// URL 格式：tcp://host:port/<token>，token 不能为空
 	if cfg.ServerMode == "config-server" && cfg.ConfigServerAddr != "" {
+		if cfg.MultiThread {
+			args = append(args, "--multi-thread")
+			if cfg.MultiThreadCount > 2 {
+				args = append(args, "--multi-thread-count", fmt.Sprintf("%d", cfg.MultiThreadCount))
+			}
+		}
 		for _, addr := range strings.Split(cfg.ConfigServerAddr, ",") {
 			addr = strings.TrimSpace(addr)
 			if addr == "" {
@@ -690,184 +309,121 @@ func (m *Manager) buildServerArgs(cfg *model.EasytierServer) []string {
 			extraParts := strings.Fields(cfg.ExtraArgs)
 			args = append(args, extraParts...)
 		}
-		return args
+		return args, nil
 	}
 
-	// ===== 以下为 standalone 独立模式参数 =====
-
-	if cfg.Hostname != "" {
-		args = append(args, "--hostname", cfg.Hostname)
+	// ===== standalone 独立模式：生成 config.toml =====
+	doc, err := buildServerConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
-	if cfg.InstanceName != "" {
-		args = append(args, "--instance-name", cfg.InstanceName)
-	}
-
-	listenAddr := cfg.ListenAddr
-	if listenAddr == "" {
-		listenAddr = "0.0.0.0"
-	}
-
-	// 监听端口（支持多个）
-	if cfg.ListenPorts != "" {
-		for _, p := range strings.Split(cfg.ListenPorts, ",") {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			if strings.Contains(p, ":") {
-				parts := strings.SplitN(p, ":", 2)
-				args = append(args, "-l", fmt.Sprintf("%s://%s:%s", parts[0], listenAddr, parts[1]))
-			} else {
-				args = append(args, "-l", p)
-			}
-		}
-	}
-
-	// ===== RPC 设置 =====
-	if cfg.RpcPortal != "" {
-		args = append(args, "--rpc-portal", cfg.RpcPortal)
-	}
-	if cfg.RpcPortalWhitelist != "" {
-		args = append(args, "--rpc-portal-whitelist", cfg.RpcPortalWhitelist)
-	}
-
-	// ===== 网络行为选项 =====
-	if cfg.NoTun {
-		args = append(args, "--no-tun")
-	}
-	if cfg.DisableP2P {
-		args = append(args, "--disable-p2p")
-	}
-	if cfg.EnableExitNode {
-		args = append(args, "--enable-exit-node")
-	}
-	if cfg.RelayAllPeerRpc {
-		args = append(args, "--relay-all-peer-rpc")
-	}
-	if cfg.DefaultProtocol != "" {
-		args = append(args, "--default-protocol", cfg.DefaultProtocol)
-	}
-	if cfg.ProxyForwardBySystem {
-		args = append(args, "--proxy-forward-by-system")
-	}
-
-	// ===== 协议加速选项 =====
-	if cfg.EnableKcpProxy {
-		args = append(args, "--enable-kcp-proxy")
-	}
-	if cfg.DisableKcpInput {
-		args = append(args, "--disable-kcp-input")
-	}
-	if cfg.EnableQuicProxy {
-		args = append(args, "--enable-quic-proxy")
-	}
-	if cfg.DisableQuicInput {
-		args = append(args, "--disable-quic-input")
-	}
-	if cfg.QuicListenPort > 0 {
-		args = append(args, "--quic-listen-port", fmt.Sprintf("%d", cfg.QuicListenPort))
+	path, err := m.writeConfigFile(fmt.Sprintf("server_%d.toml", cfg.ID), doc)
+	if err != nil {
+		return nil, err
 	}
+	args = append(args, "-c", path)
+	// 额外参数（兜底，命令行参数会覆盖配置文件中的同名项）
+	return append(args, strings.Fields(cfg.ExtraArgs)...), nil
+}
 
-	// ===== 安全选项 =====
-	if cfg.DisableEncryption {
-		args = append(args, "--disable-encryption")
-	}
-	if cfg.EncryptionAlgorithm != "" {
-		args = append(args, "--encryption-algorithm", cfg.EncryptionAlgorithm)
-	}
-	if cfg.PrivateMode {
-		args = append(args, "--private-mode")
-	}
+// ===== 进程守护 =====
 
-	// ===== 中继选项 =====
-	// 服务端必须传入 --relay-network-whitelist，否则 easytier-core 会以普通节点模式启动并立即退出。
-	// 默认值 "*" 表示中继所有网络；用户可自定义为 "net1,net2" 等。
-	relayWhitelist := cfg.RelayNetworkWhitelist
-	if relayWhitelist == "" {
-		relayWhitelist = "*"
-	}
-	args = append(args, "--relay-network-whitelist", relayWhitelist)
-	if cfg.ForeignRelayBpsLimit > 0 {
-		args = append(args, "--foreign-relay-bps-limit", fmt.Sprintf("%d", cfg.ForeignRelayBpsLimit))
-	}
-	if cfg.DisableRelayKcp {
-		args = append(args, "--disable-relay-kcp")
-	}
-	if cfg.EnableRelayForeignNetworkKcp {
-		args = append(args, "--enable-relay-foreign-network-kcp")
-	}
+// processLoader 读取最新配置，返回命令行参数、是否启用、是否已开启 no_tun
+type processLoader func() (args []string, enable, noTun bool, err error)
 
-	// ===== 流量控制 =====
-	if cfg.TcpWhitelist != "" {
-		args = append(args, "--tcp-whitelist", cfg.TcpWhitelist)
-	}
-	if cfg.UdpWhitelist != "" {
-		args = append(args, "--udp-whitelist", cfg.UdpWhitelist)
-	}
-	if cfg.Compression != "" && cfg.Compression != "none" {
-		args = append(args, "--compression", cfg.Compression)
+// startProcess 通过 supervisor 启动并守护 easytier-core 进程
+// 每次（重新）启动都会重新读取配置，崩溃后按指数退避自动重启；rpcAddr 非空时轮询 RPC 门户状态
+func (m *Manager) startProcess(store *sync.Map, id uint, label string, table interface{}, rpcAddr string, load processLoader) error {
+	name := fmt.Sprintf("[%s][%d]", label, id)
+	setStatus := func(fields map[string]interface{}) {
+		m.db.Model(table).Where("id = ?", id).Updates(fields)
 	}
 
-	// ===== STUN 服务器 =====
-	if cfg.StunServers != "" {
-		for _, s := range strings.Split(cfg.StunServers, ",") {
-			s = strings.TrimSpace(s)
-			if s != "" {
-				args = append(args, "--stun-servers", s)
+	proc, err := supervisor.Start(supervisor.Config{
+		Name: name,
+		Log:  m.log,
+		Command: func() (*exec.Cmd, error) {
+			args, _, _, err := load()
+			if err != nil {
+				return nil, fmt.Errorf("读取配置失败: %w", err)
 			}
-		}
-	}
-	if cfg.StunServersV6 != "" {
-		for _, s := range strings.Split(cfg.StunServersV6, ",") {
-			s = strings.TrimSpace(s)
-			if s != "" {
-				args = append(args, "--stun-servers-v6", s)
+			cmd := exec.Command(m.getBinaryPath(), args...)
+			// 设置工作目录为二进制文件所在目录，确保能找到 wintun.dll 等依赖文件
+			cmd.Dir = filepath.Dir(m.getBinaryPath())
+			return cmd, nil
+		},
+		OnExit: func(err error, output string) bool {
+			if err == nil {
+				return false
 			}
-		}
-	}
-
-	// ===== 手动路由 =====
-	if cfg.EnableManualRoutes && cfg.ManualRoutes != "" {
-		for _, route := range strings.Split(cfg.ManualRoutes, ",") {
-			route = strings.TrimSpace(route)
-			if route != "" {
-				args = append(args, "--manual-routes", route)
+			// 关闭期间不自动重启
+			m.mu.Lock()
+			isStopping := m.stopping
+			m.mu.Unlock()
+			if isStopping {
+				return false
 			}
-		}
+			_, enable, noTun, loadErr := load()
+			if loadErr != nil || !enable {
+				return false
+			}
+			// 检测 WinPcap/Npcap 崩溃（通过进程输出判断），自动开启 no_tun 选项
+			if isWinPcapPanic(output) && !noTun {
+				m.log.Warnf("%s 检测到 WinPcap/Npcap 崩溃，自动开启 --no-tun 模式", name)
+				m.db.Model(table).Where("id = ?", id).Update("no_tun", true)
+			}
+			return true
+		},
+		OnStateChange: func(st supervisor.Status) {
+			switch st.State {
+			case supervisor.StateRunning:
+				setStatus(map[string]interface{}{"status": "running", "last_error": ""})
+			case supervisor.StateBackoff, supervisor.StateFailed:
+				setStatus(map[string]interface{}{"status": "error", "last_error": "进程异常退出: " + st.LastExit})
+			case supervisor.StateExited:
+				setStatus(map[string]interface{}{"status": "stopped"})
+			}
+		},
+		// 保留输出到控制台
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if err != nil {
+		setStatus(map[string]interface{}{"status": "error", "last_error": err.Error()})
+		return fmt.Errorf("启动 %s 失败: %w", label, err)
 	}
+	entry := &processEntry{proc: proc}
+	if rpcAddr != "" {
+		entry.rpc = m.startRpcPoller(name, rpcAddr)
+	}
+	store.Store(id, entry)
+	m.log.Infof("%s 已启动，PID: %d", name, proc.PID())
+	return nil
+}
 
-	// ===== 端口转发 =====
-	if cfg.PortForwards != "" {
-		for _, pf := range strings.Split(cfg.PortForwards, "\n") {
-			pf = strings.TrimSpace(pf)
-			if pf != "" {
-				args = append(args, "--port-forward", pf)
-			}
+// stopProcess 停止进程并等待其完全退出，确保端口等资源已释放
+func (m *Manager) stopProcess(store *sync.Map, id uint) {
+	if val, ok := store.LoadAndDelete(id); ok {
+		entry := val.(*processEntry)
+		if entry.rpc != nil {
+			entry.rpc.stop()
 		}
+		entry.proc.Stop()
 	}
+}
 
-	// ===== 日志选项 =====
-	if cfg.ConsoleLogLevel != "" {
-		args = append(args, "--console-log-level", cfg.ConsoleLogLevel)
-	}
-	if cfg.FileLogLevel != "" {
-		args = append(args, "--file-log-level", cfg.FileLogLevel)
-	}
-	if cfg.FileLogDir != "" {
-		args = append(args, "--file-log-dir", cfg.FileLogDir)
-	}
-	if cfg.FileLogSize > 0 {
-		args = append(args, "--file-log-size", fmt.Sprintf("%d", cfg.FileLogSize))
-	}
-	if cfg.FileLogCount > 0 {
-		args = append(args, "--file-log-count", fmt.Sprintf("%d", cfg.FileLogCount))
+func processStatus(store *sync.Map, id uint) string {
+	if val, ok := store.Load(id); ok && val.(*processEntry).proc.Alive() {
+		return "running"
 	}
+	return "stopped"
+}
 
-	// 额外参数（兜底）
-	if cfg.ExtraArgs != "" {
-		extraParts := strings.Fields(cfg.ExtraArgs)
-		args = append(args, extraParts...)
+func processInfo(store *sync.Map, id uint) *supervisor.Status {
+	val, ok := store.Load(id)
+	if !ok {
+		return nil
 	}
-
-	return args
+	st := val.(*processEntry).proc.Status()
+	return &st
 }
@@ -0,0 +1,342 @@
+package easytier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rpcPollInterval = 10 * time.Second // RPC 门户轮询间隔
+	rpcQueryTimeout = 5 * time.Second  // 单次 easytier-cli 查询超时
+)
+
+// PeerInfo 对等节点信息（来自 easytier-cli peer list）
+type PeerInfo struct {
+	PeerID      string `json:"peer_id"`
+	IPv4        string `json:"ipv4"`
+	Hostname    string `json:"hostname"`
+	Cost        string `json:"cost"`      // Local / p2p / relay(n)
+	ConnType    string `json:"conn_type"` // local / p2p / relay
+	LatencyMs   string `json:"lat_ms"`
+	LossRate    string `json:"loss_rate"`
+	RxBytes     string `json:"rx_bytes"`
+	TxBytes     string `json:"tx_bytes"`
+	TunnelProto string `json:"tunnel_proto"`
+	NatType     string `json:"nat_type"`
+	Version     string `json:"version"`
+}
+
+// RouteInfo 路由信息（来自 easytier-cli route list）
+type RouteInfo struct {
+	IPv4            string `json:"ipv4"`
+	Hostname        string `json:"hostname"`
+	ProxyCidrs      string `json:"proxy_cidrs"`
+	NextHopIPv4     string `json:"next_hop_ipv4"`
+	NextHopHostname string `json:"next_hop_hostname"`
+	NextHopLatency  string `json:"next_hop_lat"`
+	PathLen         string `json:"path_len"`
+	PathLatency     string `json:"path_latency"`
+	ConnType        string `json:"conn_type"` // direct / relay
+	Version         string `json:"version"`
+}
+
+// PeersStatus 客户端对等节点状态
+type PeersStatus struct {
+	RpcPortal string     `json:"rpc_portal"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	LastError string     `json:"last_error"`
+	Peers     []PeerInfo `json:"peers"`
+}
+
+// RoutesStatus 客户端路由状态
+type RoutesStatus struct {
+	RpcPortal string      `json:"rpc_portal"`
+	UpdatedAt *time.Time  `json:"updated_at"`
+	LastError string      `json:"last_error"`
+	Routes    []RouteInfo `json:"routes"`
+}
+
+// getCliPath 获取 easytier-cli 二进制路径（与 easytier-core 同目录）
+func (m *Manager) getCliPath() string {
+	binName := "easytier-cli"
+	if runtime.GOOS == "windows" {
+		binName = "easytier-cli.exe"
+	}
+	return filepath.Join(m.dataDir, "bin", binName)
+}
+
+// portalKey 自动分配的 RPC 门户所属实例
+type portalKey struct {
+	kind string // client/server
+	id   uint
+}
+
+// rpcPortalFor 返回实例使用的 RPC 门户地址
+// 未配置（或配置为 0 随机端口）时自动分配一个本机回环端口，同一实例重启后保持不变
+func (m *Manager) rpcPortalFor(kind string, id uint, configured string) (string, error) {
+	if configured != "" && configured != "0" {
+		return configured, nil
+	}
+	key := portalKey{kind: kind, id: id}
+	if val, ok := m.portals.Load(key); ok {
+		return val.(string), nil
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("分配 RPC 门户端口失败: %w", err)
+	}
+	portal := ln.Addr().String()
+	ln.Close()
+	m.portals.Store(key, portal)
+	return portal, nil
+}
+
+// rpcQueryAddr 将 --rpc-portal 参数转换为本机可访问的查询地址
+func rpcQueryAddr(portal string) string {
+	if _, err := strconv.Atoi(portal); err == nil {
+		return net.JoinHostPort("127.0.0.1", portal)
+	}
+	host, port, err := net.SplitHostPort(portal)
+	if err != nil {
+		return portal
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// rpcPoller 定期通过 easytier-cli 查询 RPC 门户并缓存结果
+type rpcPoller struct {
+	addr   string
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	updatedAt time.Time
+	lastErr   string
+	peers     []PeerInfo
+	routes    []RouteInfo
+}
+
+// startRpcPoller 启动 RPC 门户轮询
+func (m *Manager) startRpcPoller(name, addr string) *rpcPoller {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &rpcPoller{addr: addr, cancel: cancel}
+	go func() {
+		// 等待 easytier-core 启动 RPC 门户
+		timer := time.NewTimer(2 * time.Second)
+		defer timer.Stop()
+		failed := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			err := m.pollRpc(ctx, p)
+			if err != nil && !failed {
+				m.log.Warnf("%s 查询 RPC 门户 %s 失败: %v", name, addr, err)
+			}
+			failed = err != nil
+			timer.Reset(rpcPollInterval)
+		}
+	}()
+	return p
+}
+
+func (p *rpcPoller) stop() {
+	p.cancel()
+}
+
+// pollRpc 查询一次对等节点与路由
+func (m *Manager) pollRpc(ctx context.Context, p *rpcPoller) error {
+	peers, err := m.queryPeers(ctx, p.addr)
+	var routes []RouteInfo
+	if err == nil {
+		routes, err = m.queryRoutes(ctx, p.addr)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updatedAt = time.Now()
+	if err != nil {
+		p.lastErr = err.Error()
+		return err
+	}
+	p.lastErr = ""
+	p.peers = peers
+	p.routes = routes
+	return nil
+}
+
+// runCli 执行 easytier-cli 并返回输出（表格类命令需传入 -o json）
+func (m *Manager) runCli(ctx context.Context, addr string, args ...string) ([]byte, error) {
+	cliPath := m.getCliPath()
+	if _, err := os.Stat(cliPath); err != nil {
+		return nil, fmt.Errorf("easytier-cli 二进制不存在: %s", cliPath)
+	}
+	ctx, cancel := context.WithTimeout(ctx, rpcQueryTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, cliPath, append([]string{"-p", addr}, args...)...)
+	cmd.Dir = filepath.Dir(cliPath)
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("%s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// cliRows 解析 easytier-cli 的 JSON 表格输出，字段值统一转为字符串
+func cliRows(out []byte) ([]map[string]string, error) {
+	var raw []map[string]any
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, fmt.Errorf("解析 easytier-cli 输出失败: %w", err)
+	}
+	rows := make([]map[string]string, 0, len(raw))
+	for _, r := range raw {
+		row := make(map[string]string, len(r))
+		for k, v := range r {
+			switch val := v.(type) {
+			case nil:
+				row[k] = ""
+			case string:
+				row[k] = val
+			default:
+				b, _ := json.Marshal(val)
+				row[k] = string(b)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (m *Manager) queryPeers(ctx context.Context, addr string) ([]PeerInfo, error) {
+	out, err := m.runCli(ctx, addr, "-o", "json", "peer", "list")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cliRows(out)
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]PeerInfo, 0, len(rows))
+	for _, r := range rows {
+		ipv4 := r["cidr"]
+		if ipv4 == "" {
+			ipv4 = r["ipv4"]
+		}
+		cost := r["cost"]
+		connType := "p2p"
+		switch lower := strings.ToLower(cost); {
+		case lower == "local":
+			connType = "local"
+		case strings.HasPrefix(lower, "relay"):
+			connType = "relay"
+		}
+		peers = append(peers, PeerInfo{
+			PeerID:      r["id"],
+			IPv4:        ipv4,
+			Hostname:    r["hostname"],
+			Cost:        cost,
+			ConnType:    connType,
+			LatencyMs:   r["lat_ms"],
+			LossRate:    r["loss_rate"],
+			RxBytes:     r["rx_bytes"],
+			TxBytes:     r["tx_bytes"],
+			TunnelProto: r["tunnel_proto"],
+			NatType:     r["nat_type"],
+			Version:     r["version"],
+		})
+	}
+	return peers, nil
+}
+
+func (m *Manager) queryRoutes(ctx context.Context, addr string) ([]RouteInfo, error) {
+	out, err := m.runCli(ctx, addr, "-o", "json", "route", "list")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cliRows(out)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]RouteInfo, 0, len(rows))
+	for _, r := range rows {
+		connType := "relay"
+		if strings.EqualFold(r["next_hop_ipv4"], "DIRECT") || r["path_len"] == "1" {
+			connType = "direct"
+		}
+		routes = append(routes, RouteInfo{
+			IPv4:            r["ipv4"],
+			Hostname:        r["hostname"],
+			ProxyCidrs:      r["proxy_cidrs"],
+			NextHopIPv4:     r["next_hop_ipv4"],
+			NextHopHostname: r["next_hop_hostname"],
+			NextHopLatency:  r["next_hop_lat"],
+			PathLen:         r["path_len"],
+			PathLatency:     r["path_latency"],
+			ConnType:        connType,
+			Version:         r["version"],
+		})
+	}
+	return routes, nil
+}
+
+// clientPoller 获取运行中客户端的 RPC 轮询器
+func (m *Manager) clientPoller(id uint) (*rpcPoller, error) {
+	val, ok := m.clients.Load(id)
+	if !ok || !val.(*processEntry).proc.Alive() {
+		return nil, fmt.Errorf("EasyTier 客户端未运行")
+	}
+	p := val.(*processEntry).rpc
+	if p == nil {
+		return nil, fmt.Errorf("EasyTier 客户端未启用 RPC 门户")
+	}
+	return p, nil
+}
+
+// GetClientPeers 获取客户端对等节点（含连接方式 p2p/relay、延迟、流量、NAT 类型）
+func (m *Manager) GetClientPeers(id uint) (*PeersStatus, error) {
+	p, err := m.clientPoller(id)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := &PeersStatus{RpcPortal: p.addr, LastError: p.lastErr, Peers: append([]PeerInfo{}, p.peers...)}
+	if !p.updatedAt.IsZero() {
+		updatedAt := p.updatedAt
+		st.UpdatedAt = &updatedAt
+	}
+	return st, nil
+}
+
+// GetClientRoutes 获取客户端路由表
+func (m *Manager) GetClientRoutes(id uint) (*RoutesStatus, error) {
+	p, err := m.clientPoller(id)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := &RoutesStatus{RpcPortal: p.addr, LastError: p.lastErr, Routes: append([]RouteInfo{}, p.routes...)}
+	if !p.updatedAt.IsZero() {
+		updatedAt := p.updatedAt
+		st.UpdatedAt = &updatedAt
+	}
+	return st, nil
+}
@@ -0,0 +1,270 @@
+package easytier
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// defaultNetworkName EasyTier 未指定网络名时使用的网络
+const defaultNetworkName = "default"
+
+// TopologyInstance 参与拓扑汇总的本地实例
+type TopologyInstance struct {
+	Kind      string     `json:"kind"` // client / server
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	NodeID    string     `json:"node_id"` // 该实例在拓扑中对应的节点
+	UpdatedAt *time.Time `json:"updated_at"`
+	LastError string     `json:"last_error"`
+}
+
+// TopologyNode 拓扑节点
+type TopologyNode struct {
+	ID       string `json:"id"`
+	PeerID   string `json:"peer_id"`
+	Hostname string `json:"hostname"`
+	IPv4     string `json:"ipv4"`
+	NatType  string `json:"nat_type"`
+	Version  string `json:"version"`
+	Local    bool   `json:"local"` // 是否为本面板管理的实例
+}
+
+// TopologyEdge 拓扑连线
+type TopologyEdge struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Type        string `json:"type"`   // p2p / relay
+	LatencyMs   string `json:"lat_ms"` // p2p 为直连延迟，relay 为整条路径延迟
+	LossRate    string `json:"loss_rate"`
+	TunnelProto string `json:"tunnel_proto"`
+	Via         string `json:"via"` // relay 时的下一跳节点
+}
+
+// Topology 单个虚拟网络的拓扑
+type Topology struct {
+	NetworkName string             `json:"network_name"`
+	Instances   []TopologyInstance `json:"instances"`
+	Nodes       []TopologyNode     `json:"nodes"`
+	Edges       []TopologyEdge     `json:"edges"`
+}
+
+// topologySource 一个本地实例的 RPC 查询快照
+type topologySource struct {
+	inst    TopologyInstance
+	network string
+	peers   []PeerInfo
+	routes  []RouteInfo
+}
+
+// GetTopology 汇总本地所有运行中实例的对等节点与路由，按网络名生成拓扑图
+// network 非空时只返回该网络
+func (m *Manager) GetTopology(network string) []Topology {
+	var sources []topologySource
+	collect := func(store *sync.Map, kind string, lookup func(id uint) (name, network string, ok bool)) {
+		store.Range(func(k, v any) bool {
+			entry := v.(*processEntry)
+			if entry.rpc == nil || !entry.proc.Alive() {
+				return true
+			}
+			id := k.(uint)
+			name, netName, ok := lookup(id)
+			if !ok {
+				return true
+			}
+			if netName == "" {
+				netName = defaultNetworkName
+			}
+			if network != "" && netName != network {
+				return true
+			}
+			p := entry.rpc
+			p.mu.Lock()
+			src := topologySource{
+				inst:    TopologyInstance{Kind: kind, ID: id, Name: name, LastError: p.lastErr},
+				network: netName,
+				peers:   append([]PeerInfo{}, p.peers...),
+				routes:  append([]RouteInfo{}, p.routes...),
+			}
+			if !p.updatedAt.IsZero() {
+				updatedAt := p.updatedAt
+				src.inst.UpdatedAt = &updatedAt
+			}
+			p.mu.Unlock()
+			sources = append(sources, src)
+			return true
+		})
+	}
+	collect(&m.clients, "client", func(id uint) (string, string, bool) {
+		var cfg model.EasytierClient
+		if err := m.db.First(&cfg, id).Error; err != nil {
+			return "", "", false
+		}
+		return cfg.Name, cfg.NetworkName, true
+	})
+	collect(&m.servers, "server", func(id uint) (string, string, bool) {
+		var cfg model.EasytierServer
+		if err := m.db.First(&cfg, id).Error; err != nil {
+			return "", "", false
+		}
+		// 服务端配置不写入 network_identity，始终加入 default 网络，其他网络仅经其中转
+		return cfg.Name, defaultNetworkName, true
+	})
+
+	groups := make(map[string][]topologySource)
+	for _, src := range sources {
+		groups[src.network] = append(groups[src.network], src)
+	}
+	result := make([]Topology, 0, len(groups))
+	for name, srcs := range groups {
+		result = append(result, buildTopology(name, srcs))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].NetworkName < result[j].NetworkName })
+	return result
+}
+
+// topologyBuilder 合并多个实例视角下的节点与连线
+type topologyBuilder struct {
+	nodes   map[string]*TopologyNode
+	order   []string
+	byIPv4  map[string]string // 虚拟 IP -> 节点 ID
+	edges   map[[2]string]*TopologyEdge
+	edgeIDs [][2]string
+}
+
+// nodeKey 节点唯一标识：优先 peer id，其次虚拟 IP、主机名
+func nodeKey(peerID, ipv4, hostname string) string {
+	switch {
+	case peerID != "":
+		return "peer:" + peerID
+	case ipv4 != "":
+		return "ip:" + stripCidr(ipv4)
+	default:
+		return "host:" + hostname
+	}
+}
+
+func stripCidr(ipv4 string) string {
+	ip, _, _ := strings.Cut(ipv4, "/")
+	return ip
+}
+
+// addNode 添加或合并节点，已有字段为空时用新数据补全
+func (b *topologyBuilder) addNode(n TopologyNode) string {
+	if n.IPv4 != "" {
+		if id, ok := b.byIPv4[stripCidr(n.IPv4)]; ok {
+			n.ID = id
+		}
+	}
+	cur, ok := b.nodes[n.ID]
+	if !ok {
+		cp := n
+		b.nodes[n.ID] = &cp
+		b.order = append(b.order, n.ID)
+		cur = &cp
+	} else {
+		fill := func(dst *string, src string) {
+			if *dst == "" {
+				*dst = src
+			}
+		}
+		fill(&cur.PeerID, n.PeerID)
+		fill(&cur.Hostname, n.Hostname)
+		fill(&cur.IPv4, n.IPv4)
+		fill(&cur.NatType, n.NatType)
+		fill(&cur.Version, n.Version)
+		cur.Local = cur.Local || n.Local
+	}
+	if cur.IPv4 != "" {
+		b.byIPv4[stripCidr(cur.IPv4)] = cur.ID
+	}
+	return cur.ID
+}
+
+// addEdge 添加连线；同一对节点只保留一条，p2p 优先于 relay
+func (b *topologyBuilder) addEdge(e TopologyEdge) {
+	if e.From == e.To {
+		return
+	}
+	key := [2]string{e.From, e.To}
+	if key[0] > key[1] {
+		key[0], key[1] = key[1], key[0]
+	}
+	cur, ok := b.edges[key]
+	if !ok {
+		cp := e
+		b.edges[key] = &cp
+		b.edgeIDs = append(b.edgeIDs, key)
+		return
+	}
+	if cur.Type == "relay" && e.Type == "p2p" {
+		*cur = e
+	}
+}
+
+func buildTopology(network string, srcs []topologySource) Topology {
+	b := &topologyBuilder{
+		nodes:  make(map[string]*TopologyNode),
+		byIPv4: make(map[string]string),
+		edges:  make(map[[2]string]*TopologyEdge),
+	}
+	topo := Topology{NetworkName: network}
+	for _, src := range srcs {
+		// 先确定本实例自身节点（cost 为 Local 的一行）
+		self := ""
+		for _, p := range src.peers {
+			if p.ConnType == "local" {
+				self = b.addNode(TopologyNode{
+					ID: nodeKey(p.PeerID, p.IPv4, p.Hostname), PeerID: p.PeerID, Hostname: p.Hostname,
+					IPv4: stripCidr(p.IPv4), NatType: p.NatType, Version: p.Version, Local: true,
+				})
+				break
+			}
+		}
+		inst := src.inst
+		inst.NodeID = self
+		topo.Instances = append(topo.Instances, inst)
+		if self == "" {
+			continue
+		}
+
+		// relay 连线的下一跳与路径延迟来自路由表
+		routeByIP := make(map[string]RouteInfo, len(src.routes))
+		for _, r := range src.routes {
+			routeByIP[stripCidr(r.IPv4)] = r
+		}
+		for _, p := range src.peers {
+			if p.ConnType == "local" {
+				continue
+			}
+			id := b.addNode(TopologyNode{
+				ID: nodeKey(p.PeerID, p.IPv4, p.Hostname), PeerID: p.PeerID, Hostname: p.Hostname,
+				IPv4: stripCidr(p.IPv4), NatType: p.NatType, Version: p.Version,
+			})
+			edge := TopologyEdge{From: self, To: id, Type: p.ConnType, LatencyMs: p.LatencyMs, LossRate: p.LossRate, TunnelProto: p.TunnelProto}
+			if p.ConnType == "relay" {
+				if r, ok := routeByIP[stripCidr(p.IPv4)]; ok {
+					edge.LatencyMs = r.PathLatency
+					edge.Via = r.NextHopHostname
+					if edge.Via == "" {
+						edge.Via = r.NextHopIPv4
+					}
+				}
+			}
+			b.addEdge(edge)
+		}
+	}
+
+	topo.Nodes = make([]TopologyNode, 0, len(b.order))
+	for _, id := range b.order {
+		topo.Nodes = append(topo.Nodes, *b.nodes[id])
+	}
+	topo.Edges = make([]TopologyEdge, 0, len(b.edgeIDs))
+	for _, key := range b.edgeIDs {
+		topo.Edges = append(topo.Edges, *b.edges[key])
+	}
+	return topo
+}
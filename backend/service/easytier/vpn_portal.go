@@ -0,0 +1,230 @@
+package easytier
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/netpanel/netpanel/model"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// ===== WireGuard VPN 门户设备 =====
+//
+// easytier-core 的 VPN 门户只接受由网络名与网络密钥派生出的唯一客户端密钥，
+// 不支持按设备注册公钥，因此无法为设备生成独立密钥对，也无法单独吊销某台设备：
+// 所有设备共用门户签发的客户端密钥（easytier-cli vpn-portal 输出），面板只为每台设备分配独立的客户端地址。
+// 删除设备仅释放地址，已分发的配置仍可连接；如需让已分发的配置失效，只能修改网络密钥（所有设备需重新导入配置）。
+
+// vpnPortalInfo easytier-cli vpn-portal 输出的客户端配置
+type vpnPortalInfo struct {
+	PrivateKey string // 客户端私钥
+	PublicKey  string // 门户（服务端）公钥
+	AllowedIPs string // 虚拟网段及代理子网
+}
+
+// queryVpnPortal 查询运行中客户端的 VPN 门户配置
+func (m *Manager) queryVpnPortal(clientID uint) (*vpnPortalInfo, error) {
+	p, err := m.clientPoller(clientID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.runCli(context.Background(), p.addr, "vpn-portal")
+	if err != nil {
+		return nil, fmt.Errorf("查询 VPN 门户失败: %w", err)
+	}
+	return parseVpnPortal(out)
+}
+
+// parseVpnPortal 解析 client_config_start 与 client_config_end 之间的 WireGuard 配置
+func parseVpnPortal(out []byte) (*vpnPortalInfo, error) {
+	info := &vpnPortalInfo{}
+	inConfig := false
+	section := ""
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.Contains(line, "client_config_start"):
+			inConfig = true
+			continue
+		case strings.Contains(line, "client_config_end"):
+			inConfig = false
+			continue
+		}
+		if !inConfig {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		// 去掉行尾注释
+		val, _, _ = strings.Cut(val, "#")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		switch {
+		case section == "interface" && key == "PrivateKey":
+			info.PrivateKey = val
+		case section == "peer" && key == "PublicKey":
+			info.PublicKey = val
+		case section == "peer" && key == "AllowedIPs":
+			info.AllowedIPs = val
+		}
+	}
+	if info.PrivateKey == "" || info.PublicKey == "" {
+		return nil, fmt.Errorf("VPN 门户未启用或输出格式无法识别")
+	}
+	return info, nil
+}
+
+// vpnPortalClient 读取启用了 VPN 门户的客户端配置
+func (m *Manager) vpnPortalClient(clientID uint) (*model.EasytierClient, error) {
+	var cfg model.EasytierClient
+	if err := m.db.First(&cfg, clientID).Error; err != nil {
+		return nil, fmt.Errorf("EasyTier 客户端不存在")
+	}
+	if !cfg.EnableVpnPortal || cfg.VpnPortalListenPort <= 0 || cfg.VpnPortalClientNetwork == "" {
+		return nil, fmt.Errorf("该客户端未启用 VPN 门户")
+	}
+	return &cfg, nil
+}
+
+// allocVpnAddress 在 VPN 客户端网段中分配未被占用的地址（跳过网络地址与广播地址）
+func (m *Manager) allocVpnAddress(cfg *model.EasytierClient) (string, error) {
+	prefix, err := netip.ParsePrefix(cfg.VpnPortalClientNetwork)
+	if err != nil || !prefix.Addr().Is4() {
+		return "", fmt.Errorf("VPN 客户端网段格式无效: %s", cfg.VpnPortalClientNetwork)
+	}
+	prefix = prefix.Masked()
+
+	var devices []model.EasytierVpnDevice
+	m.db.Where("client_id = ?", cfg.ID).Find(&devices)
+	used := make(map[netip.Addr]bool, len(devices))
+	for _, d := range devices {
+		if p, err := netip.ParsePrefix(d.Address); err == nil {
+			used[p.Addr()] = true
+		}
+	}
+
+	for addr := prefix.Addr().Next(); prefix.Contains(addr); addr = addr.Next() {
+		// 下一个地址已不在网段内，说明当前为广播地址
+		if !prefix.Contains(addr.Next()) {
+			break
+		}
+		if !used[addr] {
+			return addr.String() + "/32", nil
+		}
+	}
+	return "", fmt.Errorf("VPN 客户端网段 %s 地址已用尽", cfg.VpnPortalClientNetwork)
+}
+
+// ListVpnDevices 列出客户端的 VPN 设备
+func (m *Manager) ListVpnDevices(clientID uint) []model.EasytierVpnDevice {
+	var list []model.EasytierVpnDevice
+	m.db.Where("client_id = ?", clientID).Order("id desc").Find(&list)
+	return list
+}
+
+// CreateVpnDevice 新建 VPN 设备：在客户端网段中分配地址
+func (m *Manager) CreateVpnDevice(clientID uint, name, remark string) (*model.EasytierVpnDevice, error) {
+	if name == "" {
+		return nil, fmt.Errorf("设备名称不能为空")
+	}
+	cfg, err := m.vpnPortalClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+	// (client_id, address) 唯一索引保证并发新建不会分到同一地址，冲突时重新分配
+	for attempt := 0; ; attempt++ {
+		addr, err := m.allocVpnAddress(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dev := &model.EasytierVpnDevice{
+			ClientID: clientID,
+			Name:     name,
+			Address:  addr,
+			Remark:   remark,
+		}
+		err = m.db.Create(dev).Error
+		if err == nil {
+			return dev, nil
+		}
+		var count int64
+		m.db.Model(&model.EasytierVpnDevice{}).Where("client_id = ? AND address = ?", clientID, addr).Count(&count)
+		if count == 0 || attempt >= 2 {
+			return nil, err
+		}
+	}
+}
+
+func (m *Manager) getVpnDevice(clientID, deviceID uint) (*model.EasytierVpnDevice, error) {
+	var dev model.EasytierVpnDevice
+	if err := m.db.Where("client_id = ?", clientID).First(&dev, deviceID).Error; err != nil {
+		return nil, fmt.Errorf("设备不存在")
+	}
+	return &dev, nil
+}
+
+// DeleteVpnDevice 删除设备并释放其地址（门户密钥共用，已分发的配置不会因此失效）
+func (m *Manager) DeleteVpnDevice(clientID, deviceID uint) error {
+	dev, err := m.getVpnDevice(clientID, deviceID)
+	if err != nil {
+		return err
+	}
+	return m.db.Delete(dev).Error
+}
+
+// VpnDeviceConfig 生成设备的 WireGuard 配置文件
+// endpoint 为设备访问本机的地址（域名或公网 IP，可带端口），未带端口时使用 VPN 门户监听端口
+func (m *Manager) VpnDeviceConfig(clientID, deviceID uint, endpoint string) ([]byte, error) {
+	dev, err := m.getVpnDevice(clientID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := m.vpnPortalClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+	portal, err := m.queryVpnPortal(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint == "" {
+		return nil, fmt.Errorf("请指定设备访问本机的地址")
+	}
+	if _, _, err := net.SplitHostPort(endpoint); err != nil {
+		endpoint = net.JoinHostPort(strings.Trim(endpoint, "[]"), strconv.Itoa(cfg.VpnPortalListenPort))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s (%s)\n", dev.Name, cfg.Name)
+	b.WriteString("[Interface]\n")
+	fmt.Fprintf(&b, "PrivateKey = %s\n", portal.PrivateKey)
+	fmt.Fprintf(&b, "Address = %s\n", dev.Address)
+	b.WriteString("\n[Peer]\n")
+	fmt.Fprintf(&b, "PublicKey = %s\n", portal.PublicKey)
+	fmt.Fprintf(&b, "AllowedIPs = %s\n", portal.AllowedIPs)
+	fmt.Fprintf(&b, "Endpoint = %s\n", endpoint)
+	b.WriteString("PersistentKeepalive = 25\n")
+	return []byte(b.String()), nil
+}
+
+// VpnDeviceQRCode 生成设备配置的二维码 PNG，供 WireGuard 移动端扫码导入
+func (m *Manager) VpnDeviceQRCode(clientID, deviceID uint, endpoint string) ([]byte, error) {
+	conf, err := m.VpnDeviceConfig(clientID, deviceID, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(string(conf), qrcode.Medium, 512)
+}
@@ -0,0 +1,50 @@
+package easytier
+
+import "testing"
+
+func TestParseVpnPortal(t *testing.T) {
+	out := []byte(`portal_name: wireguard
+client_config_start
+############### client_config_start ###############
+
+[Interface]
+PrivateKey = cGxhY2Vob2xkZXItcHJpdmF0ZS1rZXktYmFzZTY0PQ==
+Address = 10.14.14.2/32 # should assign an ip from this cidr manually
+
+[Peer]
+PublicKey = cGxhY2Vob2xkZXItcHVibGljLWtleS1iYXNlNjQ9PQ==
+AllowedIPs = 10.144.144.0/24,10.14.14.0/24
+Endpoint = 0.0.0.0:11013 # should be the public ip(or domain) of the vpn server
+PersistentKeepalive = 25
+
+############### client_config_end ###############
+connected_clients:
+[]
+`)
+	info, err := parseVpnPortal(out)
+	if err != nil {
+		t.Fatalf("parseVpnPortal: %v", err)
+	}
+	if info.PrivateKey != "cGxhY2Vob2xkZXItcHJpdmF0ZS1rZXktYmFzZTY0PQ==" {
+		t.Errorf("PrivateKey = %q", info.PrivateKey)
+	}
+	if info.PublicKey != "cGxhY2Vob2xkZXItcHVibGljLWtleS1iYXNlNjQ9PQ==" {
+		t.Errorf("PublicKey = %q", info.PublicKey)
+	}
+	if info.AllowedIPs != "10.144.144.0/24,10.14.14.0/24" {
+		t.Errorf("AllowedIPs = %q", info.AllowedIPs)
+	}
+}
+
+func TestParseVpnPortalDisabled(t *testing.T) {
+	for _, out := range []string{
+		"",
+		"vpn portal not enabled\n",
+		// 配置块外的键值不应被采纳
+		"[Interface]\nPrivateKey = abc=\n[Peer]\nPublicKey = def=\n",
+	} {
+		if _, err := parseVpnPortal([]byte(out)); err == nil {
+			t.Errorf("parseVpnPortal(%q) 应返回错误", out)
+		}
+	}
+}
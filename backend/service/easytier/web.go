@@ -0,0 +1,678 @@
+package easytier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/pkg/supervisor"
+)
+
+// ===== EasyTier Web 配置服务器（config-server） =====
+//
+// easytier-core 的 --config-server 使用 EasyTier 自有隧道与 RPC 协议，由官方 easytier-web 实现，
+// 因此面板托管 easytier-web 子进程（随 easytier-core 发行包一起安装），并通过其管理 API：
+//   - 定期同步接入的机器（按 MachineID 识别）到数据库，新机器默认为待审批
+//   - 审批通过的机器按面板中保存的配置下发网络实例，撤销后删除其全部网络实例
+// 网络实例 ID 由面板生成并随配置下发，据此判断机器上运行的实例是否由面板管理。
+
+const webSyncInterval = 15 * time.Second // 机器同步间隔
+
+// 机器审批状态
+const (
+	MachinePending  = "pending"
+	MachineApproved = "approved"
+	MachineRevoked  = "revoked"
+)
+
+type webEntry struct {
+	proc   *supervisor.Process
+	api    *webAPI
+	cancel context.CancelFunc
+	kick   chan struct{} // 触发立即同步
+}
+
+// getWebBinaryPath 获取 easytier-web 二进制路径
+func (m *Manager) getWebBinaryPath() string {
+	return filepath.Join(m.dataDir, "bin", binaryFileName("easytier-web"))
+}
+
+// StartWebServer 启动 config-server，easytier-core 发行包缺失时自动安装
+func (m *Manager) StartWebServer(id uint) error {
+	if err := m.ensureBinary(); err != nil {
+		return err
+	}
+	m.binary.switching.RLock()
+	defer m.binary.switching.RUnlock()
+	return m.startWebServer(id)
+}
+
+func (m *Manager) startWebServer(id uint) error {
+	m.StopWebServer(id)
+
+	if _, err := os.Stat(m.getWebBinaryPath()); err != nil {
+		return fmt.Errorf("easytier-web 二进制不存在，当前发行包可能未包含: %s", m.getWebBinaryPath())
+	}
+	var cfg model.EasytierWebServer
+	if err := m.db.First(&cfg, id).Error; err != nil {
+		return fmt.Errorf("EasyTier 配置服务器不存在: %w", err)
+	}
+	if err := ValidateWebServer(&cfg); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("[EasyTier配置服务器][%d]", id)
+	bindLoopback := m.webAPIBindSupported()
+	if !bindLoopback {
+		m.log.Warnf("%s 当前 easytier-web 不支持 %s，管理 API 将监听所有地址，请通过防火墙限制端口 %d", name, webAPIBindFlag, cfg.ApiPort)
+	}
+	setStatus := func(fields map[string]interface{}) {
+		m.db.Model(&model.EasytierWebServer{}).Where("id = ?", id).Updates(fields)
+	}
+	dbPath := filepath.Join(m.dataDir, "easytier", fmt.Sprintf("web_%d.db", id))
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	proc, err := supervisor.Start(supervisor.Config{
+		Name: name,
+		Log:  m.log,
+		Command: func() (*exec.Cmd, error) {
+			var cur model.EasytierWebServer
+			if err := m.db.First(&cur, id).Error; err != nil {
+				return nil, fmt.Errorf("读取配置失败: %w", err)
+			}
+			cmd := exec.Command(m.getWebBinaryPath(), webServerArgs(&cur, dbPath, bindLoopback)...)
+			cmd.Dir = filepath.Dir(m.getWebBinaryPath())
+			return cmd, nil
+		},
+		OnExit: func(err error, output string) bool {
+			if err == nil {
+				return false
+			}
+			m.mu.Lock()
+			isStopping := m.stopping
+			m.mu.Unlock()
+			if isStopping {
+				return false
+			}
+			var cur model.EasytierWebServer
+			return m.db.First(&cur, id).Error == nil && cur.Enable
+		},
+		OnStateChange: func(st supervisor.Status) {
+			switch st.State {
+			case supervisor.StateRunning:
+				setStatus(map[string]interface{}{"status": "running", "last_error": ""})
+			case supervisor.StateBackoff, supervisor.StateFailed:
+				setStatus(map[string]interface{}{"status": "error", "last_error": "进程异常退出: " + st.LastExit})
+			case supervisor.StateExited:
+				setStatus(map[string]interface{}{"status": "stopped"})
+			}
+		},
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if err != nil {
+		setStatus(map[string]interface{}{"status": "error", "last_error": err.Error()})
+		return fmt.Errorf("启动 EasyTier 配置服务器失败: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entry := &webEntry{proc: proc, api: newWebAPI(&cfg), cancel: cancel, kick: make(chan struct{}, 1)}
+	m.webs.Store(id, entry)
+	go m.runWebSync(ctx, id, entry)
+	m.log.Infof("%s 已启动，PID: %d，节点接入地址 %s://<面板地址>:%d/%s", name, proc.PID(), cfg.ConfigProtocol, cfg.ConfigPort, cfg.Username)
+	return nil
+}
+
+// ValidateWebServer 校验 config-server 配置
+// easytier-web 只能登录其数据库中已存在的账号：默认账号（admin/user）首次启动时由面板改为配置的密码，
+// 其他账号需预先在 easytier-web 中注册；默认密码不允许使用
+func ValidateWebServer(cfg *model.EasytierWebServer) error {
+	if cfg.Username == "" {
+		return fmt.Errorf("账号不能为空")
+	}
+	if cfg.ApiPort <= 0 || cfg.ApiPort > 65535 {
+		return fmt.Errorf("管理 API 端口无效: %d", cfg.ApiPort)
+	}
+	if cfg.Password == "" || cfg.Password == cfg.Username {
+		return fmt.Errorf("密码不能为空或与账号相同")
+	}
+	for _, def := range defaultWebAccounts {
+		if cfg.Password == def {
+			return fmt.Errorf("不能使用 easytier-web 默认密码，请设置新密码")
+		}
+	}
+	return nil
+}
+
+// defaultWebAccounts easytier-web 新建数据库时内置的账号及密码
+var defaultWebAccounts = map[string]string{"admin": "admin", "user": "user"}
+
+// webAPIBindFlag easytier-web 指定管理 API 监听地址的参数
+const webAPIBindFlag = "--api-server-addr"
+
+// webAPIBindSupported 检查当前 easytier-web 是否支持指定管理 API 监听地址
+func (m *Manager) webAPIBindSupported() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, m.getWebBinaryPath(), "--help").CombinedOutput()
+	return bytes.Contains(out, []byte(webAPIBindFlag))
+}
+
+// webServerArgs 构建 easytier-web 命令行参数，bindLoopback 时管理 API 只监听本机回环
+func webServerArgs(cfg *model.EasytierWebServer, dbPath string, bindLoopback bool) []string {
+	args := []string{"--db", dbPath}
+	if cfg.ConfigPort > 0 {
+		args = append(args, "--config-server-port", strconv.Itoa(cfg.ConfigPort))
+	}
+	if cfg.ConfigProtocol != "" {
+		args = append(args, "--config-server-protocol", cfg.ConfigProtocol)
+	}
+	if cfg.ApiPort > 0 {
+		args = append(args, "--api-server-port", strconv.Itoa(cfg.ApiPort))
+	}
+	if bindLoopback {
+		args = append(args, webAPIBindFlag, "127.0.0.1")
+	}
+	return args
+}
+
+func (m *Manager) StopWebServer(id uint) {
+	if val, ok := m.webs.LoadAndDelete(id); ok {
+		entry := val.(*webEntry)
+		entry.cancel()
+		entry.proc.Stop()
+	}
+	m.db.Model(&model.EasytierWebServer{}).Where("id = ?", id).Update("status", "stopped")
+	m.db.Model(&model.EasytierMachine{}).Where("web_server_id = ?", id).Update("online", false)
+}
+
+func (m *Manager) GetWebServerStatus(id uint) string {
+	if val, ok := m.webs.Load(id); ok && val.(*webEntry).proc.Alive() {
+		return "running"
+	}
+	return "stopped"
+}
+
+// GetWebServerProcess 获取 config-server 进程的守护状态，未运行时返回 nil
+func (m *Manager) GetWebServerProcess(id uint) *supervisor.Status {
+	val, ok := m.webs.Load(id)
+	if !ok {
+		return nil
+	}
+	st := val.(*webEntry).proc.Status()
+	return &st
+}
+
+// webServer 获取运行中的 config-server
+func (m *Manager) webServer(id uint) (*webEntry, error) {
+	val, ok := m.webs.Load(id)
+	if !ok || !val.(*webEntry).proc.Alive() {
+		return nil, fmt.Errorf("EasyTier 配置服务器未运行")
+	}
+	return val.(*webEntry), nil
+}
+
+// ===== easytier-web 管理 API =====
+
+// webAPI easytier-web 管理 API 客户端（会话 Cookie 鉴权）
+type webAPI struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+
+	mu       sync.Mutex
+	loggedIn bool
+}
+
+func newWebAPI(cfg *model.EasytierWebServer) *webAPI {
+	jar, _ := cookiejar.New(nil)
+	return &webAPI{
+		baseURL:  "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(cfg.ApiPort)),
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: 10 * time.Second, Jar: jar},
+	}
+}
+
+func (a *webAPI) send(method, path string, body, out any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, a.baseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("请求 easytier-web API 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return resp.StatusCode, fmt.Errorf("easytier-web API %s 返回 HTTP %d: %s", path, resp.StatusCode, msg)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("解析 easytier-web API %s 响应失败: %w", path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func (a *webAPI) login() error {
+	_, err := a.send(http.MethodPost, "/api/v1/auth/login", map[string]string{
+		"username": a.username,
+		"password": a.password,
+	}, nil)
+	if err == nil {
+		return nil
+	}
+	// 新建的 easytier-web 数据库中默认账号仍为默认密码：登录后改为配置的密码
+	def, ok := defaultWebAccounts[a.username]
+	if !ok {
+		return fmt.Errorf("登录 easytier-web 失败（账号需已在 easytier-web 中注册）: %w", err)
+	}
+	if _, derr := a.send(http.MethodPost, "/api/v1/auth/login", map[string]string{
+		"username": a.username,
+		"password": def,
+	}, nil); derr != nil {
+		return fmt.Errorf("登录 easytier-web 失败: %w", err)
+	}
+	if _, err := a.send(http.MethodPut, "/api/v1/auth/password", map[string]string{
+		"new_password": a.password,
+	}, nil); err != nil {
+		return fmt.Errorf("修改 easytier-web 默认密码失败: %w", err)
+	}
+	return nil
+}
+
+// do 调用管理 API，未登录或会话失效时自动登录
+func (a *webAPI) do(method, path string, body, out any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.loggedIn {
+		if err := a.login(); err != nil {
+			return err
+		}
+		a.loggedIn = true
+	}
+	code, err := a.send(method, path, body, out)
+	if code == http.StatusUnauthorized {
+		a.loggedIn = false
+		if err := a.login(); err != nil {
+			return err
+		}
+		a.loggedIn = true
+		_, err = a.send(method, path, body, out)
+	}
+	return err
+}
+
+// webMachine easytier-web 机器列表项（info 为节点心跳内容）
+type webMachine struct {
+	ClientURL string `json:"client_url"`
+	Info      *struct {
+		MachineID               json.RawMessage   `json:"machine_id"`
+		Hostname                string            `json:"hostname"`
+		EasytierVersion         string            `json:"easytier_version"`
+		RunningNetworkInstances []json.RawMessage `json:"running_network_instances"`
+	} `json:"info"`
+}
+
+func (a *webAPI) listMachines() ([]webMachine, error) {
+	var res struct {
+		Machines []webMachine `json:"machines"`
+	}
+	if err := a.do(http.MethodGet, "/api/v1/machines", nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Machines, nil
+}
+
+func (a *webAPI) runNetwork(machineID string, cfg map[string]any) error {
+	return a.do(http.MethodPost, "/api/v1/machines/"+url.PathEscape(machineID)+"/networks", map[string]any{"config": cfg}, nil)
+}
+
+func (a *webAPI) removeNetwork(machineID, instID string) error {
+	return a.do(http.MethodDelete, "/api/v1/machines/"+url.PathEscape(machineID)+"/networks/"+url.PathEscape(instID), nil, nil)
+}
+
+// parseWebUUID 解析 easytier-web 返回的 UUID：字符串，或 {part1..part4} 四段 32 位整数
+func parseWebUUID(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.ToLower(s)
+	}
+	var parts struct {
+		Part1 uint32 `json:"part1"`
+		Part2 uint32 `json:"part2"`
+		Part3 uint32 `json:"part3"`
+		Part4 uint32 `json:"part4"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var b [16]byte
+	for i, p := range []uint32{parts.Part1, parts.Part2, parts.Part3, parts.Part4} {
+		b[i*4], b[i*4+1], b[i*4+2], b[i*4+3] = byte(p>>24), byte(p>>16), byte(p>>8), byte(p)
+	}
+	return formatUUID(b)
+}
+
+func formatUUID(b [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// newInstanceID 生成随机（v4）网络实例 ID
+func newInstanceID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// machineNetworkConfig 构建下发给节点的 NetworkConfig
+func machineNetworkConfig(mc *model.EasytierMachine) (map[string]any, error) {
+	cfg := map[string]any{
+		"instance_id":       mc.InstanceID,
+		"network_name":      mc.NetworkName,
+		"network_secret":    mc.NetworkPassword,
+		"networking_method": 1, // Manual：使用 peer_urls 指定的对等节点
+		"peer_urls":         append([]string{}, splitList(mc.PeerURLs, ",\n")...),
+		"listener_urls":     append([]string{}, splitList(mc.ListenerURLs, ",\n")...),
+		"dhcp":              mc.VirtualIP == "",
+	}
+	if mc.Hostname != "" {
+		cfg["hostname"] = mc.Hostname
+	}
+	if mc.VirtualIP != "" {
+		ip, length, found := strings.Cut(mc.VirtualIP, "/")
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("虚拟 IP 格式无效: %s", mc.VirtualIP)
+		}
+		n := 24
+		if found {
+			var err error
+			if n, err = strconv.Atoi(length); err != nil || n < 1 || n > 32 {
+				return nil, fmt.Errorf("虚拟 IP 前缀长度无效: %s", mc.VirtualIP)
+			}
+		}
+		cfg["virtual_ipv4"] = ip
+		cfg["network_length"] = n
+	}
+	if strings.TrimSpace(mc.ExtraConfig) != "" {
+		var extra map[string]any
+		if err := json.Unmarshal([]byte(mc.ExtraConfig), &extra); err != nil {
+			return nil, fmt.Errorf("自定义配置不是有效的 JSON 对象: %w", err)
+		}
+		for k, v := range extra {
+			if k != "instance_id" {
+				cfg[k] = v
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// ===== 机器同步 =====
+
+func (m *Manager) runWebSync(ctx context.Context, id uint, entry *webEntry) {
+	// 等待 easytier-web 启动管理 API
+	timer := time.NewTimer(3 * time.Second)
+	defer timer.Stop()
+	failed := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-entry.kick:
+		}
+		err := m.syncMachines(id, entry.api)
+		if err != nil && !failed {
+			m.log.Warnf("[EasyTier配置服务器][%d] 同步机器失败: %v", id, err)
+		}
+		failed = err != nil
+		timer.Reset(webSyncInterval)
+	}
+}
+
+// kickWebSync 触发立即同步
+func (m *Manager) kickWebSync(id uint) {
+	if val, ok := m.webs.Load(id); ok {
+		select {
+		case val.(*webEntry).kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// syncMachines 同步接入的机器，并按审批状态下发或删除网络实例
+func (m *Manager) syncMachines(id uint, api *webAPI) error {
+	var srv model.EasytierWebServer
+	if err := m.db.First(&srv, id).Error; err != nil {
+		return err
+	}
+	machines, err := api.listMachines()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	seen := make([]uint, 0, len(machines))
+	for _, wm := range machines {
+		if wm.Info == nil {
+			continue
+		}
+		machineID := parseWebUUID(wm.Info.MachineID)
+		if machineID == "" {
+			continue
+		}
+		var mc model.EasytierMachine
+		if m.db.Where("web_server_id = ? AND machine_id = ?", id, machineID).First(&mc).Error != nil {
+			mc = model.EasytierMachine{WebServerID: id, MachineID: machineID, Approval: MachinePending}
+			if srv.AutoApprove {
+				mc.Approval = MachineApproved
+			}
+			m.log.Infof("[EasyTier配置服务器][%d] 新机器接入: %s (%s)，状态 %s", id, wm.Info.Hostname, machineID, mc.Approval)
+		}
+		mc.Hostname = wm.Info.Hostname
+		mc.Version = wm.Info.EasytierVersion
+		mc.ClientURL = wm.ClientURL
+		mc.Online = true
+		mc.LastSeen = &now
+		running := make([]string, 0, len(wm.Info.RunningNetworkInstances))
+		for _, raw := range wm.Info.RunningNetworkInstances {
+			if inst := parseWebUUID(raw); inst != "" {
+				running = append(running, inst)
+			}
+		}
+		mc.LastError = errString(m.reconcileMachine(api, &mc, running))
+		m.db.Save(&mc)
+		seen = append(seen, mc.ID)
+	}
+
+	q := m.db.Model(&model.EasytierMachine{}).Where("web_server_id = ?", id)
+	if len(seen) > 0 {
+		q = q.Where("id NOT IN ?", seen)
+	}
+	q.Update("online", false)
+	return nil
+}
+
+// reconcileMachine 使机器上运行的网络实例与审批状态、面板配置一致
+func (m *Manager) reconcileMachine(api *webAPI, mc *model.EasytierMachine, running []string) error {
+	ours := false
+	for _, inst := range running {
+		if mc.Approval == MachineApproved && strings.EqualFold(inst, mc.InstanceID) {
+			ours = true
+			continue
+		}
+		// 撤销的机器删除全部实例；审批通过的机器只保留面板下发的实例，尚未配置网络时不做删除
+		if (mc.Approval == MachineApproved && mc.NetworkName != "") || mc.Approval == MachineRevoked {
+			if err := api.removeNetwork(mc.MachineID, inst); err != nil {
+				return err
+			}
+			m.log.Infof("[EasyTier配置服务器] 已删除机器 %s 上的网络实例 %s", mc.MachineID, inst)
+		}
+	}
+	if mc.Approval != MachineApproved || ours || mc.NetworkName == "" {
+		return nil
+	}
+
+	if mc.InstanceID == "" {
+		mc.InstanceID = newInstanceID()
+	}
+	cfg, err := machineNetworkConfig(mc)
+	if err != nil {
+		return err
+	}
+	if err := api.runNetwork(mc.MachineID, cfg); err != nil {
+		return err
+	}
+	m.log.Infof("[EasyTier配置服务器] 已向机器 %s 下发网络 %s", mc.MachineID, mc.NetworkName)
+	return nil
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// ===== 机器管理 =====
+
+// ListMachines 列出接入 config-server 的机器
+func (m *Manager) ListMachines(webID uint) []model.EasytierMachine {
+	var list []model.EasytierMachine
+	m.db.Where("web_server_id = ?", webID).Order("id desc").Find(&list)
+	return list
+}
+
+func (m *Manager) getMachine(webID, machineRowID uint) (*model.EasytierMachine, error) {
+	var mc model.EasytierMachine
+	if err := m.db.Where("web_server_id = ?", webID).First(&mc, machineRowID).Error; err != nil {
+		return nil, fmt.Errorf("机器不存在")
+	}
+	return &mc, nil
+}
+
+// SetMachineApproval 审批通过或撤销机器，下次同步时下发/删除网络实例
+func (m *Manager) SetMachineApproval(webID, machineRowID uint, approval string) error {
+	if approval != MachineApproved && approval != MachineRevoked && approval != MachinePending {
+		return fmt.Errorf("无效的审批状态: %s", approval)
+	}
+	mc, err := m.getMachine(webID, machineRowID)
+	if err != nil {
+		return err
+	}
+	m.db.Model(mc).Update("approval", approval)
+	m.kickWebSync(webID)
+	return nil
+}
+
+// UpdateMachineConfig 更新机器的网络配置，已下发的实例会按新配置重新下发
+func (m *Manager) UpdateMachineConfig(webID, machineRowID uint, req *model.EasytierMachine) error {
+	mc, err := m.getMachine(webID, machineRowID)
+	if err != nil {
+		return err
+	}
+	mc.NetworkName = req.NetworkName
+	mc.NetworkPassword = req.NetworkPassword
+	mc.VirtualIP = req.VirtualIP
+	mc.PeerURLs = req.PeerURLs
+	mc.ListenerURLs = req.ListenerURLs
+	mc.ExtraConfig = req.ExtraConfig
+	mc.Remark = req.Remark
+	if _, err := machineNetworkConfig(mc); err != nil {
+		return err
+	}
+	// 更换实例 ID 后，同步时旧实例不再匹配而被删除，并按新配置下发
+	if mc.InstanceID != "" {
+		mc.InstanceID = newInstanceID()
+	}
+	if err := m.db.Save(mc).Error; err != nil {
+		return err
+	}
+	m.kickWebSync(webID)
+	return nil
+}
+
+// DeleteMachine 删除机器记录；在线时先删除其上的全部网络实例
+// 节点仍在接入时，下次同步会以待审批状态重新出现
+func (m *Manager) DeleteMachine(webID, machineRowID uint) error {
+	mc, err := m.getMachine(webID, machineRowID)
+	if err != nil {
+		return err
+	}
+	if entry, err := m.webServer(webID); err == nil && mc.Online {
+		mc.Approval = MachineRevoked
+		if machines, err := entry.api.listMachines(); err == nil {
+			for _, wm := range machines {
+				if wm.Info == nil || parseWebUUID(wm.Info.MachineID) != mc.MachineID {
+					continue
+				}
+				var running []string
+				for _, raw := range wm.Info.RunningNetworkInstances {
+					running = append(running, parseWebUUID(raw))
+				}
+				if err := m.reconcileMachine(entry.api, mc, running); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return m.db.Delete(mc).Error
+}
+
+// GetMachineNetworks 查询机器上网络实例的运行信息（easytier-web 原始数据）
+func (m *Manager) GetMachineNetworks(webID, machineRowID uint) (json.RawMessage, error) {
+	mc, err := m.getMachine(webID, machineRowID)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := m.webServer(webID)
+	if err != nil {
+		return nil, err
+	}
+	var info json.RawMessage
+	if err := entry.api.do(http.MethodGet, "/api/v1/machines/"+url.PathEscape(mc.MachineID)+"/networks/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
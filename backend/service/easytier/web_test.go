@@ -0,0 +1,48 @@
+package easytier
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+func TestParseWebUUID(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`"0F1E2D3C-4B5A-6978-8796-A5B4C3D2E1F0"`, "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"},
+		// 四段 32 位整数按大端拼接
+		{`{"part1":253635900,"part2":1264216440,"part3":2274796980,"part4":3285377520}`, "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"},
+		{`{"part1":0,"part2":0,"part3":0,"part4":1}`, "00000000-0000-0000-0000-000000000001"},
+		{`null`, ""},
+		{`123`, ""},
+		{`[1,2]`, ""},
+	}
+	for _, tt := range tests {
+		if got := parseWebUUID(json.RawMessage(tt.raw)); got != tt.want {
+			t.Errorf("parseWebUUID(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebServer(t *testing.T) {
+	tests := []struct {
+		cfg model.EasytierWebServer
+		ok  bool
+	}{
+		{model.EasytierWebServer{Username: "admin", Password: "s3cret-pass", ApiPort: 11211}, true},
+		{model.EasytierWebServer{Username: "admin", Password: "admin", ApiPort: 11211}, false},
+		{model.EasytierWebServer{Username: "ops", Password: "user", ApiPort: 11211}, false},
+		{model.EasytierWebServer{Username: "ops", Password: "ops", ApiPort: 11211}, false},
+		{model.EasytierWebServer{Username: "ops", Password: "", ApiPort: 11211}, false},
+		{model.EasytierWebServer{Username: "", Password: "s3cret-pass", ApiPort: 11211}, false},
+		{model.EasytierWebServer{Username: "ops", Password: "s3cret-pass", ApiPort: 0}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateWebServer(&tt.cfg); (err == nil) != tt.ok {
+			t.Errorf("ValidateWebServer(%s/%s:%d) err = %v, want ok=%v", tt.cfg.Username, tt.cfg.Password, tt.cfg.ApiPort, err, tt.ok)
+		}
+	}
+}
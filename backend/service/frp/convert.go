@@ -0,0 +1,557 @@
+package frp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatedier/frp/pkg/config"
+	"github.com/fatedier/frp/pkg/config/legacy"
+	v1 "github.com/fatedier/frp/pkg/config/v1"
+	"github.com/netpanel/netpanel/model"
+	"github.com/pelletier/go-toml/v2"
+	"gorm.io/gorm"
+	"sigs.k8s.io/yaml"
+)
+
+// ===== 导入 =====
+
+// ImportClientConfig 导入 frpc 配置文件（toml/yaml/json 或旧版 ini），生成 FrpcConfig 及代理、访问者记录
+func (m *Manager) ImportClientConfig(name string, content []byte) (*model.FrpcConfig, error) {
+	common, proxyCfgs, visitorCfgs, err := parseClientConfigFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := clientConfigToModel(common)
+	cfg.Name = name
+	if cfg.Name == "" {
+		cfg.Name = fmt.Sprintf("导入-%s:%d", cfg.ServerAddr, cfg.ServerPort)
+	}
+	for _, pc := range proxyCfgs {
+		p, err := proxyConfigToModel(pc)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Proxies = append(cfg.Proxies, *p)
+	}
+	for _, vc := range visitorCfgs {
+		v, err := visitorConfigToModel(vc)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Visitors = append(cfg.Visitors, *v)
+	}
+
+	// 带 default 标签的布尔字段为 false 时会被 gorm 替换为默认值，需在创建前记下并显式写回
+	boolFields := map[string]any{
+		"tls_enable":      cfg.TLSEnable,
+		"tcp_mux":         cfg.TCPMux,
+		"login_fail_exit": cfg.LoginFailExit,
+	}
+	var disabledProxies, disabledVisitors []int
+	for i, p := range cfg.Proxies {
+		if !p.Enable {
+			disabledProxies = append(disabledProxies, i)
+		}
+	}
+	for i, v := range cfg.Visitors {
+		if !v.Enable {
+			disabledVisitors = append(disabledVisitors, i)
+		}
+	}
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cfg).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.FrpcConfig{}).Where("id = ?", cfg.ID).Updates(boolFields).Error; err != nil {
+			return err
+		}
+		for _, i := range disabledProxies {
+			if err := tx.Model(&cfg.Proxies[i]).Update("enable", false).Error; err != nil {
+				return err
+			}
+		}
+		for _, i := range disabledVisitors {
+			if err := tx.Model(&cfg.Visitors[i]).Update("enable", false).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存导入的 FRP 客户端配置失败: %w", err)
+	}
+	m.db.Preload("Proxies").Preload("Visitors").First(cfg, cfg.ID)
+
+	m.log.Infof("[FRP客户][%s] 已导入配置，代理数: %d，访问者数: %d", cfg.Name, len(cfg.Proxies), len(cfg.Visitors))
+	return cfg, nil
+}
+
+// ImportServerConfig 导入 frps 配置文件（toml/yaml/json 或旧版 ini），生成 FrpsConfig 记录
+func (m *Manager) ImportServerConfig(name string, content []byte) (*model.FrpsConfig, error) {
+	svrCfg, err := parseServerConfigFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := serverConfigToModel(svrCfg)
+	cfg.Name = name
+	if cfg.Name == "" {
+		cfg.Name = fmt.Sprintf("导入-%d", cfg.BindPort)
+	}
+
+	// 同 ImportClientConfig，默认为 true 的布尔字段需显式写回
+	detailedErrors := cfg.DetailedErrorsToClient
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cfg).Error; err != nil {
+			return err
+		}
+		return tx.Model(cfg).Update("detailed_errors_to_client", detailedErrors).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("保存导入的 FRP 服务端配置失败: %w", err)
+	}
+	m.db.First(cfg, cfg.ID)
+
+	m.log.Infof("[FRP服务][%s] 已导入配置", cfg.Name)
+	return cfg, nil
+}
+
+// parseClientConfigFile 解析 frpc 配置文件内容，旧版 ini 格式会先转换为 v1 结构
+func parseClientConfigFile(content []byte) (*v1.ClientCommonConfig, []v1.ProxyConfigurer, []v1.VisitorConfigurer, error) {
+	var (
+		common      *v1.ClientCommonConfig
+		proxyCfgs   []v1.ProxyConfigurer
+		visitorCfgs []v1.VisitorConfigurer
+	)
+
+	if config.DetectLegacyINIFormat(content) {
+		rendered, err := legacy.RenderContent(content)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("渲染 ini 配置模板失败: %w", err)
+		}
+		legacyCommon, err := legacy.UnmarshalClientConfFromIni(rendered)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("解析 ini [common] 段失败: %w", err)
+		}
+		// 前缀传空，保留配置文件中的原始代理名
+		legacyProxies, legacyVisitors, err := legacy.LoadAllProxyConfsFromIni("", rendered, legacyCommon.Start)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("解析 ini 代理配置失败: %w", err)
+		}
+		common = legacy.Convert_ClientCommonConf_To_v1(&legacyCommon)
+		for _, c := range legacyProxies {
+			proxyCfgs = append(proxyCfgs, legacy.Convert_ProxyConf_To_v1(c))
+		}
+		for _, c := range legacyVisitors {
+			visitorCfgs = append(visitorCfgs, legacy.Convert_VisitorConf_To_v1(c))
+		}
+		// ini 解析结果为 map，按名称排序保证导入顺序稳定
+		sort.Slice(proxyCfgs, func(i, j int) bool {
+			return proxyCfgs[i].GetBaseConfig().Name < proxyCfgs[j].GetBaseConfig().Name
+		})
+		sort.Slice(visitorCfgs, func(i, j int) bool {
+			return visitorCfgs[i].GetBaseConfig().Name < visitorCfgs[j].GetBaseConfig().Name
+		})
+	} else {
+		rendered, err := config.RenderWithTemplate(content, config.GetValues())
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("渲染配置模板失败: %w", err)
+		}
+		allCfg := v1.ClientConfig{}
+		if err := config.LoadConfigure(rendered, &allCfg, false); err != nil {
+			return nil, nil, nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+		common = &allCfg.ClientCommonConfig
+		for _, c := range allCfg.Proxies {
+			proxyCfgs = append(proxyCfgs, c.ProxyConfigurer)
+		}
+		for _, c := range allCfg.Visitors {
+			visitorCfgs = append(visitorCfgs, c.VisitorConfigurer)
+		}
+	}
+
+	if len(common.IncludeConfigFiles) > 0 {
+		return nil, nil, nil, fmt.Errorf("暂不支持 includes 引用的外部配置文件，请合并后再导入")
+	}
+	if err := common.Complete(); err != nil {
+		return nil, nil, nil, fmt.Errorf("补全客户端默认配置失败: %w", err)
+	}
+	return common, proxyCfgs, visitorCfgs, nil
+}
+
+// parseServerConfigFile 解析 frps 配置文件内容，旧版 ini 格式会先转换为 v1 结构
+func parseServerConfigFile(content []byte) (*v1.ServerConfig, error) {
+	var svrCfg *v1.ServerConfig
+	if config.DetectLegacyINIFormat(content) {
+		rendered, err := legacy.RenderContent(content)
+		if err != nil {
+			return nil, fmt.Errorf("渲染 ini 配置模板失败: %w", err)
+		}
+		legacyCfg, err := legacy.UnmarshalServerConfFromIni(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("解析 ini [common] 段失败: %w", err)
+		}
+		svrCfg = legacy.Convert_ServerCommonConf_To_v1(&legacyCfg)
+	} else {
+		rendered, err := config.RenderWithTemplate(content, config.GetValues())
+		if err != nil {
+			return nil, fmt.Errorf("渲染配置模板失败: %w", err)
+		}
+		svrCfg = &v1.ServerConfig{}
+		if err := config.LoadConfigure(rendered, svrCfg, false); err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+	}
+	if err := svrCfg.Complete(); err != nil {
+		return nil, fmt.Errorf("补全服务端默认配置失败: %w", err)
+	}
+	return svrCfg, nil
+}
+
+// clientConfigToModel 将 frp v1 客户端公共配置转换为数据库模型（buildRawClientConfig 的逆过程）
+func clientConfigToModel(c *v1.ClientCommonConfig) *model.FrpcConfig {
+	cfg := &model.FrpcConfig{
+		User:                    c.User,
+		ServerAddr:              c.ServerAddr,
+		ServerPort:              c.ServerPort,
+		AuthMethod:              string(c.Auth.Method),
+		Token:                   c.Auth.Token,
+		TransportProtocol:       c.Transport.Protocol,
+		TLSEnable:               c.Transport.TLS.Enable == nil || *c.Transport.TLS.Enable,
+		PoolCount:               c.Transport.PoolCount,
+		TCPMux:                  c.Transport.TCPMux == nil || *c.Transport.TCPMux,
+		TCPMuxKeepaliveInterval: int(c.Transport.TCPMuxKeepaliveInterval),
+		DialServerTimeout:       int(c.Transport.DialServerTimeout),
+		DialServerKeepalive:     int(c.Transport.DialServerKeepAlive),
+		HeartbeatInterval:       int(c.Transport.HeartbeatInterval),
+		HeartbeatTimeout:        int(c.Transport.HeartbeatTimeout),
+		ConnectServerLocalIP:    c.Transport.ConnectServerLocalIP,
+		ProxyURL:                c.Transport.ProxyURL,
+		NatHoleStunServer:       c.NatHoleSTUNServer,
+		DNSServer:               c.DNSServer,
+		LoginFailExit:           c.LoginFailExit == nil || *c.LoginFailExit,
+		UDPPacketSize:           int(c.UDPPacketSize),
+		WebServerPort:           c.WebServer.Port,
+		WebServerUser:           c.WebServer.User,
+		WebServerPassword:       c.WebServer.Password,
+		LogLevel:                c.Log.Level,
+	}
+	return cfg
+}
+
+// proxyConfigToModel 将 frp v1 代理配置转换为数据库模型（buildProxyConfig 的逆过程）
+func proxyConfigToModel(pc v1.ProxyConfigurer) (*model.FrpcProxy, error) {
+	base := pc.GetBaseConfig()
+	p := &model.FrpcProxy{
+		Name:                 base.Name,
+		Type:                 base.Type,
+		LocalIP:              base.LocalIP,
+		LocalPort:            base.LocalPort,
+		UseEncryption:        base.Transport.UseEncryption,
+		UseCompression:       base.Transport.UseCompression,
+		Enable:               base.Enabled == nil || *base.Enabled,
+		BandwidthLimit:       base.Transport.BandwidthLimit.String(),
+		BandwidthLimitMode:   base.Transport.BandwidthLimitMode,
+		HealthCheckType:      base.HealthCheck.Type,
+		HealthCheckPath:      base.HealthCheck.Path,
+		HealthCheckTimeoutS:  base.HealthCheck.TimeoutSeconds,
+		HealthCheckIntervalS: base.HealthCheck.IntervalSeconds,
+		HealthCheckMaxFailed: base.HealthCheck.MaxFailed,
+		LoadBalancerGroup:    base.LoadBalancer.Group,
+		LoadBalancerGroupKey: base.LoadBalancer.GroupKey,
+	}
+
+	if base.Plugin.ClientPluginOptions != nil {
+		if !supportedPlugins[base.Plugin.Type] {
+			return nil, fmt.Errorf("代理 [%s] 使用了不支持的插件类型: %s", base.Name, base.Plugin.Type)
+		}
+		b, err := json.Marshal(base.Plugin.ClientPluginOptions)
+		if err != nil {
+			return nil, fmt.Errorf("代理 [%s] 插件配置序列化失败: %w", base.Name, err)
+		}
+		p.PluginType = base.Plugin.Type
+		p.PluginConfig = string(b)
+	}
+
+	switch c := pc.(type) {
+	case *v1.TCPProxyConfig:
+		p.RemotePort = c.RemotePort
+	case *v1.UDPProxyConfig:
+		p.RemotePort = c.RemotePort
+	case *v1.HTTPProxyConfig:
+		p.CustomDomains = strings.Join(c.CustomDomains, ",")
+		p.Subdomain = c.SubDomain
+		p.Locations = strings.Join(c.Locations, ",")
+		p.HostHeaderRewrite = c.HostHeaderRewrite
+		p.HTTPUser = c.HTTPUser
+		p.HTTPPassword = c.HTTPPassword
+		p.RequestHeaders = formatHeaderLines(c.RequestHeaders.Set)
+	case *v1.HTTPSProxyConfig:
+		p.CustomDomains = strings.Join(c.CustomDomains, ",")
+		p.Subdomain = c.SubDomain
+	case *v1.TCPMuxProxyConfig:
+		p.CustomDomains = strings.Join(c.CustomDomains, ",")
+		p.Subdomain = c.SubDomain
+		p.Multiplexer = c.Multiplexer
+		p.HTTPUser = c.HTTPUser
+		p.HTTPPassword = c.HTTPPassword
+	case *v1.STCPProxyConfig:
+		p.SecretKey = c.Secretkey
+		p.AllowUsers = strings.Join(c.AllowUsers, ",")
+	case *v1.XTCPProxyConfig:
+		p.SecretKey = c.Secretkey
+		p.AllowUsers = strings.Join(c.AllowUsers, ",")
+	case *v1.SUDPProxyConfig:
+		p.SecretKey = c.Secretkey
+		p.AllowUsers = strings.Join(c.AllowUsers, ",")
+	default:
+		return nil, fmt.Errorf("代理 [%s] 类型不支持导入: %s", base.Name, base.Type)
+	}
+	return p, nil
+}
+
+// visitorConfigToModel 将 frp v1 访问者配置转换为数据库模型（buildVisitorConfig 的逆过程）
+func visitorConfigToModel(vc v1.VisitorConfigurer) (*model.FrpcVisitor, error) {
+	base := vc.GetBaseConfig()
+	v := &model.FrpcVisitor{
+		Name:           base.Name,
+		Type:           base.Type,
+		ServerUser:     base.ServerUser,
+		ServerName:     base.ServerName,
+		SecretKey:      base.SecretKey,
+		BindAddr:       base.BindAddr,
+		BindPort:       base.BindPort,
+		UseEncryption:  base.Transport.UseEncryption,
+		UseCompression: base.Transport.UseCompression,
+		Enable:         base.Enabled == nil || *base.Enabled,
+	}
+
+	switch c := vc.(type) {
+	case *v1.STCPVisitorConfig, *v1.SUDPVisitorConfig:
+	case *v1.XTCPVisitorConfig:
+		v.Protocol = c.Protocol
+		v.KeepTunnelOpen = c.KeepTunnelOpen
+		v.MaxRetriesAnHour = c.MaxRetriesAnHour
+		v.MinRetryInterval = c.MinRetryInterval
+		v.FallbackTo = c.FallbackTo
+		v.FallbackTimeoutMs = c.FallbackTimeoutMs
+	default:
+		return nil, fmt.Errorf("访问者 [%s] 类型不支持导入: %s", base.Name, base.Type)
+	}
+	return v, nil
+}
+
+// serverConfigToModel 将 frp v1 服务端配置转换为数据库模型（buildServerConfig 的逆过程）
+func serverConfigToModel(c *v1.ServerConfig) *model.FrpsConfig {
+	cfg := &model.FrpsConfig{
+		BindAddr:                        c.BindAddr,
+		BindPort:                        c.BindPort,
+		KCPBindPort:                     c.KCPBindPort,
+		QUICBindPort:                    c.QUICBindPort,
+		ProxyBindAddr:                   c.ProxyBindAddr,
+		VhostHTTPPort:                   c.VhostHTTPPort,
+		VhostHTTPTimeout:                int(c.VhostHTTPTimeout),
+		VhostHTTPSPort:                  c.VhostHTTPSPort,
+		TcpmuxHTTPConnectPort:           c.TCPMuxHTTPConnectPort,
+		TcpmuxPassthrough:               c.TCPMuxPassthrough,
+		SubDomainHost:                   c.SubDomainHost,
+		Custom404Page:                   c.Custom404Page,
+		Token:                           c.Auth.Token,
+		DashboardAddr:                   c.WebServer.Addr,
+		DashboardPort:                   c.WebServer.Port,
+		DashboardUser:                   c.WebServer.User,
+		DashboardPassword:               c.WebServer.Password,
+		EnablePrometheus:                c.EnablePrometheus,
+		MaxPortsPerClient:               int(c.MaxPortsPerClient),
+		UserConnTimeout:                 int(c.UserConnTimeout),
+		UDPPacketSize:                   int(c.UDPPacketSize),
+		NatholeAnalysisDataReserveHours: int(c.NatHoleAnalysisDataReserveHours),
+		DetailedErrorsToClient:          c.DetailedErrorsToClient == nil || *c.DetailedErrorsToClient,
+		LogLevel:                        c.Log.Level,
+		LogMaxDays:                      int(c.Log.MaxDays),
+		TransportMaxPoolCount:           int(c.Transport.MaxPoolCount),
+		TransportHeartbeatTimeout:       int(c.Transport.HeartbeatTimeout),
+		TransportTCPMuxKeepalive:        int(c.Transport.TCPMuxKeepaliveInterval),
+		TransportTCPKeepalive:           int(c.Transport.TCPKeepAlive),
+		TransportTLSForce:               c.Transport.TLS.Force,
+		TransportTLSCertFile:            c.Transport.TLS.CertFile,
+		TransportTLSKeyFile:             c.Transport.TLS.KeyFile,
+		TransportTLSTrustedCAFile:       c.Transport.TLS.TrustedCaFile,
+		SSHTunnelGatewayBindPort:        c.SSHTunnelGateway.BindPort,
+		SSHTunnelGatewayPrivateKeyFile:  c.SSHTunnelGateway.PrivateKeyFile,
+		SSHTunnelGatewayAutoGenKeyPath:  c.SSHTunnelGateway.AutoGenPrivateKeyPath,
+		SSHTunnelGatewayAuthorizedKeys:  c.SSHTunnelGateway.AuthorizedKeysFile,
+	}
+	// 日志输出到控制台时留空
+	if c.Log.To != "console" {
+		cfg.LogFile = c.Log.To
+	}
+	return cfg
+}
+
+// parseHeaderLines 解析 key=value 换行分隔的请求头
+func parseHeaderLines(s string) map[string]string {
+	headers := map[string]string{}
+	for _, line := range strings.Split(s, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+// formatHeaderLines 将请求头格式化为 key=value 换行分隔（parseHeaderLines 的逆过程）
+func formatHeaderLines(headers map[string]string) string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, k+"="+headers[k])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ===== 导出 =====
+
+// ExportClientConfig 将客户端配置导出为 frpc 原生配置文件，format 支持 toml/yaml/json
+func (m *Manager) ExportClientConfig(id uint, format string) ([]byte, error) {
+	var cfg model.FrpcConfig
+	if err := m.db.Preload("Proxies").Preload("Visitors").First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("FRP 客户端配置不存在: %w", err)
+	}
+
+	common, proxyCfgs, visitorCfgs, err := m.buildRawClientConfig(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("构建 FRP 客户端配置失败: %w", err)
+	}
+
+	// 面板保存的自定义请求头写入导出文件
+	headers := map[string]string{}
+	for _, p := range cfg.Proxies {
+		headers[p.Name] = p.RequestHeaders
+	}
+
+	allCfg := v1.ClientConfig{ClientCommonConfig: *common}
+	for _, pc := range proxyCfgs {
+		if hc, ok := pc.(*v1.HTTPProxyConfig); ok {
+			if set := parseHeaderLines(headers[hc.Name]); len(set) > 0 {
+				hc.RequestHeaders.Set = set
+			}
+		}
+		allCfg.Proxies = append(allCfg.Proxies, v1.TypedProxyConfig{Type: pc.GetBaseConfig().Type, ProxyConfigurer: pc})
+	}
+	for _, vc := range visitorCfgs {
+		allCfg.Visitors = append(allCfg.Visitors, v1.TypedVisitorConfig{Type: vc.GetBaseConfig().Type, VisitorConfigurer: vc})
+	}
+	return encodeConfigFile(allCfg, format)
+}
+
+// ExportServerConfig 将服务端配置导出为 frps 原生配置文件，format 支持 toml/yaml/json
+func (m *Manager) ExportServerConfig(id uint, format string) ([]byte, error) {
+	var cfg model.FrpsConfig
+	if err := m.db.First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("FRP 服务端配置不存在: %w", err)
+	}
+
+	svrCfg, err := buildServerConfig(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("构建 FRP 服务端配置失败: %w", err)
+	}
+	return encodeConfigFile(svrCfg, format)
+}
+
+// encodeConfigFile 按 frp v1 的 json 字段名序列化配置，并去掉空值使输出接近手写配置
+func encodeConfigFile(v any, format string) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	pruneEmpty(obj)
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		var out bytes.Buffer
+		if err := json.Indent(&out, b, "", "  "); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	case "yaml", "yml":
+		return yaml.JSONToYAML(b)
+	case "", "toml":
+		return toml.Marshal(normalizeJSONNumbers(obj))
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
+
+// pruneEmpty 递归删除 null、空字符串、空对象和空数组
+func pruneEmpty(obj map[string]any) {
+	for k, v := range obj {
+		switch val := v.(type) {
+		case nil:
+			delete(obj, k)
+		case string:
+			if val == "" {
+				delete(obj, k)
+			}
+		case map[string]any:
+			pruneEmpty(val)
+			if len(val) == 0 {
+				delete(obj, k)
+			}
+		case []any:
+			for _, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					pruneEmpty(m)
+				}
+			}
+			if len(val) == 0 {
+				delete(obj, k)
+			}
+		}
+	}
+}
+
+// normalizeJSONNumbers 将 json.Number 转换为整数或浮点数，避免 toml 中整数被写成 7000.0
+func normalizeJSONNumbers(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			val[k] = normalizeJSONNumbers(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = normalizeJSONNumbers(item)
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	default:
+		return v
+	}
+}
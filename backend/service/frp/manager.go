@@ -21,14 +21,33 @@ import (
 
 // clientEntry FRP 客户端运行实例
 type clientEntry struct {
-	svc    *client.Service
-	cancel context.CancelFunc
+	svc     *client.Service
+	cancel  context.CancelFunc
+	tracker *connTracker
+
+	mu        sync.Mutex // 保护 proxyCfgs，热更新时原地替换
+	proxyCfgs []v1.ProxyConfigurer
+}
+
+// setProxyCfgs 替换当前生效的代理配置
+func (e *clientEntry) setProxyCfgs(cfgs []v1.ProxyConfigurer) {
+	e.mu.Lock()
+	e.proxyCfgs = cfgs
+	e.mu.Unlock()
+}
+
+// getProxyCfgs 获取当前生效的代理配置
+func (e *clientEntry) getProxyCfgs() []v1.ProxyConfigurer {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.proxyCfgs
 }
 
 // serverEntry FRP 服务端运行实例
 type serverEntry struct {
-	svc    *server.Service
-	cancel context.CancelFunc
+	svc        *server.Service
+	cancel     context.CancelFunc
+	terminator *httpsTerminator
 }
 
 // Manager FRP 管理器（客户端+服务端）
@@ -85,7 +104,7 @@ func (m *Manager) StartClient(id uint) error {
 	m.StopClient(id)
 
 	var cfg model.FrpcConfig
-	if err := m.db.Preload("Proxies").First(&cfg, id).Error; err != nil {
+	if err := m.db.Preload("Proxies").Preload("Visitors").First(&cfg, id).Error; err != nil {
 		return fmt.Errorf("FRP 客户端配置不存在: %w", err)
 	}
 	if !cfg.Enable {
@@ -93,24 +112,26 @@ func (m *Manager) StartClient(id uint) error {
 	}
 
 	// 构建 frp 客户端配置
-	frpCfg, proxyCfgs, err := buildClientConfig(&cfg)
+	frpCfg, proxyCfgs, visitorCfgs, err := m.buildClientConfig(&cfg)
 	if err != nil {
 		m.setClientError(id, err.Error())
 		return fmt.Errorf("构建 FRP 客户端配置失败: %w", err)
 	}
 
 	// 验证配置
-	if _, err := validation.ValidateAllClientConfig(frpCfg, proxyCfgs, nil, nil); err != nil {
+	if _, err := validation.ValidateAllClientConfig(frpCfg, proxyCfgs, visitorCfgs, nil); err != nil {
 		m.setClientError(id, err.Error())
 		return fmt.Errorf("FRP 客户端配置验证失败: %w", err)
 	}
 
-	// 创建服务
+	// 创建服务（包装连接器以跟踪登录状态）
+	tracker := &connTracker{}
 	svc, err := client.NewService(client.ServiceOptions{
-		Common:         frpCfg,
-		ProxyCfgs:      proxyCfgs,
-		VisitorCfgs:    nil,
-		ConfigFilePath: "",
+		Common:           frpCfg,
+		ProxyCfgs:        proxyCfgs,
+		VisitorCfgs:      visitorCfgs,
+		ConfigFilePath:   "",
+		ConnectorCreator: tracker.creator,
 	})
 	if err != nil {
 		m.setClientError(id, err.Error())
@@ -118,7 +139,7 @@ func (m *Manager) StartClient(id uint) error {
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	entry := &clientEntry{svc: svc, cancel: cancel}
+	entry := &clientEntry{svc: svc, cancel: cancel, tracker: tracker, proxyCfgs: proxyCfgs}
 	m.clients.Store(id, entry)
 
 	// 更新状态
@@ -129,8 +150,8 @@ func (m *Manager) StartClient(id uint) error {
 
 	go m.runClient(ctx, id, cfg.Name, svc)
 
-	m.log.Infof("[FRP客户][%s] 已启动，连接 %s:%d，代理数: %d",
-		cfg.Name, cfg.ServerAddr, cfg.ServerPort, len(proxyCfgs))
+	m.log.Infof("[FRP客户][%s] 已启动，连接 %s:%d，代理数: %d，访问者数: %d",
+		cfg.Name, cfg.ServerAddr, cfg.ServerPort, len(proxyCfgs), len(visitorCfgs))
 	return nil
 }
 
@@ -151,9 +172,44 @@ func (m *Manager) RestartClient(id uint) error {
 	return m.StartClient(id)
 }
 
-// GetClientStatus 获取客户端状态
+// ReloadClient 热更新运行中客户端的代理与访问者配置，不断开与服务端的控制连接
+// 客户端未运行时不做处理，配置将在下次启动时生效
+func (m *Manager) ReloadClient(id uint) error {
+	val, ok := m.clients.Load(id)
+	if !ok {
+		return nil
+	}
+	entry := val.(*clientEntry)
+
+	var cfg model.FrpcConfig
+	if err := m.db.Preload("Proxies").Preload("Visitors").First(&cfg, id).Error; err != nil {
+		return fmt.Errorf("FRP 客户端配置不存在: %w", err)
+	}
+
+	frpCfg, proxyCfgs, visitorCfgs, err := m.buildClientConfig(&cfg)
+	if err != nil {
+		return fmt.Errorf("构建 FRP 客户端配置失败: %w", err)
+	}
+	if _, err := validation.ValidateAllClientConfig(frpCfg, proxyCfgs, visitorCfgs, nil); err != nil {
+		return fmt.Errorf("FRP 客户端配置验证失败: %w", err)
+	}
+
+	if err := entry.svc.UpdateAllConfigurer(proxyCfgs, visitorCfgs); err != nil {
+		return fmt.Errorf("热更新 FRP 客户端配置失败: %w", err)
+	}
+	// 原地更新，避免覆盖 StopClient/runClient 并发删除或重启后的新实例
+	entry.setProxyCfgs(proxyCfgs)
+
+	m.log.Infof("[FRP客户][%s] 配置已热更新，代理数: %d，访问者数: %d", cfg.Name, len(proxyCfgs), len(visitorCfgs))
+	return nil
+}
+
+// GetClientStatus 获取客户端状态，已启动但尚未登录服务端时返回 connecting
 func (m *Manager) GetClientStatus(id uint) string {
 	if _, ok := m.clients.Load(id); ok {
+		if m.GetClientRuntimeStatus(id).LoginStatus != "logged_in" {
+			return "connecting"
+		}
 		return "running"
 	}
 	return "stopped"
@@ -192,11 +248,27 @@ func (m *Manager) setClientError(id uint, errMsg string) {
 	})
 }
 
-// buildClientConfig 将数据库配置转换为 frp v1 配置
-func buildClientConfig(cfg *model.FrpcConfig) (*v1.ClientCommonConfig, []v1.ProxyConfigurer, error) {
+// buildClientConfig 将数据库配置转换为 frp v1 配置，并补全代理/访问者默认值
+func (m *Manager) buildClientConfig(cfg *model.FrpcConfig) (*v1.ClientCommonConfig, []v1.ProxyConfigurer, []v1.VisitorConfigurer, error) {
+	common, proxyCfgs, visitorCfgs, err := m.buildRawClientConfig(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// 补全默认值与 {user}. 名称前缀（与 frpc 加载配置文件时的行为一致）
+	for _, pc := range proxyCfgs {
+		pc.Complete(common.User)
+	}
+	for _, vc := range visitorCfgs {
+		vc.Complete(common)
+	}
+	return common, proxyCfgs, visitorCfgs, nil
+}
+
+// buildRawClientConfig 将数据库配置转换为 frp v1 配置，代理与访问者保持配置文件中的原始形式（未加用户名前缀）
+func (m *Manager) buildRawClientConfig(cfg *model.FrpcConfig) (*v1.ClientCommonConfig, []v1.ProxyConfigurer, []v1.VisitorConfigurer, error) {
 	common := &v1.ClientCommonConfig{}
 	if err := common.Complete(); err != nil {
-		return nil, nil, fmt.Errorf("初始化 FRP 客户端默认配置失败: %w", err)
+		return nil, nil, nil, fmt.Errorf("初始化 FRP 客户端默认配置失败: %w", err)
 	}
 
 	// ===== 基本连接 =====
@@ -318,18 +390,40 @@ func buildClientConfig(cfg *model.FrpcConfig) (*v1.ClientCommonConfig, []v1.Prox
 		}
 		pc, err := buildProxyConfig(&p)
 		if err != nil {
-			return nil, nil, fmt.Errorf("代理 [%s] 配置错误: %w", p.Name, err)
+			return nil, nil, nil, fmt.Errorf("代理 [%s] 配置错误: %w", p.Name, err)
+		}
+		// 插件：设置后由插件处理连接，LocalIP/LocalPort 将被忽略
+		if strings.TrimSpace(p.PluginType) != "" {
+			plugin, err := m.buildPluginOptions(&p)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("代理 [%s] 插件配置错误: %w", p.Name, err)
+			}
+			pc.GetBaseConfig().Plugin = plugin
 		}
 		proxyCfgs = append(proxyCfgs, pc)
 	}
 
-	return common, proxyCfgs, nil
+	// ===== 构建访问者配置 =====
+	var visitorCfgs []v1.VisitorConfigurer
+	for _, v := range cfg.Visitors {
+		if !v.Enable {
+			continue
+		}
+		vc, err := buildVisitorConfig(&v)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("访问者 [%s] 配置错误: %w", v.Name, err)
+		}
+		visitorCfgs = append(visitorCfgs, vc)
+	}
+
+	return common, proxyCfgs, visitorCfgs, nil
 }
 
 // buildProxyConfig 构建单个代理配置
 func buildProxyConfig(p *model.FrpcProxy) (v1.ProxyConfigurer, error) {
 	base := v1.ProxyBaseConfig{
 		Name: p.Name,
+		Type: strings.ToLower(p.Type),
 		Transport: v1.ProxyTransport{
 			UseEncryption:  p.UseEncryption,
 			UseCompression: p.UseCompression,
@@ -490,6 +584,59 @@ func buildProxyConfig(p *model.FrpcProxy) (v1.ProxyConfigurer, error) {
 	}
 }
 
+// ValidateVisitor 构建并校验单个访问者配置，供保存前检查
+func ValidateVisitor(v *model.FrpcVisitor) error {
+	vc, err := buildVisitorConfig(v)
+	if err != nil {
+		return err
+	}
+	// 补全默认值（如 xtcp 协议）后再校验，与客户端启动时一致
+	vc.Complete(&v1.ClientCommonConfig{})
+	if err := validation.ValidateVisitorConfigurer(vc); err != nil {
+		return fmt.Errorf("访问者 [%s] 配置错误: %w", v.Name, err)
+	}
+	return nil
+}
+
+// buildVisitorConfig 构建单个访问者配置
+func buildVisitorConfig(v *model.FrpcVisitor) (v1.VisitorConfigurer, error) {
+	base := v1.VisitorBaseConfig{
+		Name: v.Name,
+		Type: strings.ToLower(v.Type),
+		Transport: v1.VisitorTransport{
+			UseEncryption:  v.UseEncryption,
+			UseCompression: v.UseCompression,
+		},
+		SecretKey:  v.SecretKey,
+		ServerUser: v.ServerUser,
+		ServerName: v.ServerName,
+		BindAddr:   v.BindAddr,
+		BindPort:   v.BindPort,
+	}
+
+	switch base.Type {
+	case "stcp":
+		return &v1.STCPVisitorConfig{VisitorBaseConfig: base}, nil
+
+	case "sudp":
+		return &v1.SUDPVisitorConfig{VisitorBaseConfig: base}, nil
+
+	case "xtcp":
+		return &v1.XTCPVisitorConfig{
+			VisitorBaseConfig: base,
+			Protocol:          v.Protocol,
+			KeepTunnelOpen:    v.KeepTunnelOpen,
+			MaxRetriesAnHour:  v.MaxRetriesAnHour,
+			MinRetryInterval:  v.MinRetryInterval,
+			FallbackTo:        v.FallbackTo,
+			FallbackTimeoutMs: v.FallbackTimeoutMs,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的访问者类型: %s", v.Type)
+	}
+}
+
 // ===== 服务端 =====
 
 // StartServer 启动指定 FRP 服务端
@@ -511,8 +658,35 @@ func (m *Manager) StartServer(id uint) error {
 		return fmt.Errorf("构建 FRP 服务端配置失败: %w", err)
 	}
 
+	// 关联域名证书（传输层 TLS / HTTPS 终止）
+	terminateCert, err := m.applyServerCerts(&cfg, frpCfg)
+	if err != nil {
+		m.setServerError(id, err.Error())
+		return err
+	}
+
+	// 附属服务（鉴权插件、HTTPS 终止），随服务端一同关闭
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	// 多用户鉴权：启动本机插件服务并注册到 frps
+	if cfg.EnableUserAuth {
+		auth, pluginOpts, err := startUserAuthServer(m.db, m.log, id, cfg.Name)
+		if err != nil {
+			m.setServerError(id, err.Error())
+			return err
+		}
+		closers = append(closers, auth.Close)
+		frpCfg.HTTPPlugins = append(frpCfg.HTTPPlugins, pluginOpts)
+	}
+
 	// 验证配置
 	if _, err := validation.NewConfigValidator(nil).ValidateServerConfig(frpCfg); err != nil {
+		closeAll()
 		m.setServerError(id, err.Error())
 		return fmt.Errorf("FRP 服务端配置验证失败: %w", err)
 	}
@@ -520,12 +694,26 @@ func (m *Manager) StartServer(id uint) error {
 	// 创建服务
 	svc, err := server.NewService(frpCfg)
 	if err != nil {
+		closeAll()
 		m.setServerError(id, err.Error())
 		return fmt.Errorf("创建 FRP 服务端服务失败: %w", err)
 	}
 
+	// HTTPS 终止：由 NetPanel 监听 VhostHTTPSPort，按 SNI 终止或透传到 frps
+	var terminator *httpsTerminator
+	if terminateCert != nil {
+		terminator, err = startHTTPSTerminator(&cfg, terminateCert, frpCfg.VhostHTTPSPort)
+		if err != nil {
+			svc.Close()
+			closeAll()
+			m.setServerError(id, err.Error())
+			return err
+		}
+		closers = append(closers, terminator.Close)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	entry := &serverEntry{svc: svc, cancel: cancel}
+	entry := &serverEntry{svc: svc, cancel: cancel, terminator: terminator}
 	m.servers.Store(id, entry)
 
 	// 更新状态
@@ -534,7 +722,10 @@ func (m *Manager) StartServer(id uint) error {
 		"last_error": "",
 	})
 
-	go m.runServer(ctx, id, cfg.Name, svc)
+	go func() {
+		m.runServer(ctx, id, cfg.Name, svc)
+		closeAll()
+	}()
 
 	m.log.Infof("[FRP服务][%s] 已启动，监听 %s:%d", cfg.Name, cfg.BindAddr, cfg.BindPort)
 	return nil
@@ -550,6 +741,13 @@ func (m *Manager) StopServer(id uint) {
 	m.db.Model(&model.FrpsConfig{}).Where("id = ?", id).Update("status", "stopped")
 }
 
+// RestartServer 重启指定 FRP 服务端
+func (m *Manager) RestartServer(id uint) error {
+	m.StopServer(id)
+	time.Sleep(500 * time.Millisecond)
+	return m.StartServer(id)
+}
+
 // GetServerStatus 获取服务端状态
 func (m *Manager) GetServerStatus(id uint) string {
 	if _, ok := m.servers.Load(id); ok {
@@ -0,0 +1,137 @@
+package frp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "github.com/fatedier/frp/pkg/config/v1"
+	"github.com/netpanel/netpanel/model"
+)
+
+// supportedPlugins 支持的客户端插件类型
+var supportedPlugins = map[string]bool{
+	v1.PluginSocks5:           true,
+	v1.PluginHTTPProxy:        true,
+	v1.PluginStaticFile:       true,
+	v1.PluginUnixDomainSocket: true,
+	v1.PluginHTTP2HTTPS:       true,
+	v1.PluginHTTPS2HTTP:       true,
+	v1.PluginHTTPS2HTTPS:      true,
+	v1.PluginTLS2Raw:          true,
+}
+
+// pluginUsesCert 需要 TLS 证书的插件（可关联 NetPanel 域名证书）
+func pluginUsesCert(pluginType string) bool {
+	switch pluginType {
+	case v1.PluginHTTPS2HTTP, v1.PluginHTTPS2HTTPS, v1.PluginTLS2Raw:
+		return true
+	}
+	return false
+}
+
+// ParsePluginConfig 解析并校验插件 JSON 配置
+// PluginConfig 字段名与 frp 配置文件一致（驼峰），如 {"localAddr":"127.0.0.1:80","hostHeaderRewrite":"example.com"}
+func ParsePluginConfig(pluginType, pluginConfig string) (v1.ClientPluginOptions, error) {
+	pluginType = strings.ToLower(strings.TrimSpace(pluginType))
+	if !supportedPlugins[pluginType] {
+		return nil, fmt.Errorf("不支持的插件类型: %s", pluginType)
+	}
+
+	var opts v1.ClientPluginOptions
+	switch pluginType {
+	case v1.PluginSocks5:
+		opts = &v1.Socks5PluginOptions{}
+	case v1.PluginHTTPProxy:
+		opts = &v1.HTTPProxyPluginOptions{}
+	case v1.PluginStaticFile:
+		opts = &v1.StaticFilePluginOptions{}
+	case v1.PluginUnixDomainSocket:
+		opts = &v1.UnixDomainSocketPluginOptions{}
+	case v1.PluginHTTP2HTTPS:
+		opts = &v1.HTTP2HTTPSPluginOptions{}
+	case v1.PluginHTTPS2HTTP:
+		opts = &v1.HTTPS2HTTPPluginOptions{}
+	case v1.PluginHTTPS2HTTPS:
+		opts = &v1.HTTPS2HTTPSPluginOptions{}
+	case v1.PluginTLS2Raw:
+		opts = &v1.TLS2RawPluginOptions{}
+	}
+
+	// 写入 type 字段，frp 序列化插件配置时依赖该字段
+	fields := map[string]any{}
+	if raw := strings.TrimSpace(pluginConfig); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return nil, fmt.Errorf("插件 %s 配置不是合法的 JSON 对象: %w", pluginType, err)
+		}
+	}
+	fields["type"] = pluginType
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(opts); err != nil {
+		return nil, fmt.Errorf("插件 %s 配置解析失败: %w", pluginType, err)
+	}
+
+	// 必填项校验
+	switch o := opts.(type) {
+	case *v1.StaticFilePluginOptions:
+		if o.LocalPath == "" {
+			return nil, fmt.Errorf("static_file 插件需要填写 localPath")
+		}
+	case *v1.UnixDomainSocketPluginOptions:
+		if o.UnixPath == "" {
+			return nil, fmt.Errorf("unix_domain_socket 插件需要填写 unixPath")
+		}
+	case *v1.HTTP2HTTPSPluginOptions:
+		if o.LocalAddr == "" {
+			return nil, fmt.Errorf("http2https 插件需要填写 localAddr")
+		}
+	case *v1.HTTPS2HTTPPluginOptions:
+		if o.LocalAddr == "" {
+			return nil, fmt.Errorf("https2http 插件需要填写 localAddr")
+		}
+	case *v1.HTTPS2HTTPSPluginOptions:
+		if o.LocalAddr == "" {
+			return nil, fmt.Errorf("https2https 插件需要填写 localAddr")
+		}
+	case *v1.TLS2RawPluginOptions:
+		if o.LocalAddr == "" {
+			return nil, fmt.Errorf("tls2raw 插件需要填写 localAddr")
+		}
+	}
+	return opts, nil
+}
+
+// buildPluginOptions 构建代理的插件配置，关联域名证书时用证书文件覆盖 crtPath/keyPath
+func (m *Manager) buildPluginOptions(p *model.FrpcProxy) (v1.TypedClientPluginOptions, error) {
+	pluginType := strings.ToLower(strings.TrimSpace(p.PluginType))
+	opts, err := ParsePluginConfig(pluginType, p.PluginConfig)
+	if err != nil {
+		return v1.TypedClientPluginOptions{}, err
+	}
+
+	if p.PluginCertID > 0 {
+		if !pluginUsesCert(pluginType) {
+			return v1.TypedClientPluginOptions{}, fmt.Errorf("插件 %s 不支持关联证书", pluginType)
+		}
+		certFile, keyFile, err := m.loadDomainCert(p.PluginCertID, "插件关联证书")
+		if err != nil {
+			return v1.TypedClientPluginOptions{}, err
+		}
+		switch o := opts.(type) {
+		case *v1.HTTPS2HTTPPluginOptions:
+			o.CrtPath, o.KeyPath = certFile, keyFile
+		case *v1.HTTPS2HTTPSPluginOptions:
+			o.CrtPath, o.KeyPath = certFile, keyFile
+		case *v1.TLS2RawPluginOptions:
+			o.CrtPath, o.KeyPath = certFile, keyFile
+		}
+	}
+
+	return v1.TypedClientPluginOptions{Type: pluginType, ClientPluginOptions: opts}, nil
+}
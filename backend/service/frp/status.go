@@ -0,0 +1,208 @@
+package frp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatedier/frp/client"
+	v1 "github.com/fatedier/frp/pkg/config/v1"
+	frpsapi "github.com/fatedier/frp/server/api"
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== 客户端运行状态 =====
+
+// connTracker 包装 frp 连接器，记录与服务端的控制连接状态
+// frpc 每次登录都会新建一个 Connector：登录失败或控制连接断开时调用 Close
+type connTracker struct {
+	mu          sync.Mutex
+	connected   bool
+	connectedAt time.Time
+	lastErr     string
+}
+
+// creator 返回可传给 client.ServiceOptions.ConnectorCreator 的构造函数
+func (t *connTracker) creator(ctx context.Context, cfg *v1.ClientCommonConfig) client.Connector {
+	return &trackedConnector{Connector: client.NewConnector(ctx, cfg), tracker: t}
+}
+
+func (t *connTracker) snapshot() (connected bool, connectedAt time.Time, lastErr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected, t.connectedAt, t.lastErr
+}
+
+type trackedConnector struct {
+	client.Connector
+	tracker *connTracker
+	once    sync.Once
+}
+
+func (c *trackedConnector) Open() error {
+	err := c.Connector.Open()
+	if err != nil {
+		c.tracker.mu.Lock()
+		c.tracker.lastErr = err.Error()
+		c.tracker.mu.Unlock()
+	}
+	return err
+}
+
+func (c *trackedConnector) Connect() (net.Conn, error) {
+	conn, err := c.Connector.Connect()
+	c.tracker.mu.Lock()
+	defer c.tracker.mu.Unlock()
+	if err != nil {
+		c.tracker.lastErr = err.Error()
+		return nil, err
+	}
+	// 第一个连接为控制连接，后续为工作连接
+	c.once.Do(func() {
+		c.tracker.connected = true
+		c.tracker.connectedAt = time.Now()
+	})
+	return conn, nil
+}
+
+func (c *trackedConnector) Close() error {
+	c.tracker.mu.Lock()
+	c.tracker.connected = false
+	c.tracker.mu.Unlock()
+	return c.Connector.Close()
+}
+
+// ProxyRuntimeStatus 单个代理的运行状态（来自 frpc 内部）
+type ProxyRuntimeStatus struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Phase      string `json:"phase"` // new/wait start/start error/running/check failed/closed，未登录时为空
+	Err        string `json:"err"`
+	RemoteAddr string `json:"remote_addr"`
+}
+
+// ClientRuntimeStatus FRP 客户端运行状态
+type ClientRuntimeStatus struct {
+	Status      string               `json:"status"`       // running/stopped
+	LoginStatus string               `json:"login_status"` // logged_in/connecting/stopped
+	ConnectedAt *time.Time           `json:"connected_at"`
+	LastError   string               `json:"last_error"`
+	Proxies     []ProxyRuntimeStatus `json:"proxies"`
+}
+
+// GetClientRuntimeStatus 获取客户端登录状态及各代理运行阶段
+func (m *Manager) GetClientRuntimeStatus(id uint) *ClientRuntimeStatus {
+	val, ok := m.clients.Load(id)
+	if !ok {
+		return &ClientRuntimeStatus{Status: "stopped", LoginStatus: "stopped", Proxies: []ProxyRuntimeStatus{}}
+	}
+	entry := val.(*clientEntry)
+
+	st := &ClientRuntimeStatus{Status: "running", Proxies: []ProxyRuntimeStatus{}}
+	exporter := entry.svc.StatusExporter()
+	anyReported := false
+	proxyCfgs := entry.getProxyCfgs()
+	for _, pc := range proxyCfgs {
+		base := pc.GetBaseConfig()
+		ps := ProxyRuntimeStatus{Name: base.Name, Type: base.Type}
+		if ws, ok := exporter.GetProxyStatus(base.Name); ok {
+			anyReported = true
+			ps.Phase = ws.Phase
+			ps.Err = ws.Err
+			ps.RemoteAddr = ws.RemoteAddr
+		}
+		st.Proxies = append(st.Proxies, ps)
+	}
+
+	connected, connectedAt, lastErr := entry.tracker.snapshot()
+	// 登录成功后 frpc 才会创建控制器并登记代理，因此能查到代理状态即表示已登录
+	if anyReported || (connected && len(proxyCfgs) == 0) {
+		st.LoginStatus = "logged_in"
+		if connected {
+			st.ConnectedAt = &connectedAt
+		}
+	} else {
+		st.LoginStatus = "connecting"
+		st.LastError = lastErr
+	}
+	return st
+}
+
+// ===== 服务端运行状态 =====
+
+// frpsProxyTypes frps Dashboard 按类型查询代理时使用的类型列表
+var frpsProxyTypes = []string{"tcp", "udp", "http", "https", "tcpmux", "stcp", "sudp", "xtcp"}
+
+// ServerRuntimeInfo FRP 服务端运行信息（来自内置 frps 的 Dashboard API）
+type ServerRuntimeInfo struct {
+	Server  *frpsapi.ServerInfoResp   `json:"server"`
+	Clients []frpsapi.ClientInfoResp  `json:"clients"`
+	Proxies []*frpsapi.ProxyStatsInfo `json:"proxies"`
+}
+
+// GetServerRuntimeInfo 获取服务端已连接客户端、代理及流量统计
+// frps 仅通过 Dashboard API 暴露这些数据，因此要求配置了 Dashboard 端口
+func (m *Manager) GetServerRuntimeInfo(id uint) (*ServerRuntimeInfo, error) {
+	if _, ok := m.servers.Load(id); !ok {
+		return nil, fmt.Errorf("FRP 服务端未运行")
+	}
+	var cfg model.FrpsConfig
+	if err := m.db.First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("FRP 服务端配置不存在: %w", err)
+	}
+	if cfg.DashboardPort == 0 {
+		return nil, fmt.Errorf("未配置 Dashboard 端口，无法获取服务端运行信息")
+	}
+
+	host := cfg.DashboardAddr
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	baseURL := "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.DashboardPort))
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+
+	get := func(path string, out any) error {
+		req, err := http.NewRequest(http.MethodGet, baseURL+path, nil)
+		if err != nil {
+			return err
+		}
+		if cfg.DashboardUser != "" || cfg.DashboardPassword != "" {
+			req.SetBasicAuth(cfg.DashboardUser, cfg.DashboardPassword)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("请求 %s 失败: HTTP %d", path, resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	info := &ServerRuntimeInfo{
+		Server:  &frpsapi.ServerInfoResp{},
+		Clients: []frpsapi.ClientInfoResp{},
+		Proxies: []*frpsapi.ProxyStatsInfo{},
+	}
+	if err := get("/api/serverinfo", info.Server); err != nil {
+		return nil, fmt.Errorf("获取服务端信息失败: %w", err)
+	}
+	if err := get("/api/clients", &info.Clients); err != nil {
+		return nil, fmt.Errorf("获取客户端列表失败: %w", err)
+	}
+	for _, t := range frpsProxyTypes {
+		var resp frpsapi.GetProxyInfoResp
+		if err := get("/api/proxy/"+t, &resp); err != nil {
+			return nil, fmt.Errorf("获取 %s 代理列表失败: %w", strings.ToUpper(t), err)
+		}
+		info.Proxies = append(info.Proxies, resp.Proxies...)
+	}
+	return info, nil
+}
@@ -0,0 +1,362 @@
+package frp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/fatedier/frp/pkg/config/v1"
+	"github.com/netpanel/netpanel/model"
+)
+
+// loadDomainCert 查询域名证书文件路径，label 用于错误提示
+func (m *Manager) loadDomainCert(id uint, label string) (certFile, keyFile string, err error) {
+	var dc model.DomainCert
+	if err := m.db.First(&dc, id).Error; err != nil {
+		return "", "", fmt.Errorf("%s ID=%d 查询失败: %w", label, id, err)
+	}
+	if dc.CertFile == "" || dc.KeyFile == "" {
+		return "", "", fmt.Errorf("%s ID=%d 的证书文件路径为空（证书可能尚未签发）", label, id)
+	}
+	return dc.CertFile, dc.KeyFile, nil
+}
+
+// applyServerCerts 将关联的域名证书写入 frps 配置
+// 启用 HTTPS 终止时返回终止服务所需的证书，同时把 frps 的 HTTPS 虚拟主机并入 BindPort，
+// 由终止服务在原端口上按 SNI 分流：*.SubDomainHost 终止 TLS，其余原样透传给 frps
+func (m *Manager) applyServerCerts(cfg *model.FrpsConfig, frpCfg *v1.ServerConfig) (*tls.Certificate, error) {
+	if cfg.TransportTLSCertID > 0 {
+		certFile, keyFile, err := m.loadDomainCert(cfg.TransportTLSCertID, "传输层 TLS 证书")
+		if err != nil {
+			return nil, err
+		}
+		frpCfg.Transport.TLS.CertFile = certFile
+		frpCfg.Transport.TLS.KeyFile = keyFile
+	}
+
+	if !cfg.VhostHTTPSTerminate {
+		return nil, nil
+	}
+	if cfg.VhostHTTPSPort == 0 || cfg.VhostHTTPPort == 0 {
+		return nil, fmt.Errorf("HTTPS 终止需要同时配置 HTTP 与 HTTPS 虚拟主机端口")
+	}
+	if cfg.SubDomainHost == "" {
+		return nil, fmt.Errorf("HTTPS 终止需要配置子域名根域名")
+	}
+	if cfg.VhostHTTPSCertID == 0 {
+		return nil, fmt.Errorf("HTTPS 终止需要关联泛域名证书")
+	}
+	cert, err := m.loadTerminateCert(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// frps 的 HTTPS 虚拟主机与 BindPort 复用同一监听（frps 按首字节分流），
+	// 继续为 type=https 的代理提供 SNI 路由，且不额外监听端口。
+	// 复用要求代理监听地址与绑定地址一致，否则 frps 会在 ProxyBindAddr 上单独监听 BindPort
+	if frpCfg.ProxyBindAddr != frpCfg.BindAddr {
+		return nil, fmt.Errorf("HTTPS 终止要求代理监听地址与绑定地址一致（当前 %s 与 %s）", frpCfg.ProxyBindAddr, frpCfg.BindAddr)
+	}
+	frpCfg.VhostHTTPSPort = frpCfg.BindPort
+	return cert, nil
+}
+
+// loadTerminateCert 加载 HTTPS 终止证书，并校验其覆盖 *.SubDomainHost
+func (m *Manager) loadTerminateCert(cfg *model.FrpsConfig) (*tls.Certificate, error) {
+	certFile, keyFile, err := m.loadDomainCert(cfg.VhostHTTPSCertID, "HTTPS 终止证书")
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载 HTTPS 终止证书失败: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("解析 HTTPS 终止证书失败: %w", err)
+	}
+	// 任取一个子域名校验证书是否为 *.SubDomainHost 泛域名证书
+	if err := leaf.VerifyHostname("netpanel." + cfg.SubDomainHost); err != nil {
+		return nil, fmt.Errorf("HTTPS 终止证书未覆盖 *.%s: %w", cfg.SubDomainHost, err)
+	}
+	return &cert, nil
+}
+
+// terminatorBindAddr 返回 frps 虚拟主机实际监听的地址
+func terminatorBindAddr(cfg *model.FrpsConfig) string {
+	bindAddr := cfg.ProxyBindAddr
+	if bindAddr == "" {
+		bindAddr = cfg.BindAddr
+	}
+	if bindAddr == "" {
+		bindAddr = "0.0.0.0"
+	}
+	return bindAddr
+}
+
+// terminatorTargetHost 返回本机访问 frps 虚拟主机端口使用的地址
+func terminatorTargetHost(cfg *model.FrpsConfig) string {
+	host := terminatorBindAddr(cfg)
+	if host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return host
+}
+
+// httpsTerminator 监听 VhostHTTPSPort，按 ClientHello 中的 SNI 分流：
+// 属于 SubDomainHost 的连接终止 TLS 后转发到 frps 的 HTTP 虚拟主机端口，
+// 其余连接原样透传到与 BindPort 复用的 frps HTTPS 虚拟主机（type=https 代理）
+type httpsTerminator struct {
+	ln          net.Listener
+	srv         *http.Server
+	conns       *connListener
+	rootDomain  string
+	passthrough string
+	cert        atomic.Pointer[tls.Certificate]
+}
+
+// startHTTPSTerminator 启动 HTTPS 终止服务，frpsPort 为透传目标，即 frps 承载 HTTPS 虚拟主机的 BindPort
+func startHTTPSTerminator(cfg *model.FrpsConfig, cert *tls.Certificate, frpsPort int) (*httpsTerminator, error) {
+	targetHost := terminatorTargetHost(cfg)
+	target := &url.URL{Scheme: "http", Host: net.JoinHostPort(targetHost, strconv.Itoa(cfg.VhostHTTPPort))}
+
+	rootDomain := strings.ToLower(cfg.SubDomainHost)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		// 保留原始 Host，frps 依据 Host 路由到对应代理
+		req.Header.Set("X-Forwarded-Proto", "https")
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.ToLower(r.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !matchDomain(host, rootDomain) {
+			http.Error(w, "misdirected request", http.StatusMisdirectedRequest)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	})
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(terminatorBindAddr(cfg), strconv.Itoa(cfg.VhostHTTPSPort)))
+	if err != nil {
+		return nil, fmt.Errorf("HTTPS 终止服务监听失败: %w", err)
+	}
+	t := &httpsTerminator{
+		ln:          ln,
+		conns:       newConnListener(ln.Addr()),
+		rootDomain:  rootDomain,
+		passthrough: net.JoinHostPort(targetHost, strconv.Itoa(frpsPort)),
+	}
+	t.cert.Store(cert)
+	t.srv = &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 30 * time.Second,
+		TLSConfig: &tls.Config{
+			// 每次握手读取当前证书，续期时只需替换证书
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				return t.cert.Load(), nil
+			},
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+	go t.srv.ServeTLS(t.conns, "", "")
+	go t.acceptLoop()
+	return t, nil
+}
+
+// SetCertificate 替换终止服务使用的证书，已建立的连接不受影响
+func (t *httpsTerminator) SetCertificate(cert *tls.Certificate) {
+	t.cert.Store(cert)
+}
+
+// acceptLoop 接受外部连接并逐个分流
+func (t *httpsTerminator) acceptLoop() {
+	for {
+		conn, err := t.ln.Accept()
+		if err != nil {
+			return
+		}
+		go t.route(conn)
+	}
+}
+
+// route 读取 ClientHello 中的 SNI 并决定终止或透传
+func (t *httpsTerminator) route(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	serverName, peeked, err := peekServerName(conn)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+	wrapped := &prefixConn{Conn: conn, r: io.MultiReader(bytes.NewReader(peeked), conn)}
+
+	if matchDomain(strings.ToLower(serverName), t.rootDomain) {
+		if !t.conns.push(wrapped) {
+			conn.Close()
+		}
+		return
+	}
+
+	upstream, err := net.DialTimeout("tcp", t.passthrough, 10*time.Second)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go func() {
+		io.Copy(upstream, wrapped)
+		upstream.Close()
+	}()
+	io.Copy(conn, upstream)
+	conn.Close()
+}
+
+// Close 关闭 HTTPS 终止服务
+func (t *httpsTerminator) Close() {
+	t.ln.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	t.srv.Shutdown(ctx)
+}
+
+// matchDomain 判断 host 是否为根域名或其子域名
+func matchDomain(host, rootDomain string) bool {
+	return host == rootDomain || strings.HasSuffix(host, "."+rootDomain)
+}
+
+// errHelloRead 用于在读到 ClientHello 后中止握手
+var errHelloRead = errors.New("client hello read")
+
+// peekServerName 读取 TLS ClientHello 中的 SNI，并返回已读取的原始字节以便回放
+func peekServerName(conn net.Conn) (string, []byte, error) {
+	var buf bytes.Buffer
+	var serverName string
+	err := tls.Server(readOnlyConn{r: io.TeeReader(conn, &buf)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, errHelloRead
+		},
+	}).Handshake()
+	if !errors.Is(err, errHelloRead) {
+		if err == nil {
+			err = errors.New("未读取到 ClientHello")
+		}
+		return "", nil, err
+	}
+	return serverName, buf.Bytes(), nil
+}
+
+// readOnlyConn 只允许读取的连接，用于解析 ClientHello 时丢弃握手响应
+type readOnlyConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c readOnlyConn) Read(p []byte) (int, error)       { return c.r.Read(p) }
+func (c readOnlyConn) Write(p []byte) (int, error)      { return 0, io.ErrClosedPipe }
+func (c readOnlyConn) Close() error                     { return nil }
+func (c readOnlyConn) LocalAddr() net.Addr              { return nil }
+func (c readOnlyConn) RemoteAddr() net.Addr             { return nil }
+func (c readOnlyConn) SetDeadline(time.Time) error      { return nil }
+func (c readOnlyConn) SetReadDeadline(time.Time) error  { return nil }
+func (c readOnlyConn) SetWriteDeadline(time.Time) error { return nil }
+
+// prefixConn 先回放已读取的 ClientHello 字节，再继续读取原连接
+type prefixConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *prefixConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// connListener 将分流后需要终止 TLS 的连接交给 http.Server
+type connListener struct {
+	addr   net.Addr
+	ch     chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{addr: addr, ch: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+// push 投递连接，监听已关闭时返回 false
+func (l *connListener) push(conn net.Conn) bool {
+	select {
+	case l.ch <- conn:
+		return true
+	case <-l.closed:
+		return false
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.ch:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *connListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr { return l.addr }
+
+// OnCertUpdated 证书续期后使新证书生效：
+// HTTPS 终止证书直接替换，不中断隧道；传输层 TLS 证书由 frps 启动时加载，只能重启服务端
+func (m *Manager) OnCertUpdated(certID uint) {
+	var ids []uint
+	m.servers.Range(func(key, _ any) bool {
+		ids = append(ids, key.(uint))
+		return true
+	})
+	for _, id := range ids {
+		var cfg model.FrpsConfig
+		if err := m.db.First(&cfg, id).Error; err != nil {
+			continue
+		}
+		if cfg.TransportTLSCertID == certID {
+			m.log.Infof("[FRP服务][%s] 传输层证书已更新，重启服务端", cfg.Name)
+			if err := m.RestartServer(id); err != nil {
+				m.log.Errorf("[FRP服务][%s] 证书更新后重启失败: %v", cfg.Name, err)
+			}
+			continue
+		}
+		if !cfg.VhostHTTPSTerminate || cfg.VhostHTTPSCertID != certID {
+			continue
+		}
+		val, ok := m.servers.Load(id)
+		if !ok || val.(*serverEntry).terminator == nil {
+			continue
+		}
+		cert, err := m.loadTerminateCert(&cfg)
+		if err != nil {
+			m.log.Errorf("[FRP服务][%s] 重新加载 HTTPS 终止证书失败: %v", cfg.Name, err)
+			continue
+		}
+		val.(*serverEntry).terminator.SetCertificate(cert)
+		m.log.Infof("[FRP服务][%s] HTTPS 终止证书已更新", cfg.Name)
+	}
+}
@@ -0,0 +1,348 @@
+package frp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatedier/frp/pkg/config/types"
+	v1 "github.com/fatedier/frp/pkg/config/v1"
+	plugin "github.com/fatedier/frp/pkg/plugin/server"
+	"github.com/netpanel/netpanel/model"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// userAuthPluginName 注册到 frps 的服务端插件名称
+const userAuthPluginName = "netpanel-user-auth"
+
+// proxyRetryTimeout 注册失败的代理占用配额的最长时间
+// frpc 每 30 秒重试一次注册失败的代理，超过该时间未再重试说明客户端已放弃，释放其配额
+const proxyRetryTimeout = 75 * time.Second
+
+// proxyReservation 已放行代理占用的配额
+// frps 在插件放行后才注册代理：注册成功的代理关闭时会收到 CloseProxy，注册失败则不会有任何通知。
+// 同一客户端再次提交同名代理说明上次注册失败（已注册的代理不会重复提交），此时改为按重试租约计时；
+// 收到该代理的用户连接则确认其已在本实例注册。
+type proxyReservation struct {
+	runID    string
+	lastSeen time.Time
+	failing  bool
+}
+
+// userAuthServer 实现 frps HTTP 服务端插件协议，按 FrpsUser 表做多用户鉴权
+// 每个启用了多用户鉴权的 frps 实例对应一个仅监听本机回环地址的插件服务
+type userAuthServer struct {
+	db     *gorm.DB
+	log    *logrus.Logger
+	frpsID uint
+	name   string
+	srv    *http.Server
+	ln     net.Listener
+
+	mu      sync.Mutex
+	proxies map[string]map[string]*proxyReservation // user -> 代理名 -> 配额占用
+	ports   map[string][]types.PortsRange           // AllowPorts -> 解析结果，避免每次注册代理时重复解析
+}
+
+// startUserAuthServer 启动插件服务，返回需写入 frps 配置的插件选项
+func startUserAuthServer(db *gorm.DB, log *logrus.Logger, frpsID uint, name string) (*userAuthServer, v1.HTTPPluginOptions, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, v1.HTTPPluginOptions{}, fmt.Errorf("启动多用户鉴权插件失败: %w", err)
+	}
+	s := &userAuthServer{
+		db:      db,
+		log:     log,
+		frpsID:  frpsID,
+		name:    name,
+		ln:      ln,
+		proxies: map[string]map[string]*proxyReservation{},
+		ports:   map[string][]types.PortsRange{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/handler", s.handle)
+	s.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go s.srv.Serve(ln)
+
+	opts := v1.HTTPPluginOptions{
+		Name: userAuthPluginName,
+		Addr: ln.Addr().String(),
+		Path: "/handler",
+		Ops:  []string{plugin.OpLogin, plugin.OpNewProxy, plugin.OpCloseProxy, plugin.OpNewUserConn},
+	}
+	return s, opts, nil
+}
+
+// Close 关闭插件服务
+func (s *userAuthServer) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	s.srv.Shutdown(ctx)
+}
+
+func (s *userAuthServer) handle(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Op      string          `json:"op"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resp *plugin.Response
+	switch req.Op {
+	case plugin.OpLogin:
+		var content plugin.LoginContent
+		if err := json.Unmarshal(req.Content, &content); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp = s.handleLogin(&content)
+	case plugin.OpNewProxy:
+		var content plugin.NewProxyContent
+		if err := json.Unmarshal(req.Content, &content); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp = s.handleNewProxy(&content)
+	case plugin.OpCloseProxy:
+		var content plugin.CloseProxyContent
+		if err := json.Unmarshal(req.Content, &content); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.removeProxy(content.User.User, content.ProxyName)
+		resp = &plugin.Response{Unchange: true}
+	case plugin.OpNewUserConn:
+		var content plugin.NewUserConnContent
+		if err := json.Unmarshal(req.Content, &content); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp = s.handleNewUserConn(&content)
+	default:
+		resp = &plugin.Response{Unchange: true}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// reject 构造拒绝响应并记录日志
+func (s *userAuthServer) reject(user, format string, args ...any) *plugin.Response {
+	reason := fmt.Sprintf(format, args...)
+	s.log.Warnf("[FRP服务][%s] 用户 [%s] 被拒绝: %s", s.name, user, reason)
+	return &plugin.Response{Reject: true, RejectReason: reason}
+}
+
+// loadUser 查询可用的服务端用户
+func (s *userAuthServer) loadUser(username string) (*model.FrpsUser, error) {
+	if username == "" {
+		return nil, fmt.Errorf("未指定用户名")
+	}
+	var u model.FrpsUser
+	if err := s.db.Where("frps_id = ? AND username = ?", s.frpsID, username).First(&u).Error; err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	if !u.Enable {
+		return nil, fmt.Errorf("用户已禁用")
+	}
+	if u.ExpireAt != nil && time.Now().After(*u.ExpireAt) {
+		return nil, fmt.Errorf("用户已过期")
+	}
+	return &u, nil
+}
+
+func (s *userAuthServer) handleLogin(c *plugin.LoginContent) *plugin.Response {
+	u, err := s.loadUser(c.User)
+	if err != nil {
+		return s.reject(c.User, "%v", err)
+	}
+	token := c.Metas["token"]
+	if u.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(u.Token)) != 1 {
+		return s.reject(c.User, "token 错误")
+	}
+	return &plugin.Response{Unchange: true}
+}
+
+func (s *userAuthServer) handleNewProxy(c *plugin.NewProxyContent) *plugin.Response {
+	username := c.User.User
+	u, err := s.loadUser(username)
+	if err != nil {
+		return s.reject(username, "%v", err)
+	}
+
+	// 端口限制（tcp/udp）
+	if u.AllowPorts != "" && (c.ProxyType == "tcp" || c.ProxyType == "udp") {
+		if c.RemotePort == 0 {
+			return s.reject(username, "代理 [%s] 必须指定 remotePort", c.ProxyName)
+		}
+		ranges, err := s.allowPorts(u.AllowPorts)
+		if err != nil {
+			return s.reject(username, "%v", err)
+		}
+		if !portAllowed(ranges, c.RemotePort) {
+			return s.reject(username, "代理 [%s] 不允许使用端口 %d", c.ProxyName, c.RemotePort)
+		}
+	}
+
+	// 子域名限制
+	if c.SubDomain != "" && !matchList(u.AllowSubdomains, func(item string) bool {
+		return strings.EqualFold(item, c.SubDomain)
+	}) {
+		return s.reject(username, "代理 [%s] 不允许使用子域名 %s", c.ProxyName, c.SubDomain)
+	}
+
+	// 自定义域名限制（按后缀匹配）
+	for _, d := range c.CustomDomains {
+		domain := strings.ToLower(d)
+		if !matchList(u.AllowDomains, func(item string) bool {
+			return domain == item || strings.HasSuffix(domain, "."+item)
+		}) {
+			return s.reject(username, "代理 [%s] 不允许使用域名 %s", c.ProxyName, d)
+		}
+	}
+
+	// 代理数量配额
+	if !s.reserveProxy(username, c.ProxyName, c.User.RunID, u.MaxProxies) {
+		return s.reject(username, "代理数量已达上限 %d", u.MaxProxies)
+	}
+
+	// 带宽配额：强制使用服务端限速
+	if u.BandwidthLimit != "" {
+		c.BandwidthLimit = u.BandwidthLimit
+		c.BandwidthLimitMode = "server"
+		return &plugin.Response{Unchange: false, Content: c}
+	}
+	return &plugin.Response{Unchange: true}
+}
+
+func (s *userAuthServer) handleNewUserConn(c *plugin.NewUserConnContent) *plugin.Response {
+	// 用户被禁用或过期后，拒绝已建立代理上的新连接
+	if _, err := s.loadUser(c.User.User); err != nil {
+		return s.reject(c.User.User, "%v", err)
+	}
+	s.confirmProxy(c.User.User, c.ProxyName)
+	return &plugin.Response{Unchange: true}
+}
+
+// allowPorts 返回用户允许端口的解析结果，同一配置只解析一次
+func (s *userAuthServer) allowPorts(allowPorts string) ([]types.PortsRange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ranges, ok := s.ports[allowPorts]; ok {
+		return ranges, nil
+	}
+	ranges, err := parseAllowPorts(allowPorts)
+	if err != nil {
+		return nil, err
+	}
+	s.ports[allowPorts] = ranges
+	return ranges, nil
+}
+
+// ValidateAllowPorts 校验服务端用户的允许端口配置，如 "6000-6100,7000"，供保存前检查
+func ValidateAllowPorts(allowPorts string) error {
+	if allowPorts == "" {
+		return nil
+	}
+	_, err := parseAllowPorts(allowPorts)
+	return err
+}
+
+func parseAllowPorts(allowPorts string) ([]types.PortsRange, error) {
+	ranges, err := types.NewPortsRangeSliceFromString(allowPorts)
+	if err != nil {
+		return nil, fmt.Errorf("允许端口格式错误: %s", allowPorts)
+	}
+	for _, r := range ranges {
+		start, end := r.Start, r.End
+		if r.Single > 0 {
+			start, end = r.Single, r.Single
+		}
+		if start < 1 || end > 65535 {
+			return nil, fmt.Errorf("允许端口超出范围 1-65535: %s", allowPorts)
+		}
+	}
+	return ranges, nil
+}
+
+func portAllowed(ranges []types.PortsRange, port int) bool {
+	for _, r := range ranges {
+		if port == r.Single || (r.Single == 0 && port >= r.Start && port <= r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// reserveProxy 为代理占用配额，超出最大代理数时返回 false
+func (s *userAuthServer) reserveProxy(username, proxyName, runID string, maxProxies int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set := s.proxies[username]
+	if set == nil {
+		set = map[string]*proxyReservation{}
+		s.proxies[username] = set
+	}
+	now := time.Now()
+	for name, r := range set {
+		if r.failing && now.Sub(r.lastSeen) > proxyRetryTimeout {
+			delete(set, name)
+		}
+	}
+	if r, exists := set[proxyName]; exists {
+		// 同一客户端重复提交：上次放行后注册失败，正在重试
+		r.failing = r.runID == runID
+		r.runID = runID
+		r.lastSeen = now
+		return true
+	}
+	if maxProxies > 0 && len(set) >= maxProxies {
+		return false
+	}
+	set[proxyName] = &proxyReservation{runID: runID, lastSeen: now}
+	return true
+}
+
+// confirmProxy 代理已有用户连接，确认其在本实例注册成功
+func (s *userAuthServer) confirmProxy(username, proxyName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.proxies[username][proxyName]; r != nil {
+		r.failing = false
+	}
+}
+
+func (s *userAuthServer) removeProxy(username, proxyName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if set := s.proxies[username]; set != nil {
+		delete(set, proxyName)
+		if len(set) == 0 {
+			delete(s.proxies, username)
+		}
+	}
+}
+
+// matchList 判断逗号分隔列表中是否有匹配项，列表包含 * 时恒为真
+func matchList(list string, match func(item string) bool) bool {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if item == "*" || match(item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package nps
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	npsClient "github.com/djylb/nps/client"
+	npsCommon "github.com/djylb/nps/lib/common"
+	"github.com/djylb/nps/lib/config"
+	"github.com/djylb/nps/lib/file"
+	"github.com/netpanel/netpanel/model"
+)
+
+// 隧道注册状态
+const (
+	tunnelPending    = "pending"
+	tunnelRegistered = "registered"
+	tunnelFailed     = "failed"
+)
+
+// TunnelRuntimeStatus 单条隧道在服务端的注册状态
+type TunnelRuntimeStatus struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"` // pending/registered/failed
+	Error  string `json:"error"`
+}
+
+// ClientRuntimeStatus NPS 客户端运行状态
+type ClientRuntimeStatus struct {
+	Status    string                `json:"status"`    // running/stopped
+	Mode      string                `json:"mode"`      // vkey：仅以 vkey 连接，隧道在服务端配置；config：按本地隧道配置注册
+	Connected bool                  `json:"connected"` // 是否已与服务端建立连接
+	LastError string                `json:"last_error"`
+	Tunnels   []TunnelRuntimeStatus `json:"tunnels"`
+}
+
+// clientState 客户端运行时状态，由运行 goroutine 更新
+type clientState struct {
+	mu        sync.Mutex
+	mode      string
+	connected bool
+	lastErr   string
+	tunnels   []TunnelRuntimeStatus
+}
+
+func (s *clientState) setConnected(connected bool, lastErr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = connected
+	if lastErr != "" || connected {
+		s.lastErr = lastErr
+	}
+}
+
+func (s *clientState) setTunnel(i int, status, errMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tunnels[i].Status = status
+	s.tunnels[i].Error = errMsg
+}
+
+func (s *clientState) snapshot() *ClientRuntimeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tunnels := make([]TunnelRuntimeStatus, len(s.tunnels))
+	copy(tunnels, s.tunnels)
+	return &ClientRuntimeStatus{
+		Status:    "running",
+		Mode:      s.mode,
+		Connected: s.connected,
+		LastError: s.lastErr,
+		Tunnels:   tunnels,
+	}
+}
+
+// GetClientRuntimeStatus 获取客户端连接状态及各隧道注册状态
+func (m *Manager) GetClientRuntimeStatus(id uint) *ClientRuntimeStatus {
+	val, ok := m.clients.Load(id)
+	if !ok {
+		return &ClientRuntimeStatus{Status: "stopped", Tunnels: []TunnelRuntimeStatus{}}
+	}
+	return val.(*clientEntry).state.snapshot()
+}
+
+// ValidateTunnel 校验隧道配置
+func ValidateTunnel(t *model.NpsTunnel) error {
+	t.Type = strings.ToLower(strings.TrimSpace(t.Type))
+	if t.Name == "" {
+		return fmt.Errorf("隧道名称不能为空")
+	}
+	if strings.ContainsAny(t.Name, "[]\r\n") {
+		return fmt.Errorf("隧道名称不能包含方括号或换行")
+	}
+	validPort := func(p int) bool { return p > 0 && p <= 65535 }
+	switch t.Type {
+	case "tcp", "udp", "socks5":
+		if !validPort(t.RemotePort) {
+			return fmt.Errorf("%s 隧道需要填写有效的服务端端口", t.Type)
+		}
+	case "http", "https":
+		if t.HostHeader == "" {
+			return fmt.Errorf("%s 隧道需要填写域名", t.Type)
+		}
+	case "secret", "p2p":
+		if t.Password == "" {
+			return fmt.Errorf("%s 隧道需要填写唯一密钥", t.Type)
+		}
+	default:
+		return fmt.Errorf("不支持的隧道类型: %s", t.Type)
+	}
+	if t.Type != "socks5" && !validPort(t.LocalPort) {
+		return fmt.Errorf("%s 隧道需要填写有效的本地端口", t.Type)
+	}
+	return nil
+}
+
+// npcItem 需要向服务端注册的隧道（域名解析类隧道为 host，其余为 task）
+type npcItem struct {
+	host *file.Host
+	task *file.Tunnel
+}
+
+// buildNpcConfig 根据 NpsTunnel 生成 npc 配置（等价于 npc.conf 中的 [common] 及各隧道段）
+func buildNpcConfig(serverAddr, vkey, connType string, tunnels []model.NpsTunnel) (*config.Config, []npcItem, error) {
+	common := &config.CommonConfig{
+		Server:           serverAddr,
+		VKey:             vkey,
+		Tp:               connType,
+		AutoReconnection: true,
+		Client:           file.NewClient("", true, true),
+		DisconnectTime:   60,
+	}
+	cnf := &config.Config{CommonConfig: common}
+
+	items := make([]npcItem, 0, len(tunnels))
+	for i := range tunnels {
+		t := &tunnels[i]
+		if err := ValidateTunnel(t); err != nil {
+			return nil, nil, fmt.Errorf("隧道 [%s]: %w", t.Name, err)
+		}
+		localIP := t.LocalIP
+		if localIP == "" {
+			localIP = "127.0.0.1"
+		}
+		target := &file.Target{TargetStr: net.JoinHostPort(localIP, strconv.Itoa(t.LocalPort))}
+
+		switch t.Type {
+		case "http", "https":
+			h := &file.Host{
+				Host:         t.HostHeader,
+				Location:     "/",
+				Remark:       t.Name,
+				Scheme:       t.Type,
+				Target:       target,
+				MultiAccount: new(file.MultiAccount),
+			}
+			// https 隧道直接透传 TLS 到本地 HTTPS 服务
+			h.HttpsJustProxy = t.Type == "https"
+			cnf.Hosts = append(cnf.Hosts, h)
+			items = append(items, npcItem{host: h})
+		default:
+			task := &file.Tunnel{
+				Mode:         t.Type,
+				Remark:       t.Name,
+				Password:     t.Password,
+				Target:       target,
+				MultiAccount: new(file.MultiAccount),
+			}
+			if t.RemotePort > 0 {
+				task.Ports = strconv.Itoa(t.RemotePort)
+			}
+			if t.Type == "socks5" {
+				task.Target = new(file.Target)
+			}
+			cnf.Tasks = append(cnf.Tasks, task)
+			items = append(items, npcItem{task: task})
+		}
+	}
+	return cnf, items, nil
+}
+
+// runConfigClient 以配置文件模式运行客户端：每次连接时先向服务端注册隧道，再建立隧道连接
+// 流程与 npc StartFromFile 一致，但不会调用 os.Exit，并记录每条隧道的注册结果
+func (m *Manager) runConfigClient(ctx context.Context, name string, cnf *config.Config, items []npcItem, state *clientState) {
+	common := cnf.CommonConfig
+	var uuid string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		vkey, err := m.registerTunnels(common, &uuid, items, state)
+		if err != nil {
+			m.log.Warnf("[NPS客户端][%s] 注册隧道失败: %v", name, err)
+			state.setConnected(false, err.Error())
+		} else {
+			state.setConnected(true, "")
+			runCtx, cancel := context.WithCancel(ctx)
+			fsm := npsClient.NewFileServerManager(runCtx)
+			npsClient.NewRPClient(common.Server, vkey, common.Tp, common.ProxyUrl, uuid, cnf, common.DisconnectTime, fsm).Start(runCtx)
+			fsm.CloseAll()
+			cancel()
+			state.setConnected(false, "")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			m.log.Infof("[NPS客户端][%s] 连接断开，5秒后重连...", name)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// registerTunnels 建立配置连接并逐条注册隧道，返回隧道连接使用的 vkey
+func (m *Manager) registerTunnels(common *config.CommonConfig, uuid *string, items []npcItem, state *clientState) (string, error) {
+	c, cid, err := npsClient.NewConn(common.Tp, common.VKey, common.Server, common.ProxyUrl)
+	if err != nil {
+		return "", fmt.Errorf("连接服务器失败: %w", err)
+	}
+	defer c.Close()
+	if *uuid == "" {
+		*uuid = cid
+	}
+	if err := npsClient.SendType(c, npsCommon.WORK_CONFIG, *uuid); err != nil {
+		return "", fmt.Errorf("发送配置请求失败: %w", err)
+	}
+
+	// 使用服务端公共 vkey 时，服务端会按上报的客户端配置新建客户端并下发临时 vkey
+	vkey := common.VKey
+	var isPub bool
+	_ = binary.Read(c, binary.LittleEndian, &isPub)
+	if isPub {
+		if _, err := c.SendInfo(common.Client, npsCommon.NEW_CONF); err != nil {
+			return "", fmt.Errorf("上报客户端配置失败: %w", err)
+		}
+		if !c.GetAddStatus() {
+			return "", fmt.Errorf("服务端拒绝新建客户端")
+		}
+		b, err := c.GetShortContent(16)
+		if err != nil {
+			return "", fmt.Errorf("读取临时 vkey 失败: %w", err)
+		}
+		vkey = string(b)
+	}
+
+	for i := range items {
+		state.setTunnel(i, tunnelPending, "")
+	}
+
+	// 服务端拒绝某条隧道后会关闭配置连接，其后的隧道均无法注册
+	broken := false
+	for i, it := range items {
+		if broken {
+			state.setTunnel(i, tunnelFailed, "前序隧道注册失败，服务端已关闭配置连接")
+			continue
+		}
+		var flag string
+		var info any
+		if it.host != nil {
+			flag, info = npsCommon.NEW_HOST, it.host
+		} else {
+			flag, info = npsCommon.NEW_TASK, it.task
+		}
+		if _, err := c.SendInfo(info, flag); err != nil {
+			state.setTunnel(i, tunnelFailed, err.Error())
+			broken = true
+			continue
+		}
+		if !c.GetAddStatus() {
+			state.setTunnel(i, tunnelFailed, "服务端拒绝注册：端口或域名可能已被占用或不允许使用")
+			broken = true
+			continue
+		}
+		state.setTunnel(i, tunnelRegistered, "")
+	}
+	return vkey, nil
+}
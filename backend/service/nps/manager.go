@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
 	"time"
 
 	npsClient "github.com/djylb/nps/client"
+	"github.com/djylb/nps/lib/config"
 	"github.com/netpanel/netpanel/model"
+	"github.com/netpanel/netpanel/pkg/supervisor"
+	"github.com/netpanel/netpanel/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -18,17 +22,18 @@ import (
 type clientEntry struct {
 	cancel context.CancelFunc
 	done   chan struct{}
+	state  *clientState
 }
 
 // serverEntry 服务端运行实例（通过子进程方式，因为beego是全局单例）
 type serverEntry struct {
-	cancel context.CancelFunc
-	done   chan struct{}
+	proc *supervisor.Process
 }
 
 // Manager NPS 管理器
 // 服务端：每个实例生成独立配置文件，通过 beego 加载后在独立 goroutine 中运行
-// 客户端：直接调用 nps client 包的 NewRPClient，支持多实例并发
+// 客户端：直接调用 nps client 包的 NewRPClient，支持多实例并发；
+// 配置了隧道时按配置文件模式在连接时向服务端注册隧道
 type Manager struct {
 	db      *gorm.DB
 	log     *logrus.Logger
@@ -64,12 +69,7 @@ func (m *Manager) StartAll() {
 // StopAll 停止所有 NPS 实例
 func (m *Manager) StopAll() {
 	m.servers.Range(func(key, value interface{}) bool {
-		entry := value.(*serverEntry)
-		entry.cancel()
-		select {
-		case <-entry.done:
-		case <-time.After(5 * time.Second):
-		}
+		value.(*serverEntry).proc.Stop()
 		return true
 	})
 	m.clients.Range(func(key, value interface{}) bool {
@@ -98,6 +98,12 @@ func (m *Manager) StartServer(id uint) error {
 		return fmt.Errorf("NPS 服务端 [%s] 未启用", cfg.Name)
 	}
 
+	// Web API 签名密钥，供 NetPanel 管理客户端/隧道/域名
+	if cfg.APIKey == "" {
+		cfg.APIKey = utils.GenerateKey(32)
+		m.db.Model(&model.NpsServerConfig{}).Where("id = ?", id).Update("api_key", cfg.APIKey)
+	}
+
 	// 生成配置文件
 	confPath, err := m.writeServerConfig(&cfg)
 	if err != nil {
@@ -105,31 +111,32 @@ func (m *Manager) StartServer(id uint) error {
 		return fmt.Errorf("生成 NPS 服务端配置失败: %w", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	done := make(chan struct{})
-
-	entry := &serverEntry{cancel: cancel, done: done}
-	m.servers.Store(id, entry)
-
-	go func() {
-		defer func() {
-			close(done)
-			m.servers.Delete(id)
-			m.db.Model(&model.NpsServerConfig{}).Where("id = ?", id).Update("status", "stopped")
-			m.log.Infof("[NPS服务端][%d] 已停止", id)
-		}()
-
-		if err := runNpsServer(ctx, confPath); err != nil {
-			m.log.Errorf("[NPS服务端][%s] 运行错误: %v", cfg.Name, err)
-			m.setServerError(id, err.Error())
-			return
-		}
-	}()
-
-	m.db.Model(&model.NpsServerConfig{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":     "running",
-		"last_error": "",
+	proc, err := supervisor.Start(supervisor.Config{
+		Name:    fmt.Sprintf("[NPS服务端][%s]", cfg.Name),
+		Log:     m.log,
+		Command: func() (*exec.Cmd, error) { return npsServerCommand(confPath) },
+		// NPS 内部可能以 os.Exit(0) 退出，任何非主动停止的退出都视为异常
+		OnExit: func(err error, output string) bool { return true },
+		OnStateChange: func(st supervisor.Status) {
+			switch st.State {
+			case supervisor.StateRunning:
+				m.db.Model(&model.NpsServerConfig{}).Where("id = ?", id).Updates(map[string]interface{}{
+					"status":     "running",
+					"last_error": "",
+				})
+			case supervisor.StateBackoff, supervisor.StateFailed:
+				m.setServerError(id, "进程退出: "+st.LastExit)
+			}
+		},
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
 	})
+	if err != nil {
+		m.setServerError(id, err.Error())
+		return fmt.Errorf("启动 NPS 服务端失败: %w", err)
+	}
+	m.servers.Store(id, &serverEntry{proc: proc})
+
 	m.log.Infof("[NPS服务端][%s] 已启动，桥接端口: %d，Web端口: %d",
 		cfg.Name, cfg.BridgePort, cfg.WebPort)
 	return nil
@@ -138,25 +145,31 @@ func (m *Manager) StartServer(id uint) error {
 // StopServer 停止指定 NPS 服务端
 func (m *Manager) StopServer(id uint) {
 	if val, ok := m.servers.Load(id); ok {
-		entry := val.(*serverEntry)
-		entry.cancel()
-		select {
-		case <-entry.done:
-		case <-time.After(5 * time.Second):
-		}
+		val.(*serverEntry).proc.Stop()
 		m.servers.Delete(id)
+		m.log.Infof("[NPS服务端][%d] 已停止", id)
 	}
 	m.db.Model(&model.NpsServerConfig{}).Where("id = ?", id).Update("status", "stopped")
 }
 
 // GetServerStatus 获取服务端运行状态
 func (m *Manager) GetServerStatus(id uint) string {
-	if _, ok := m.servers.Load(id); ok {
+	if val, ok := m.servers.Load(id); ok && val.(*serverEntry).proc.Alive() {
 		return "running"
 	}
 	return "stopped"
 }
 
+// GetServerProcess 获取服务端子进程的守护状态（运行时长、重启次数、最近输出），未运行时返回 nil
+func (m *Manager) GetServerProcess(id uint) *supervisor.Status {
+	val, ok := m.servers.Load(id)
+	if !ok {
+		return nil
+	}
+	st := val.(*serverEntry).proc.Status()
+	return &st
+}
+
 // setServerError 设置服务端错误状态
 func (m *Manager) setServerError(id uint, errMsg string) {
 	m.db.Model(&model.NpsServerConfig{}).Where("id = ?", id).Updates(map[string]interface{}{
@@ -209,6 +222,7 @@ web_password=%s
 web_port=%d
 web_base_url=
 web_open_ssl=false
+auth_key=%s
 
 disconnect_timeout=60
 `,
@@ -223,6 +237,7 @@ disconnect_timeout=60
 		webUsername,
 		webPassword,
 		cfg.WebPort,
+		cfg.APIKey,
 	)
 
 	confPath := filepath.Join(confDir, "nps.conf")
@@ -269,10 +284,33 @@ func (m *Manager) StartClient(id uint) error {
 
 	fullAddr := fmt.Sprintf("%s:%d", serverAddr, serverPort)
 
+	// 存在启用的隧道时使用配置文件模式，否则仅以 vkey 连接（隧道在服务端配置）
+	var tunnels []model.NpsTunnel
+	m.db.Where("nps_client_id = ? AND enable = ?", id, true).Order("id asc").Find(&tunnels)
+	state := &clientState{mode: "vkey", tunnels: []TunnelRuntimeStatus{}}
+	var (
+		cnf   *config.Config
+		items []npcItem
+	)
+	if len(tunnels) > 0 {
+		var err error
+		cnf, items, err = buildNpcConfig(fullAddr, vkey, connType, tunnels)
+		if err != nil {
+			m.setClientError(id, err.Error())
+			return fmt.Errorf("生成 NPS 客户端配置失败: %w", err)
+		}
+		state.mode = "config"
+		for _, t := range tunnels {
+			state.tunnels = append(state.tunnels, TunnelRuntimeStatus{
+				ID: t.ID, Name: t.Name, Type: t.Type, Status: tunnelPending,
+			})
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	done := make(chan struct{})
 
-	entry := &clientEntry{cancel: cancel, done: done}
+	entry := &clientEntry{cancel: cancel, done: done, state: state}
 	m.clients.Store(id, entry)
 
 	go func() {
@@ -286,6 +324,13 @@ func (m *Manager) StartClient(id uint) error {
 		// 设置客户端全局参数
 		npsClient.AutoReconnect = true
 
+		if cnf != nil {
+			m.log.Infof("[NPS客户端][%s] 连接服务器 %s type: %s，注册 %d 条隧道",
+				cfg.Name, fullAddr, connType, len(items))
+			m.runConfigClient(ctx, cfg.Name, cnf, items, state)
+			return
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -297,7 +342,9 @@ func (m *Manager) StartClient(id uint) error {
 				cfg.Name, fullAddr, vkey, connType)
 
 			rpClient := npsClient.NewRPClient(fullAddr, vkey, connType, "", "", nil, 60, nil)
+			state.setConnected(true, "")
 			rpClient.Start(ctx)
+			state.setConnected(false, "")
 
 			select {
 			case <-ctx.Done():
@@ -335,6 +382,15 @@ func (m *Manager) StopClient(id uint) {
 	m.db.Model(&model.NpsClientConfig{}).Where("id = ?", id).Update("status", "stopped")
 }
 
+// ReloadClient 隧道变更后重启运行中的客户端，使新隧道配置重新注册
+// 客户端未运行时不做处理，配置将在下次启动时生效
+func (m *Manager) ReloadClient(id uint) error {
+	if _, ok := m.clients.Load(id); !ok {
+		return nil
+	}
+	return m.StartClient(id)
+}
+
 // GetClientStatus 获取客户端运行状态
 func (m *Manager) GetClientStatus(id uint) string {
 	if _, ok := m.clients.Load(id); ok {
@@ -0,0 +1,519 @@
+package nps
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netpanel/netpanel/model"
+)
+
+// ===== NPS 服务端管理（通过 NPS Web API） =====
+//
+// NPS 的客户端/隧道/域名数据保存在子进程内存中并定期落盘到 json 文件，
+// 直接改写文件无法让运行中的实例生效，因此统一通过 Web API 管理。
+// Web API 使用 auth_key 签名鉴权：auth_key=md5(配置中的 auth_key + 时间戳)，时间戳有效期 20 秒。
+
+// ServerClientReq NPS 服务端客户端配置
+type ServerClientReq struct {
+	VerifyKey       string   `json:"vkey"` // 留空由 NPS 自动生成
+	Remark          string   `json:"remark"`
+	BasicUsername   string   `json:"basic_username"` // socks5/httpProxy 基础认证
+	BasicPassword   string   `json:"basic_password"`
+	Compress        bool     `json:"compress"`
+	Crypt           bool     `json:"crypt"`
+	ConfigConnAllow bool     `json:"config_conn_allow"` // 允许客户端以配置文件模式连接
+	RateLimit       int      `json:"rate_limit"`        // 限速（KB/s），0 不限
+	MaxConn         int      `json:"max_conn"`          // 最大连接数，0 不限
+	MaxTunnelNum    int      `json:"max_tunnel_num"`    // 最大隧道数，0 不限
+	FlowLimit       int      `json:"flow_limit"`        // 流量限制（MB），0 不限
+	TimeLimit       string   `json:"time_limit"`        // 到期时间，格式 2006-01-02 15:04:05
+	WebUsername     string   `json:"web_username"`
+	WebPassword     string   `json:"web_password"`
+	BlackIPList     []string `json:"black_ip_list"`
+	FlowReset       bool     `json:"flow_reset"` // 仅更新时有效：清零已用流量
+}
+
+func (r *ServerClientReq) form() url.Values {
+	v := url.Values{}
+	v.Set("vkey", r.VerifyKey)
+	v.Set("remark", r.Remark)
+	v.Set("u", r.BasicUsername)
+	v.Set("p", r.BasicPassword)
+	v.Set("compress", strconv.FormatBool(r.Compress))
+	v.Set("crypt", strconv.FormatBool(r.Crypt))
+	v.Set("config_conn_allow", strconv.FormatBool(r.ConfigConnAllow))
+	v.Set("rate_limit", strconv.Itoa(r.RateLimit))
+	v.Set("max_conn", strconv.Itoa(r.MaxConn))
+	v.Set("max_tunnel", strconv.Itoa(r.MaxTunnelNum))
+	v.Set("flow_limit", strconv.Itoa(r.FlowLimit))
+	v.Set("time_limit", r.TimeLimit)
+	v.Set("web_username", r.WebUsername)
+	v.Set("web_password", r.WebPassword)
+	v.Set("blackiplist", strings.Join(r.BlackIPList, "\r\n"))
+	v.Set("flow_reset", strconv.FormatBool(r.FlowReset))
+	return v
+}
+
+// ServerTunnelReq NPS 服务端隧道配置
+type ServerTunnelReq struct {
+	ClientID      int    `json:"client_id"`
+	Type          string `json:"type"`      // tcp/udp/socks5/httpProxy/mixProxy/secret/p2p/file
+	Port          int    `json:"port"`      // 服务端监听端口，0 自动分配
+	ServerIP      string `json:"server_ip"` // 服务端监听地址
+	Target        string `json:"target"`    // 目标地址，多个换行分隔
+	ProxyProtocol int    `json:"proxy_protocol"`
+	LocalProxy    bool   `json:"local_proxy"` // 由服务端本地直接连接目标
+	Auth          string `json:"auth"`        // 多用户认证，每行 user=password
+	Password      string `json:"password"`    // secret/p2p 唯一密钥
+	LocalPath     string `json:"local_path"`  // file 模式本地目录
+	StripPre      string `json:"strip_pre"`
+	EnableHTTP    bool   `json:"enable_http"`   // mixProxy 启用 HTTP 代理
+	EnableSocks5  bool   `json:"enable_socks5"` // mixProxy 启用 SOCKS5 代理
+	Remark        string `json:"remark"`
+	FlowLimit     int    `json:"flow_limit"`
+	TimeLimit     string `json:"time_limit"`
+	FlowReset     bool   `json:"flow_reset"`
+}
+
+func (r *ServerTunnelReq) form() url.Values {
+	v := url.Values{}
+	v.Set("client_id", strconv.Itoa(r.ClientID))
+	v.Set("type", r.Type)
+	v.Set("port", strconv.Itoa(r.Port))
+	v.Set("server_ip", r.ServerIP)
+	v.Set("target", r.Target)
+	v.Set("proxy_protocol", strconv.Itoa(r.ProxyProtocol))
+	v.Set("local_proxy", strconv.FormatBool(r.LocalProxy))
+	v.Set("auth", r.Auth)
+	v.Set("password", r.Password)
+	v.Set("local_path", r.LocalPath)
+	v.Set("strip_pre", r.StripPre)
+	v.Set("enable_http", strconv.FormatBool(r.EnableHTTP))
+	v.Set("enable_socks5", strconv.FormatBool(r.EnableSocks5))
+	v.Set("remark", r.Remark)
+	v.Set("flow_limit", strconv.Itoa(r.FlowLimit))
+	v.Set("time_limit", r.TimeLimit)
+	v.Set("flow_reset", strconv.FormatBool(r.FlowReset))
+	return v
+}
+
+// ServerHostReq NPS 服务端域名解析配置
+type ServerHostReq struct {
+	ClientID       int    `json:"client_id"`
+	Host           string `json:"host"`
+	Target         string `json:"target"` // 内网目标，多个换行分隔
+	Scheme         string `json:"scheme"` // all/http/https
+	Location       string `json:"location"`
+	Remark         string `json:"remark"`
+	HostChange     string `json:"host_change"` // 请求 Host 修改
+	Header         string `json:"header"`      // 请求头修改，每行 key:value
+	RespHeader     string `json:"resp_header"` // 响应头修改，每行 key:value
+	PathRewrite    string `json:"path_rewrite"`
+	RedirectURL    string `json:"redirect_url"`
+	HTTPSJustProxy bool   `json:"https_just_proxy"` // HTTPS 直接透传到内网
+	TLSOffload     bool   `json:"tls_offload"`
+	AutoSSL        bool   `json:"auto_ssl"`
+	CertFile       string `json:"cert_file"` // 证书内容或路径
+	KeyFile        string `json:"key_file"`
+	AutoHTTPS      bool   `json:"auto_https"` // HTTP 自动跳转 HTTPS
+	AutoCORS       bool   `json:"auto_cors"`
+	TargetIsHTTPS  bool   `json:"target_is_https"`
+	ProxyProtocol  int    `json:"proxy_protocol"`
+	LocalProxy     bool   `json:"local_proxy"`
+	Auth           string `json:"auth"`
+	FlowLimit      int    `json:"flow_limit"`
+	TimeLimit      string `json:"time_limit"`
+	FlowReset      bool   `json:"flow_reset"`
+}
+
+func (r *ServerHostReq) form() url.Values {
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "all"
+	}
+	location := r.Location
+	if location == "" {
+		location = "/"
+	}
+	v := url.Values{}
+	v.Set("client_id", strconv.Itoa(r.ClientID))
+	v.Set("host", r.Host)
+	v.Set("target", r.Target)
+	v.Set("scheme", scheme)
+	v.Set("location", location)
+	v.Set("remark", r.Remark)
+	v.Set("hostchange", r.HostChange)
+	v.Set("header", r.Header)
+	v.Set("resp_header", r.RespHeader)
+	v.Set("path_rewrite", r.PathRewrite)
+	v.Set("redirect_url", r.RedirectURL)
+	v.Set("https_just_proxy", strconv.FormatBool(r.HTTPSJustProxy))
+	v.Set("tls_offload", strconv.FormatBool(r.TLSOffload))
+	v.Set("auto_ssl", strconv.FormatBool(r.AutoSSL))
+	v.Set("cert_file", r.CertFile)
+	v.Set("key_file", r.KeyFile)
+	v.Set("auto_https", strconv.FormatBool(r.AutoHTTPS))
+	v.Set("auto_cors", strconv.FormatBool(r.AutoCORS))
+	v.Set("target_is_https", strconv.FormatBool(r.TargetIsHTTPS))
+	v.Set("proxy_protocol", strconv.Itoa(r.ProxyProtocol))
+	v.Set("local_proxy", strconv.FormatBool(r.LocalProxy))
+	v.Set("auth", r.Auth)
+	v.Set("flow_limit", strconv.Itoa(r.FlowLimit))
+	v.Set("time_limit", r.TimeLimit)
+	v.Set("flow_reset", strconv.FormatBool(r.FlowReset))
+	return v
+}
+
+// ListQuery 列表查询参数（对应 NPS 的分页参数，Limit 为 0 时返回全部）
+type ListQuery struct {
+	ClientID int
+	Type     string // 仅隧道列表有效
+	Search   string
+	Offset   int
+	Limit    int
+}
+
+// ListResult 列表查询结果，Rows 为 NPS 原始数据（包含流量统计 Flow 等字段）
+type ListResult struct {
+	Rows  json.RawMessage `json:"rows"`
+	Total int             `json:"total"`
+}
+
+// webAPI NPS Web API 客户端
+type webAPI struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// serverAPI 获取运行中服务端的 Web API 客户端
+func (m *Manager) serverAPI(id uint) (*webAPI, error) {
+	if _, ok := m.servers.Load(id); !ok {
+		return nil, fmt.Errorf("NPS 服务端未运行")
+	}
+	var cfg model.NpsServerConfig
+	if err := m.db.First(&cfg, id).Error; err != nil {
+		return nil, fmt.Errorf("NPS 服务端配置不存在: %w", err)
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("NPS 服务端 API 密钥未初始化，请重启服务端")
+	}
+	host := cfg.BindAddr
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return &webAPI{
+		baseURL: "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.WebPort)),
+		apiKey:  cfg.APIKey,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// 鉴权失败时 NPS 会重定向到登录页，不跟随以便识别
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// post 调用 NPS Web API 并解析 JSON 响应
+func (a *webAPI) post(path string, form url.Values, out any) error {
+	if form == nil {
+		form = url.Values{}
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sum := md5.Sum([]byte(a.apiKey + ts))
+	form.Set("auth_key", hex.EncodeToString(sum[:]))
+	form.Set("timestamp", ts)
+
+	resp, err := a.client.PostForm(a.baseURL+path, form)
+	if err != nil {
+		return fmt.Errorf("请求 NPS Web API 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusFound {
+		return fmt.Errorf("NPS Web API 鉴权失败")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("NPS Web API %s 返回 HTTP %d", path, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析 NPS Web API %s 响应失败: %w", path, err)
+	}
+	return nil
+}
+
+// ajaxResult NPS 增删改接口的统一响应
+type ajaxResult struct {
+	Status int    `json:"status"`
+	Msg    string `json:"msg"`
+	ID     int    `json:"id"`
+}
+
+// exec 调用 NPS 增删改接口，status 为 0 时返回错误
+func (a *webAPI) exec(path string, form url.Values) (int, error) {
+	var res ajaxResult
+	if err := a.post(path, form, &res); err != nil {
+		return 0, err
+	}
+	if res.Status != 1 {
+		return 0, fmt.Errorf("NPS 返回错误: %s", res.Msg)
+	}
+	return res.ID, nil
+}
+
+func (a *webAPI) list(path string, q ListQuery) (*ListResult, error) {
+	form := url.Values{}
+	form.Set("offset", strconv.Itoa(q.Offset))
+	form.Set("limit", strconv.Itoa(q.Limit))
+	form.Set("search", q.Search)
+	if q.ClientID > 0 {
+		form.Set("client_id", strconv.Itoa(q.ClientID))
+		form.Set("clientId", strconv.Itoa(q.ClientID))
+	}
+	if q.Type != "" {
+		form.Set("type", q.Type)
+	}
+	var res ListResult
+	if err := a.post(path, form, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Rows) == 0 || string(res.Rows) == "null" {
+		res.Rows = json.RawMessage("[]")
+	}
+	return &res, nil
+}
+
+func idForm(id int) url.Values {
+	return url.Values{"id": {strconv.Itoa(id)}}
+}
+
+// ===== 客户端 =====
+
+// ListServerClients 列出 NPS 服务端的客户端（含在线状态与流量统计）
+func (m *Manager) ListServerClients(id uint, q ListQuery) (*ListResult, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return nil, err
+	}
+	return api.list("/client/list", q)
+}
+
+// CreateServerClient 新建客户端，返回 NPS 客户端 ID
+func (m *Manager) CreateServerClient(id uint, req *ServerClientReq) (int, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return 0, err
+	}
+	return api.exec("/client/add", req.form())
+}
+
+// UpdateServerClient 更新客户端
+func (m *Manager) UpdateServerClient(id uint, clientID int, req *ServerClientReq) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	form := req.form()
+	form.Set("id", strconv.Itoa(clientID))
+	_, err = api.exec("/client/edit", form)
+	return err
+}
+
+// SetServerClientStatus 启用/禁用客户端，禁用时断开其连接
+func (m *Manager) SetServerClientStatus(id uint, clientID int, enable bool) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	form := idForm(clientID)
+	form.Set("status", strconv.FormatBool(enable))
+	_, err = api.exec("/client/changestatus", form)
+	return err
+}
+
+// DeleteServerClient 删除客户端及其隧道、域名
+func (m *Manager) DeleteServerClient(id uint, clientID int) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	_, err = api.exec("/client/del", idForm(clientID))
+	return err
+}
+
+// ===== 隧道 =====
+
+// serverTunnelModes NPS 隧道类型，未指定类型和客户端时按类型逐一查询
+var serverTunnelModes = []string{"tcp", "udp", "socks5", "httpProxy", "mixProxy", "secret", "p2p", "file"}
+
+// ListServerTunnels 列出隧道（含流量统计）
+func (m *Manager) ListServerTunnels(id uint, q ListQuery) (*ListResult, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Type != "" || q.ClientID > 0 {
+		return api.list("/index/gettunnel", q)
+	}
+
+	// NPS 要求类型与客户端至少指定一个，此处合并各类型结果后再分页
+	all := []json.RawMessage{}
+	for _, mode := range serverTunnelModes {
+		res, err := api.list("/index/gettunnel", ListQuery{Type: mode, Search: q.Search})
+		if err != nil {
+			return nil, err
+		}
+		var rows []json.RawMessage
+		if err := json.Unmarshal(res.Rows, &rows); err != nil {
+			return nil, fmt.Errorf("解析隧道列表失败: %w", err)
+		}
+		all = append(all, rows...)
+	}
+	total := len(all)
+	if q.Offset > 0 {
+		all = all[min(q.Offset, len(all)):]
+	}
+	if q.Limit > 0 && q.Limit < len(all) {
+		all = all[:q.Limit]
+	}
+	rows, err := json.Marshal(all)
+	if err != nil {
+		return nil, err
+	}
+	return &ListResult{Rows: rows, Total: total}, nil
+}
+
+// CreateServerTunnel 新建隧道，返回 NPS 隧道 ID
+func (m *Manager) CreateServerTunnel(id uint, req *ServerTunnelReq) (int, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return 0, err
+	}
+	return api.exec("/index/add", req.form())
+}
+
+// UpdateServerTunnel 更新隧道（NPS 会重启该隧道）
+func (m *Manager) UpdateServerTunnel(id uint, tunnelID int, req *ServerTunnelReq) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	form := req.form()
+	form.Set("id", strconv.Itoa(tunnelID))
+	_, err = api.exec("/index/edit", form)
+	return err
+}
+
+// SetServerTunnelStatus 启动/停止隧道
+func (m *Manager) SetServerTunnelStatus(id uint, tunnelID int, enable bool) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	path := "/index/stop"
+	if enable {
+		path = "/index/start"
+	}
+	_, err = api.exec(path, idForm(tunnelID))
+	return err
+}
+
+// DeleteServerTunnel 删除隧道
+func (m *Manager) DeleteServerTunnel(id uint, tunnelID int) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	_, err = api.exec("/index/del", idForm(tunnelID))
+	return err
+}
+
+// ===== 域名解析 =====
+
+// ListServerHosts 列出域名解析（含流量统计）
+func (m *Manager) ListServerHosts(id uint, q ListQuery) (*ListResult, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return nil, err
+	}
+	return api.list("/index/hostlist", q)
+}
+
+// CreateServerHost 新建域名解析，返回 NPS 域名 ID
+func (m *Manager) CreateServerHost(id uint, req *ServerHostReq) (int, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return 0, err
+	}
+	return api.exec("/index/addhost", req.form())
+}
+
+// UpdateServerHost 更新域名解析
+func (m *Manager) UpdateServerHost(id uint, hostID int, req *ServerHostReq) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	form := req.form()
+	form.Set("id", strconv.Itoa(hostID))
+	_, err = api.exec("/index/edithost", form)
+	return err
+}
+
+// SetServerHostStatus 启用/停用域名解析
+func (m *Manager) SetServerHostStatus(id uint, hostID int, enable bool) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	path := "/index/stophost"
+	if enable {
+		path = "/index/starthost"
+	}
+	_, err = api.exec(path, idForm(hostID))
+	return err
+}
+
+// DeleteServerHost 删除域名解析
+func (m *Manager) DeleteServerHost(id uint, hostID int) error {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return err
+	}
+	_, err = api.exec("/index/delhost", idForm(hostID))
+	return err
+}
+
+// ===== 统计 =====
+
+// GetServerStats 获取服务端总览统计（客户端/隧道数量、总流量、系统负载等）
+func (m *Manager) GetServerStats(id uint) (json.RawMessage, error) {
+	api, err := m.serverAPI(id)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := api.post("/index/stats", nil, &res); err != nil {
+		return nil, err
+	}
+	if res.Code != 1 {
+		return nil, fmt.Errorf("NPS Web API 鉴权失败")
+	}
+	return res.Data, nil
+}
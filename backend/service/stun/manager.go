@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -43,6 +44,11 @@ type CallbackNotifier interface {
 	TriggerBySTUN(ruleID uint, ip string, port int) error
 }
 
+// EndpointPublisher 公网端点 DNS 发布接口（由 ddns 模块实现并注入，避免循环依赖）
+type EndpointPublisher interface {
+	PublishEndpoint(rule *model.StunRule, ip string, port int) error
+}
+
 // stunEntry 单个 STUN 任务运行实例
 type stunEntry struct {
 	cancel     context.CancelFunc
@@ -53,10 +59,11 @@ type stunEntry struct {
 
 // Manager STUN 管理器
 type Manager struct {
-	db       *gorm.DB
-	log      *logrus.Logger
-	entries  sync.Map // map[uint]*stunEntry
-	callback CallbackNotifier
+	db        *gorm.DB
+	log       *logrus.Logger
+	entries   sync.Map // map[uint]*stunEntry
+	callback  CallbackNotifier
+	publisher EndpointPublisher
 }
 
 func NewManager(db *gorm.DB, log *logrus.Logger) *Manager {
@@ -68,6 +75,11 @@ func (m *Manager) SetCallbackNotifier(n CallbackNotifier) {
 	m.callback = n
 }
 
+// SetEndpointPublisher 注入 DNS 端点发布器
+func (m *Manager) SetEndpointPublisher(p EndpointPublisher) {
+	m.publisher = p
+}
+
 func (m *Manager) StartAll() {
 	var rules []model.StunRule
 	m.db.Where("enable = ?", true).Find(&rules)
@@ -167,6 +179,8 @@ func (m *Manager) runLoop(ctx context.Context, id uint, entry *stunEntry) {
 	backoff := 5 * time.Second
 	maxBackoff := 5 * time.Minute
 	checkInterval := 30 * time.Second
+	// 最近一次成功发布的地址，发布失败时保持不变，下一轮检测继续重试
+	published := ""
 
 	for {
 		// 重新读取最新配置
@@ -214,6 +228,22 @@ func (m *Manager) runLoop(ctx context.Context, id uint, entry *stunEntry) {
 			}
 		}
 
+		// 当前地址与最近一次成功发布的地址不同时发布 DNS 记录（含上次发布失败的重试）
+		if rule.DNSPublishEnable && m.publisher != nil {
+			if info := m.GetCurrentInfo(id); info != nil {
+				endpoint := net.JoinHostPort(info.IP, strconv.Itoa(info.Port))
+				if endpoint != published {
+					if err := m.publisher.PublishEndpoint(&rule, info.IP, info.Port); err != nil {
+						m.log.Warnf("[STUN服务][%s] 发布 DNS 记录失败，下次检测时重试: %v", rule.Name, err)
+					} else {
+						published = endpoint
+					}
+				}
+			}
+		} else {
+			published = ""
+		}
+
 		select {
 		case <-ctx.Done():
 			return
@@ -598,6 +628,19 @@ func tryUPnPMapping(internalPort int) (string, int, error) {
 	return externalIP, externalPort, nil
 }
 
+// UPnPExternalIP 通过 UPnP 查询路由器的外部 IP（WAN 口地址）
+func UPnPExternalIP() (string, error) {
+	gateway, err := discoverUPnPGateway()
+	if err != nil {
+		return "", fmt.Errorf("UPnP 网关发现失败: %w", err)
+	}
+	externalIP, err := gateway.getExternalIP()
+	if err != nil {
+		return "", fmt.Errorf("获取外部 IP 失败: %w", err)
+	}
+	return strings.TrimSpace(externalIP), nil
+}
+
 // upnpGateway UPnP 网关
 type upnpGateway struct {
 	controlURL  string